@@ -2,14 +2,36 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ps3syscon-gui/syscon"
 	"ps3syscon-gui/ui"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
-	"go.bug.st/serial"
 )
 
+// broker owns the physical ports so the session and the serial monitor can
+// use the same port at once.
+var broker = syscon.NewBroker(syscon.DefaultSerialPortOpener)
+
+// profiles holds the line settings saved for each port.
+var profiles = loadProfiles()
+
+// session holds the serial connection shared by every command and auth.
+var session = syscon.NewSession(broker.Open)
+
 func main() {
+	loadCommandOverride()
+
+	// Re-send garbled answers to read-only commands
+	session.SetRetryPolicy(syscon.DefaultRetryPolicy)
+
 	myApp := app.New()
 
 	// Apply custom dark theme
@@ -33,13 +55,27 @@ func main() {
 			// On accept - show main window
 			deps := ui.WindowDeps{
 				LogoResource:        ui.LogoResource,
-				GetSerialPorts:      getSerialPorts,
-				GetCommandNames:     GetCommandNames,
-				GetCXRFCommandNames: GetCXRFCommandNames,
-				GetCommand:          adaptCommand,
-				GetCXRFCommand:      adaptCXRFCommand,
+				GetSerialPorts:      syscon.ListPorts,
+				GetCommandNames:     syscon.GetCommandNames,
+				GetCXRFCommandNames: syscon.GetCXRFCommandNames,
+				GetSWCommandNames:   syscon.GetSWCommandNames,
+				GetCommand:          syscon.GetCommand,
+				GetCXRFCommand:      syscon.GetCXRFCommand,
+				GetSWCommand:        syscon.GetSWCommand,
+				Connect:             connect,
+				Disconnect:          session.Disconnect,
+				ConnectionState:     connectionState,
+				AuthState:           session.AuthState,
 				SendCommand:         sendCommand,
 				Authenticate:        authenticate,
+				DetectDevice:        detectDevice,
+				LoadProfile:         profiles.Get,
+				SaveProfile:         profiles.Set,
+				SwitchMode:          session.SwitchMode,
+				SetReadOnly:         session.SetReadOnly,
+				NewRecorder:         newRecorder,
+				NewDumpPath:         newDumpPath,
+				SetRecorder:         session.SetRecorder,
 				OpenSerialMonitor:   openSerialMonitor,
 				ShowGuideWindow:     ui.ShowGuideWindow,
 			}
@@ -52,76 +88,112 @@ func main() {
 	)
 
 	myApp.Run()
+	session.Disconnect()
+}
+
+// connect opens the session with the port's saved line settings; speed is
+// used unless the profile fixes a baud rate.
+func connect(port, scType string, speed int) error {
+	return session.ConnectProfile(port, scType, profiles.Get(port).WithBaudRate(speed))
+}
+
+// sendCommand sends a command over the shared session.
+func sendCommand(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+	return session.CommandContext(ctx, cmd)
+}
+
+// authenticate authenticates over the shared session.
+func authenticate(ctx context.Context) error {
+	return session.AuthContext(ctx)
+}
+
+// detectDevice probes port for the syscon type and baud rate.
+func detectDevice(ctx context.Context, port string) (syscon.Detection, error) {
+	return syscon.Detect(ctx, port, broker.Open)
 }
 
-// adaptCommand adapts the Command type from commands.go to ui.Command.
-func adaptCommand(name string) *ui.Command {
-	cmd := GetCommand(name)
-	if cmd == nil {
-		return nil
+// newRecorder creates a timestamped capture file in ~/ps3syscon-captures.
+func newRecorder(kind string) (*syscon.Recorder, error) {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		dir = os.TempDir()
 	}
-	return &ui.Command{
-		Name:        cmd.Name,
-		Subcommands: cmd.Subcommands,
-		Description: cmd.Description,
+	dir = filepath.Join(dir, "ps3syscon-captures")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
 	}
+	name := fmt.Sprintf("%s-%s.jsonl", kind, time.Now().Format("20060102-150405"))
+	return syscon.CreateRecorder(filepath.Join(dir, name))
 }
 
-// adaptCXRFCommand adapts the Command type for CXRF commands.
-func adaptCXRFCommand(name string) *ui.Command {
-	cmd := GetCXRFCommand(name)
-	if cmd == nil {
-		return nil
+// newDumpPath returns a timestamped EEPROM dump path in ~/ps3syscon-dumps.
+func newDumpPath(scType string) (string, error) {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		dir = os.TempDir()
 	}
-	return &ui.Command{
-		Name:        cmd.Name,
-		Subcommands: cmd.Subcommands,
-		Description: cmd.Description,
+	dir = filepath.Join(dir, "ps3syscon-dumps")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
 	}
+	name := fmt.Sprintf("eeprom-%s-%s.bin", scType, time.Now().Format("20060102-150405"))
+	return filepath.Join(dir, name), nil
 }
 
-// sendCommand wraps the serial command execution.
-func sendCommand(port, scType, cmd string, speed int) (ui.CommandResult, error) {
-	ps3, err := NewPS3UART(port, scType, speed)
+// loadProfiles opens the connection profiles in the user config directory.
+func loadProfiles() *syscon.ProfileStore {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	path := filepath.Join(dir, "ps3syscon-gui", "profiles.json")
+	store, err := syscon.OpenProfileStore(path)
 	if err != nil {
-		return ui.CommandResult{}, err
+		// Start afresh; the unreadable file is replaced on the next save
+		log.Printf("connection profiles: %v", err)
+		return syscon.NewProfileStore(path)
 	}
-	defer ps3.Close()
+	return store
+}
 
-	result := ps3.Command(cmd, 1)
-	return ui.CommandResult{
-		Code: result.Code,
-		Data: result.Data,
-	}, nil
+// loadCommandOverride applies commands.json from the user config directory
+// to the command catalog, so commands can be added or corrected without a
+// rebuild.
+func loadCommandOverride() {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return
+	}
+	path := filepath.Join(dir, "ps3syscon-gui", "commands.json")
+	if err := syscon.LoadCatalogOverride(path); err != nil {
+		log.Printf("command catalog: %v", err)
+	}
 }
 
-// authenticate wraps the serial authentication.
-func authenticate(port, scType string, speed int) error {
-	ps3, err := NewPS3UART(port, scType, speed)
+// openMonitorPort opens port for the serial monitor at baudRate with the
+// rest of the port's saved line settings.
+func openMonitorPort(port string, baudRate int) (syscon.SerialPort, error) {
+	p := profiles.Get(port)
+	p.BaudRate = baudRate
+	mode, err := p.Mode()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer ps3.Close()
+	return broker.Open(port, mode)
+}
 
-	return ps3.Auth()
+// connectionState reports the session state and the error behind a lost connection.
+func connectionState() (syscon.ConnectionState, error) {
+	return session.State(), session.Err()
 }
 
 // openSerialMonitor wraps the ui.OpenSerialMonitor with dependencies.
 func openSerialMonitor(myApp fyne.App, port, scType string) {
 	deps := ui.MonitorDeps{
-		GetSerialPorts: getSerialPorts,
-		OpenPort:       openSerialPort,
+		GetSerialPorts: syscon.ListPorts,
+		OpenPort:       openMonitorPort,
+		CheckWiring:    syscon.CheckWiring,
+		NewRecorder:    newRecorder,
 	}
 	ui.OpenSerialMonitor(myApp, port, scType, deps)
 }
-
-// openSerialPort opens a serial port with the given settings.
-func openSerialPort(portName string, baudRate int) (ui.SerialPort, error) {
-	mode := &serial.Mode{
-		BaudRate: baudRate,
-		DataBits: 8,
-		Parity:   serial.NoParity,
-		StopBits: serial.OneStopBit,
-	}
-	return serial.Open(portName, mode)
-}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"ps3syscon-gui/syscon"
 	"ps3syscon-gui/ui"
 	"testing"
 
@@ -11,13 +12,21 @@ import (
 func testWindowDeps() ui.WindowDeps {
 	return ui.WindowDeps{
 		LogoResource:        LogoResource,
-		GetSerialPorts:      getSerialPorts,
-		GetCommandNames:     GetCommandNames,
-		GetCXRFCommandNames: GetCXRFCommandNames,
-		GetCommand:          adaptCommand,
-		GetCXRFCommand:      adaptCXRFCommand,
+		GetSerialPorts:      syscon.ListPorts,
+		GetCommandNames:     syscon.GetCommandNames,
+		GetCXRFCommandNames: syscon.GetCXRFCommandNames,
+		GetSWCommandNames:   syscon.GetSWCommandNames,
+		GetCommand:          syscon.GetCommand,
+		GetCXRFCommand:      syscon.GetCXRFCommand,
+		GetSWCommand:        syscon.GetSWCommand,
+		Connect:             session.Connect,
+		Disconnect:          session.Disconnect,
+		ConnectionState:     connectionState,
 		SendCommand:         sendCommand,
 		Authenticate:        authenticate,
+		DetectDevice:        detectDevice,
+		NewRecorder:         newRecorder,
+		SetRecorder:         session.SetRecorder,
 		OpenSerialMonitor:   openSerialMonitor,
 		ShowGuideWindow:     ui.ShowGuideWindow,
 	}
@@ -0,0 +1,272 @@
+// Package syscon provides typed argument definitions for catalog commands.
+package syscon
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ArgType is the kind of value an argument takes.
+type ArgType string
+
+// Argument types used in the catalog.
+const (
+	ArgHex     ArgType = "hex"      // hexadecimal number such as an offset; Width fixes the digits
+	ArgHexByte ArgType = "hex_byte" // two hexadecimal digits
+	ArgDecimal ArgType = "decimal"  // decimal number
+	ArgEnum    ArgType = "enum"     // one of Values
+)
+
+// maxHexDigits is the widest hex argument, a 64-bit value for w64.
+const maxHexDigits = 16
+
+// Arg describes one argument of a command.
+type Arg struct {
+	Name     string   `json:"name"`
+	Type     ArgType  `json:"type"`
+	Width    int      `json:"width,omitempty"`  // exact hex digits; zero allows 1 to 16
+	Min      int64    `json:"min,omitempty"`    // lower bound, checked when Max > Min
+	Max      int64    `json:"max,omitempty"`    // upper bound
+	Values   []string `json:"values,omitempty"` // choices for ArgEnum
+	Optional bool     `json:"optional,omitempty"`
+	Repeat   bool     `json:"repeat,omitempty"`   // last argument, may be given more than once
+	CountOf  string   `json:"count_of,omitempty"` // earlier argument giving how many times a repeating one is given
+}
+
+// ArgError reports an argument that does not match its definition. It
+// wraps ErrInvalidArgument.
+type ArgError struct {
+	Arg    string // argument name, empty for a wrong argument count
+	Value  string // value given, empty when missing
+	Reason string
+}
+
+func (e *ArgError) Error() string {
+	msg := ErrInvalidArgument.Error()
+	if e.Arg != "" {
+		msg += " " + e.Arg
+	}
+	if e.Value != "" {
+		msg += fmt.Sprintf(" %q", e.Value)
+	}
+	return msg + ": " + e.Reason
+}
+
+func (e *ArgError) Unwrap() error {
+	return ErrInvalidArgument
+}
+
+// hasRange reports whether a numeric range is set.
+func (a Arg) hasRange() bool {
+	return a.Max > a.Min
+}
+
+// Hint returns a short description of the values a accepts, suitable as
+// an input placeholder.
+func (a Arg) Hint() string {
+	switch a.Type {
+	case ArgHex:
+		if a.hasRange() {
+			return fmt.Sprintf("%0*X-%0*X", a.Width, a.Min, a.Width, a.Max)
+		}
+		if a.Width > 0 {
+			return fmt.Sprintf("%d hex digits", a.Width)
+		}
+		return "hex"
+	case ArgHexByte:
+		return "00-FF"
+	case ArgDecimal:
+		if a.hasRange() {
+			return fmt.Sprintf("%d-%d", a.Min, a.Max)
+		}
+		return "decimal"
+	case ArgEnum:
+		return strings.Join(a.Values, "/")
+	default:
+		return ""
+	}
+}
+
+// Check reports whether value is valid for a.
+func (a Arg) Check(value string) error {
+	fail := func(format string, args ...any) error {
+		return &ArgError{Arg: a.Name, Value: value, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	switch a.Type {
+	case ArgHex:
+		if value == "" || !isHex(value, len(value)) {
+			return fail("not hexadecimal")
+		}
+		if a.Width > 0 && len(value) != a.Width {
+			return fail("want %d hex digits", a.Width)
+		}
+		if len(value) > maxHexDigits {
+			return fail("more than %d hex digits", maxHexDigits)
+		}
+		if a.hasRange() {
+			n, _ := strconv.ParseUint(value, 16, 64)
+			if n < uint64(a.Min) || n > uint64(a.Max) {
+				return fail("out of range %s", a.Hint())
+			}
+		}
+	case ArgHexByte:
+		if !isHex(value, 2) {
+			return fail("want two hex digits")
+		}
+	case ArgDecimal:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fail("not a decimal number")
+		}
+		if a.hasRange() && (n < a.Min || n > a.Max) {
+			return fail("out of range %s", a.Hint())
+		}
+	case ArgEnum:
+		for _, v := range a.Values {
+			if strings.EqualFold(v, value) {
+				return nil
+			}
+		}
+		return fail("want one of %s", strings.Join(a.Values, ", "))
+	default:
+		return fail("unknown argument type %q", a.Type)
+	}
+	return nil
+}
+
+// CheckArgs reports the first of values that does not match schema, or a
+// missing or extra argument.
+func CheckArgs(schema []Arg, values []string) error {
+	for i, a := range schema {
+		if i >= len(values) {
+			if a.Optional {
+				return nil
+			}
+			return &ArgError{Arg: a.Name, Reason: "missing"}
+		}
+		rest := values[i : i+1]
+		if a.Repeat {
+			rest = values[i:]
+		}
+		for _, v := range rest {
+			if err := a.Check(v); err != nil {
+				return err
+			}
+		}
+		if a.Repeat {
+			return checkCount(schema, values, a, len(rest))
+		}
+	}
+	if len(values) > len(schema) {
+		return &ArgError{Reason: fmt.Sprintf("want at most %d arguments, got %d", len(schema), len(values))}
+	}
+	return nil
+}
+
+// checkCount reports a repeating argument a given n times when its
+// CountOf argument asks for another number, such as EEP SET with fewer
+// value bytes than its length.
+func checkCount(schema []Arg, values []string, a Arg, n int) error {
+	if a.CountOf == "" {
+		return nil
+	}
+	for i, c := range schema {
+		if c.Name != a.CountOf {
+			continue
+		}
+		base := 16
+		if c.Type == ArgDecimal {
+			base = 10
+		}
+		want, err := strconv.ParseUint(values[i], base, 64)
+		if err != nil || want != uint64(n) {
+			return &ArgError{Arg: a.Name, Reason: fmt.Sprintf("want %d for %s %s, got %d", want, c.Name, values[i], n)}
+		}
+	}
+	return nil
+}
+
+// validateSchema reports a catalog argument list that cannot be checked.
+func validateSchema(schema []Arg) error {
+	for i, a := range schema {
+		if a.Name == "" {
+			return fmt.Errorf("argument %d has no name", i+1)
+		}
+		switch a.Type {
+		case ArgHex, ArgHexByte, ArgDecimal:
+		case ArgEnum:
+			if len(a.Values) == 0 {
+				return fmt.Errorf("argument %s has no values", a.Name)
+			}
+		default:
+			return fmt.Errorf("argument %s: unknown type %q", a.Name, a.Type)
+		}
+		if a.Width < 0 || a.Width > maxHexDigits {
+			return fmt.Errorf("argument %s: invalid width %d", a.Name, a.Width)
+		}
+		if a.Repeat && i != len(schema)-1 {
+			return fmt.Errorf("argument %s: only the last argument can repeat", a.Name)
+		}
+		if a.CountOf != "" {
+			if !a.Repeat {
+				return fmt.Errorf("argument %s: count_of needs a repeating argument", a.Name)
+			}
+			if !slices.ContainsFunc(schema[:i], func(c Arg) bool {
+				return c.Name == a.CountOf && (c.Type == ArgHex || c.Type == ArgHexByte || c.Type == ArgDecimal)
+			}) {
+				return fmt.Errorf("argument %s: count_of %q is not an earlier numeric argument", a.Name, a.CountOf)
+			}
+		}
+	}
+	return nil
+}
+
+// ArgSchema returns the arguments of the command, or of its subcommand
+// sub, and whether the catalog defines them. Without a definition the
+// arguments are free text.
+func (c *Command) ArgSchema(sub string) ([]Arg, bool) {
+	if sub != "" {
+		for name, schema := range c.SubArgs {
+			if strings.EqualFold(name, sub) {
+				return schema, true
+			}
+		}
+		return nil, false
+	}
+	return c.Args, c.Args != nil
+}
+
+// ValidateCommandLine checks the arguments of a command line in the given
+// mode against the catalog. Commands and subcommands without argument
+// definitions are not checked.
+func ValidateCommandLine(scType, cmdLine string) error {
+	fields := strings.Fields(cmdLine)
+	if len(fields) == 0 {
+		return nil
+	}
+	cmd := lookupCommand(scType, fields[0])
+	if cmd == nil {
+		return nil
+	}
+
+	sub, values := "", fields[1:]
+	if len(values) > 0 {
+		for _, s := range cmd.Subcommands {
+			if strings.EqualFold(s, values[0]) {
+				sub, values = s, values[1:]
+				break
+			}
+		}
+	}
+	schema, ok := cmd.ArgSchema(sub)
+	if !ok {
+		return nil
+	}
+	if err := CheckArgs(schema, values); err != nil {
+		return fmt.Errorf("%s: %w", strings.TrimSpace(cmd.Name+" "+sub), err)
+	}
+	return nil
+}
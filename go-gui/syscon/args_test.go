@@ -0,0 +1,159 @@
+package syscon
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestArgCheck(t *testing.T) {
+	offset := Arg{Name: "offset", Type: ArgHex, Width: 4}
+	ranged := Arg{Name: "address", Type: ArgHex, Min: 0x3000, Max: 0x3FFF}
+	value := Arg{Name: "value", Type: ArgHexByte}
+	zone := Arg{Name: "zone", Type: ArgDecimal, Min: 0, Max: 3}
+	mode := Arg{Name: "mode", Type: ArgEnum, Values: []string{"on", "off"}}
+
+	tests := []struct {
+		arg   Arg
+		value string
+		ok    bool
+	}{
+		{offset, "3961", true},
+		{offset, "39fe", true},
+		{offset, "396", false},
+		{offset, "39610", false},
+		{offset, "39G1", false},
+		{ranged, "3961", true},
+		{ranged, "4000", false},
+		{value, "00", true},
+		{value, "FF", true},
+		{value, "255", false},
+		{value, "0", false},
+		{zone, "3", true},
+		{zone, "4", false},
+		{zone, "0x1", false},
+		{mode, "OFF", true},
+		{mode, "toggle", false},
+		{Arg{Name: "x", Type: "float"}, "1", false},
+	}
+	for _, tt := range tests {
+		err := tt.arg.Check(tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s.Check(%q) = %v, want ok %v", tt.arg.Name, tt.value, err, tt.ok)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s.Check(%q) = %v, want ErrInvalidArgument", tt.arg.Name, tt.value, err)
+		}
+	}
+}
+
+func TestArgHint(t *testing.T) {
+	tests := []struct {
+		arg  Arg
+		want string
+	}{
+		{Arg{Type: ArgHex, Width: 4}, "4 hex digits"},
+		{Arg{Type: ArgHex, Width: 4, Min: 0x3000, Max: 0x3FFF}, "3000-3FFF"},
+		{Arg{Type: ArgHex}, "hex"},
+		{Arg{Type: ArgHexByte}, "00-FF"},
+		{Arg{Type: ArgDecimal, Max: 3}, "0-3"},
+		{Arg{Type: ArgEnum, Values: []string{"0", "1"}}, "0/1"},
+	}
+	for _, tt := range tests {
+		if got := tt.arg.Hint(); got != tt.want {
+			t.Errorf("Hint(%+v) = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestCheckArgs(t *testing.T) {
+	schema := []Arg{
+		{Name: "offset", Type: ArgHex, Width: 4},
+		{Name: "value", Type: ArgHexByte, Repeat: true},
+	}
+	optional := []Arg{
+		{Name: "offset", Type: ArgHex, Width: 4},
+		{Name: "length", Type: ArgHex, Optional: true},
+	}
+
+	tests := []struct {
+		schema []Arg
+		values []string
+		ok     bool
+	}{
+		{schema, []string{"39FE", "38", "00"}, true},
+		{schema, []string{"39FE", "38"}, true},
+		{schema, []string{"39FE"}, false},
+		{schema, []string{"39FE", "38", "256"}, false},
+		{optional, []string{"3961"}, true},
+		{optional, []string{"3961", "1"}, true},
+		{optional, []string{"3961", "1", "2"}, false},
+		{nil, nil, true},
+		{[]Arg{}, []string{"extra"}, false},
+	}
+	for _, tt := range tests {
+		if err := CheckArgs(tt.schema, tt.values); (err == nil) != tt.ok {
+			t.Errorf("CheckArgs(%v) = %v, want ok %v", tt.values, err, tt.ok)
+		}
+	}
+}
+
+func TestValidateCommandLine(t *testing.T) {
+	tests := []struct {
+		scType string
+		line   string
+		ok     bool
+	}{
+		{"CXR", "EEP GET 3961 01", true},
+		{"CXR", "eep get 3961 01", true},
+		{"CXR", "EEP GET 396 01", false},
+		{"CXR", "EEP SET 3961 01 00", true},
+		{"CXR", "EEP SET 3961 01", false},
+		{"CXR", "EEP SET 3961 02 00", false},
+		{"CXR", "EEP SET 3961 01 00 00", false},
+		{"CXR", "EEP SET 3961 02 00 FF", true},
+		{"CXR", "EEP INIT whatever", true},
+		{"CXR", "VER", true},
+		{"CXR", "VER 1", false},
+		{"CXRF", "w 39FE 38 00", true},
+		{"CXRF", "w 39FE 255", false},
+		{"CXRF", "r 3961 1", true},
+		{"CXRF", "tmp x", false},
+		{"CXRF", "nosuchcommand 1 2 3", true},
+		{"CXRF", "", true},
+	}
+	for _, tt := range tests {
+		err := ValidateCommandLine(tt.scType, tt.line)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateCommandLine(%s, %q) = %v, want ok %v", tt.scType, tt.line, err, tt.ok)
+		}
+	}
+}
+
+func TestCatalogArgSchemas(t *testing.T) {
+	eep := GetCommand("EEP")
+	if schema, ok := eep.ArgSchema("get"); !ok || len(schema) != 2 || schema[0].Width != 4 {
+		t.Errorf("EEP GET schema = %+v, %v", schema, ok)
+	}
+	if _, ok := eep.ArgSchema("INIT"); ok {
+		t.Error("EEP INIT has a schema; its arguments are not known")
+	}
+	if _, ok := eep.ArgSchema(""); ok {
+		t.Error("EEP without a subcommand has a schema")
+	}
+	if schema, ok := GetCommand("VER").ArgSchema(""); !ok || len(schema) != 0 {
+		t.Errorf("VER schema = %+v, %v; want no arguments", schema, ok)
+	}
+
+	bad := []string{
+		`{"commands": [{"name": "X", "mode": "CXR", "args": [{"name": "a", "type": "float"}]}]}`,
+		`{"commands": [{"name": "X", "mode": "CXR", "args": [{"type": "hex"}]}]}`,
+		`{"commands": [{"name": "X", "mode": "CXR", "args": [{"name": "a", "type": "enum"}]}]}`,
+		`{"commands": [{"name": "X", "mode": "CXR", "args": [{"name": "a", "type": "hex", "repeat": true}, {"name": "b", "type": "hex"}]}]}`,
+		`{"commands": [{"name": "X", "mode": "CXR", "sub_args": {"GET": []}}]}`,
+	}
+	for _, data := range bad {
+		if _, err := (catalog{}).merge([]byte(data)); err == nil {
+			t.Errorf("merge accepted %s", data)
+		}
+	}
+}
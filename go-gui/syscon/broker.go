@@ -0,0 +1,308 @@
+// Package syscon provides a broker that shares one serial port between readers.
+package syscon
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// brokerReadTimeout is the read timeout of the physical port. It bounds
+// how long closing a shared port waits for the reader goroutine.
+const brokerReadTimeout = 50 * time.Millisecond
+
+// brokerBacklog is the most a handle keeps unread. A handle nobody reads,
+// such as a session's between commands, keeps only the newest bytes.
+const brokerBacklog = 64 << 10
+
+// Broker owns each physical port and hands out subscriber handles to it.
+// Every handle receives a copy of all bytes read from the port, and writes
+// from all handles are serialized, so the serial monitor can show live
+// traffic while commands are sent. The physical port is closed when the
+// last handle is closed.
+type Broker struct {
+	mu     sync.Mutex
+	opener SerialPortOpener
+	ports  map[string]*sharedPort
+}
+
+// NewBroker creates a broker that opens physical ports with opener.
+func NewBroker(opener SerialPortOpener) *Broker {
+	return &Broker{opener: opener, ports: make(map[string]*sharedPort)}
+}
+
+// Open returns a new handle to portName, opening the physical port if no
+// other handle has it open. A port that is already open must be asked for
+// with the same baud rate and framing.
+func (b *Broker) Open(portName string, mode *serial.Mode) (SerialPort, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sp := b.ports[portName]
+	if sp == nil {
+		port, err := b.opener(portName, mode)
+		if err != nil {
+			return nil, err
+		}
+		if err := port.SetReadTimeout(brokerReadTimeout); err != nil {
+			port.Close()
+			return nil, err
+		}
+		sp = &sharedPort{broker: b, name: portName, port: port, mode: *mode, done: make(chan struct{})}
+		b.ports[portName] = sp
+		go sp.readLoop()
+	} else if sp.mode.BaudRate != mode.BaudRate {
+		return nil, fmt.Errorf("%w: %s is open at %d baud", ErrPortBusy, portName, sp.mode.BaudRate)
+	} else if !sameLine(&sp.mode, mode) {
+		return nil, fmt.Errorf("%w: %s is open with other line settings", ErrPortBusy, portName)
+	}
+
+	return sp.subscribe(), nil
+}
+
+// OpenPort is Open with the syscon's 8N1 line settings.
+func (b *Broker) OpenPort(portName string, baudRate int) (SerialPort, error) {
+	return b.Open(portName, defaultMode(baudRate))
+}
+
+// Subscribers returns the number of open handles to portName.
+func (b *Broker) Subscribers(portName string) int {
+	b.mu.Lock()
+	sp := b.ports[portName]
+	b.mu.Unlock()
+
+	if sp == nil {
+		return 0
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return len(sp.subs)
+}
+
+// forget drops sp from the broker so the next Open starts afresh.
+func (b *Broker) forget(sp *sharedPort) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ports[sp.name] == sp {
+		delete(b.ports, sp.name)
+	}
+}
+
+// sharedPort is one physical port and its subscribers.
+type sharedPort struct {
+	broker  *Broker
+	name    string
+	port    SerialPort
+	mode    serial.Mode
+	writeMu sync.Mutex    // serializes writes from all handles
+	done    chan struct{} // closed when readLoop returns
+
+	mu     sync.Mutex
+	subs   []*brokerPort
+	err    error // read failure, reported to every handle
+	closed bool
+}
+
+// subscribe adds a handle.
+func (sp *sharedPort) subscribe() *brokerPort {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	h := &brokerPort{shared: sp, readTimeout: 100 * time.Millisecond, notify: make(chan struct{}, 1)}
+	sp.subs = append(sp.subs, h)
+	return h
+}
+
+// unsubscribe removes h and closes the physical port after the last one.
+func (sp *sharedPort) unsubscribe(h *brokerPort) error {
+	sp.mu.Lock()
+	for i, s := range sp.subs {
+		if s == h {
+			sp.subs = append(sp.subs[:i], sp.subs[i+1:]...)
+			break
+		}
+	}
+	last := len(sp.subs) == 0 && !sp.closed
+	if last {
+		sp.closed = true
+	}
+	sp.mu.Unlock()
+
+	if !last {
+		return nil
+	}
+	sp.broker.forget(sp)
+	err := sp.port.Close()
+	<-sp.done
+	return err
+}
+
+// readLoop copies everything read from the port to every subscriber.
+func (sp *sharedPort) readLoop() {
+	defer close(sp.done)
+	buf := make([]byte, 1024)
+
+	for {
+		n, err := sp.port.Read(buf)
+
+		sp.mu.Lock()
+		if sp.closed {
+			sp.mu.Unlock()
+			return
+		}
+		if n > 0 {
+			for _, h := range sp.subs {
+				h.deliver(buf[:n])
+			}
+		}
+		if err != nil {
+			sp.err = err
+			for _, h := range sp.subs {
+				h.wake()
+			}
+			sp.mu.Unlock()
+			// A failed port can be opened again by the next Open
+			sp.broker.forget(sp)
+			return
+		}
+		sp.mu.Unlock()
+	}
+}
+
+// brokerPort is a subscriber handle to a sharedPort.
+type brokerPort struct {
+	shared      *sharedPort
+	notify      chan struct{}
+	mu          sync.Mutex
+	buf         []byte
+	readTimeout time.Duration
+	closed      bool
+}
+
+// deliver appends data to the handle's buffer, dropping the oldest bytes
+// past brokerBacklog.
+func (h *brokerPort) deliver(data []byte) {
+	h.mu.Lock()
+	h.buf = append(h.buf, data...)
+	if over := len(h.buf) - brokerBacklog; over > 0 {
+		h.buf = h.buf[over:]
+	}
+	h.mu.Unlock()
+	h.wake()
+}
+
+// discardBacklog drops the bytes received since the last Read, such as
+// answers to another handle's commands, so they are not taken for the
+// answer to the next command. The other handles keep theirs.
+func (h *brokerPort) discardBacklog() {
+	h.mu.Lock()
+	h.buf = nil
+	h.mu.Unlock()
+}
+
+// discardBacklog drops what port has buffered for a command that is about
+// to start, if port is a broker handle or wraps one.
+func discardBacklog(port SerialPort) {
+	if b, ok := port.(interface{ discardBacklog() }); ok {
+		b.discardBacklog()
+	}
+}
+
+// wake nudges a Read waiting on this handle.
+func (h *brokerPort) wake() {
+	select {
+	case h.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Read returns bytes received since the last Read, waiting up to the read
+// timeout. It returns the port's error once the port has failed.
+func (h *brokerPort) Read(p []byte) (int, error) {
+	h.mu.Lock()
+	timeout := h.readTimeout
+	h.mu.Unlock()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		h.mu.Lock()
+		if h.closed {
+			h.mu.Unlock()
+			return 0, errors.New("broker: port closed")
+		}
+		if len(h.buf) > 0 {
+			n := copy(p, h.buf)
+			h.buf = h.buf[n:]
+			h.mu.Unlock()
+			return n, nil
+		}
+		h.mu.Unlock()
+
+		h.shared.mu.Lock()
+		err := h.shared.err
+		h.shared.mu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+
+		select {
+		case <-h.notify:
+		case <-timer.C:
+			return 0, nil
+		}
+	}
+}
+
+// Write sends p to the physical port, one handle at a time.
+func (h *brokerPort) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	closed := h.closed
+	h.mu.Unlock()
+	if closed {
+		return 0, errors.New("broker: port closed")
+	}
+
+	h.shared.writeMu.Lock()
+	defer h.shared.writeMu.Unlock()
+	return h.shared.port.Write(p)
+}
+
+// SetDTR drives DTR on the physical port, which every handle shares.
+func (h *brokerPort) SetDTR(level bool) error {
+	h.shared.writeMu.Lock()
+	defer h.shared.writeMu.Unlock()
+	return setLine(h.shared.port, LineDTR, level)
+}
+
+// SetRTS drives RTS on the physical port, which every handle shares.
+func (h *brokerPort) SetRTS(level bool) error {
+	h.shared.writeMu.Lock()
+	defer h.shared.writeMu.Unlock()
+	return setLine(h.shared.port, LineRTS, level)
+}
+
+// SetReadTimeout sets this handle's read timeout only.
+func (h *brokerPort) SetReadTimeout(d time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readTimeout = d
+	return nil
+}
+
+// Close releases the handle. Closing it twice is harmless.
+func (h *brokerPort) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	h.mu.Unlock()
+	h.wake()
+
+	return h.shared.unsubscribe(h)
+}
@@ -0,0 +1,235 @@
+package syscon
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// countingEmulator counts opens and closes of the physical port.
+type countingEmulator struct {
+	*Emulator
+	closes atomic.Int32
+}
+
+func (c *countingEmulator) Close() error {
+	c.closes.Add(1)
+	return c.Emulator.Close()
+}
+
+// brokerFor returns a broker whose physical port is a fresh emulator.
+func brokerFor(t *testing.T, cfg EmulatorConfig) (*Broker, *countingEmulator, *int) {
+	t.Helper()
+	opens := 0
+	dev := &countingEmulator{Emulator: NewEmulator(cfg)}
+	return NewBroker(func(portName string, mode *serial.Mode) (SerialPort, error) {
+		opens++
+		dev.mu.Lock()
+		dev.closed = false
+		dev.mu.Unlock()
+		return dev, nil
+	}), dev, &opens
+}
+
+func TestBrokerFansOutReads(t *testing.T) {
+	broker, _, _ := brokerFor(t, EmulatorConfig{Type: "CXR"})
+
+	cmdPort, err := broker.OpenPort("emu", 57600)
+	if err != nil {
+		t.Fatalf("OpenPort: %v", err)
+	}
+	defer cmdPort.Close()
+	monitorPort, err := broker.OpenPort("emu", 57600)
+	if err != nil {
+		t.Fatalf("second OpenPort: %v", err)
+	}
+	defer monitorPort.Close()
+
+	uart := NewPS3UARTWithPort(cmdPort, "CXR", 57600)
+	if result := mustCommand(t, uart, "VER", time.Second); result.Code != 0 {
+		t.Fatalf("VER through broker = %+v", result)
+	}
+
+	// The monitor saw the same answer without sending anything
+	monitorPort.SetReadTimeout(200 * time.Millisecond)
+	buf := make([]byte, 256)
+	n, _ := monitorPort.Read(buf)
+	if !strings.HasPrefix(string(buf[:n]), "R:") {
+		t.Errorf("monitor read %q, want the VER answer", buf[:n])
+	}
+}
+
+func TestBrokerClosesAfterLastHandle(t *testing.T) {
+	broker, dev, opens := brokerFor(t, EmulatorConfig{})
+
+	a, _ := broker.OpenPort("emu", 57600)
+	b, _ := broker.OpenPort("emu", 57600)
+	if *opens != 1 {
+		t.Errorf("physical opens = %d, want 1", *opens)
+	}
+	if got := broker.Subscribers("emu"); got != 2 {
+		t.Errorf("Subscribers = %d, want 2", got)
+	}
+
+	a.Close()
+	if dev.closes.Load() != 0 {
+		t.Error("physical port closed while a handle is still open")
+	}
+	b.Close()
+	b.Close()
+	if dev.closes.Load() != 1 {
+		t.Errorf("physical closes = %d, want 1", dev.closes.Load())
+	}
+	if got := broker.Subscribers("emu"); got != 0 {
+		t.Errorf("Subscribers after close = %d, want 0", got)
+	}
+
+	// The next Open starts a new physical port
+	c, err := broker.OpenPort("emu", 57600)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	c.Close()
+	if *opens != 2 {
+		t.Errorf("physical opens = %d, want 2", *opens)
+	}
+}
+
+func TestBrokerBaudMismatch(t *testing.T) {
+	broker, _, _ := brokerFor(t, EmulatorConfig{})
+
+	a, _ := broker.OpenPort("emu", 57600)
+	defer a.Close()
+
+	if _, err := broker.OpenPort("emu", 115200); !errors.Is(err, ErrPortBusy) {
+		t.Errorf("OpenPort at another baud error = %v, want %v", err, ErrPortBusy)
+	}
+}
+
+func TestBrokerOpenError(t *testing.T) {
+	broker := NewBroker(func(portName string, mode *serial.Mode) (SerialPort, error) {
+		return nil, errors.New("no such device")
+	})
+
+	if _, err := broker.OpenPort("missing", 57600); err == nil {
+		t.Error("OpenPort succeeded for a missing device")
+	}
+}
+
+// failingPort fails every read once fail is set.
+type failingPort struct {
+	*Emulator
+	fail atomic.Bool
+}
+
+func (f *failingPort) Read(p []byte) (int, error) {
+	if f.fail.Load() {
+		return 0, errors.New("device unplugged")
+	}
+	return f.Emulator.Read(p)
+}
+
+func TestBrokerDropsBacklogBeforeCommand(t *testing.T) {
+	broker, _, _ := brokerFor(t, EmulatorConfig{Type: "CXR"})
+
+	a, _ := broker.OpenPort("emu", 57600)
+	b, _ := broker.OpenPort("emu", 57600)
+	defer a.Close()
+	defer b.Close()
+
+	// b's answer also reaches a, which is not reading
+	mustCommand(t, NewPS3UARTWithPort(b, "CXR", 57600), "VER", time.Second)
+	time.Sleep(2 * brokerReadTimeout)
+
+	uart := NewPS3UARTWithPort(RecordingPort(a, nil, "emu", 57600), "CXR", 57600)
+	if result := mustCommand(t, uart, "VER", time.Second); result.Code != 0 {
+		t.Fatalf("VER = %+v", result)
+	}
+	a.SetReadTimeout(2 * brokerReadTimeout)
+	if n, _ := a.Read(make([]byte, 256)); n != 0 {
+		t.Errorf("%d bytes left after VER, want the earlier answer dropped", n)
+	}
+}
+
+func TestBrokerBacklogLimit(t *testing.T) {
+	h := &brokerPort{notify: make(chan struct{}, 1)}
+	h.deliver(make([]byte, brokerBacklog))
+	h.deliver([]byte("new"))
+	if len(h.buf) != brokerBacklog || !strings.HasSuffix(string(h.buf), "new") {
+		t.Errorf("backlog holds %d bytes ending %q, want the newest %d", len(h.buf), h.buf[len(h.buf)-3:], brokerBacklog)
+	}
+}
+
+func TestBrokerReadErrorReachesAllHandles(t *testing.T) {
+	dev := &failingPort{Emulator: NewEmulator(EmulatorConfig{})}
+	broker := NewBroker(func(portName string, mode *serial.Mode) (SerialPort, error) {
+		return dev, nil
+	})
+
+	a, _ := broker.OpenPort("emu", 57600)
+	b, _ := broker.OpenPort("emu", 57600)
+	defer a.Close()
+	defer b.Close()
+
+	dev.fail.Store(true)
+	for _, h := range []SerialPort{a, b} {
+		h.SetReadTimeout(time.Second)
+		if _, err := h.Read(make([]byte, 8)); err == nil {
+			t.Error("Read after device failure returned no error")
+		}
+	}
+}
+
+func TestBrokerSerializesWrites(t *testing.T) {
+	broker, _, _ := brokerFor(t, EmulatorConfig{Type: "CXRF"})
+
+	a, _ := broker.OpenPort("emu", 115200)
+	b, _ := broker.OpenPort("emu", 115200)
+	defer a.Close()
+	defer b.Close()
+
+	var wg sync.WaitGroup
+	for _, h := range []SerialPort{a, b} {
+		wg.Add(1)
+		go func(h SerialPort) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				h.Write([]byte("version\r\n"))
+			}
+		}(h)
+	}
+	wg.Wait()
+
+	// Every line reached the shell whole, so every answer is a version
+	time.Sleep(100 * time.Millisecond)
+	a.SetReadTimeout(100 * time.Millisecond)
+	var out strings.Builder
+	buf := make([]byte, 4096)
+	for {
+		n, _ := a.Read(buf)
+		if n == 0 {
+			break
+		}
+		out.Write(buf[:n])
+	}
+	if got := strings.Count(out.String(), "0C3D"); got != 40 {
+		t.Errorf("got %d version answers, want 40", got)
+	}
+}
+
+func TestBrokerFramingMismatch(t *testing.T) {
+	broker, _, _ := brokerFor(t, EmulatorConfig{})
+
+	a, _ := broker.OpenPort("emu", 57600)
+	defer a.Close()
+
+	mode, _ := ConnectionProfile{BaudRate: 57600, DataBits: 7, Parity: "even", StopBits: "1"}.Mode()
+	if _, err := broker.Open("emu", mode); !errors.Is(err, ErrPortBusy) {
+		t.Errorf("Open with other framing error = %v, want %v", err, ErrPortBusy)
+	}
+}
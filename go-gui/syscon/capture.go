@@ -0,0 +1,213 @@
+// Package syscon provides raw traffic capture for serial ports.
+package syscon
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Capture files are JSON Lines: one JSON object per line.
+//
+// The first line is a header:
+//
+//	{"format":"ps3syscon-capture","version":1,"started":"2026-01-02T15:04:05.000Z"}
+//
+// Every following line is one event:
+//
+//	{"t":1532000,"dir":"tx","hex":"433a44333a5645520d0a","text":"C:D3:VER\r\n"}
+//
+// t is nanoseconds since the header, from the monotonic clock. dir is "tx"
+// for bytes written to the port, "rx" for bytes read from it, "open" when
+// a port is opened (with port and baud) and "close" when it is closed. hex
+// holds the exact bytes; text is the same bytes as a string for reading by
+// eye and may not round-trip for binary data.
+//
+// CaptureFormat and CaptureVersion identify the header.
+const (
+	CaptureFormat  = "ps3syscon-capture"
+	CaptureVersion = 1
+)
+
+// Capture directions.
+const (
+	CaptureTX    = "tx"
+	CaptureRX    = "rx"
+	CaptureOpen  = "open"
+	CaptureClose = "close"
+)
+
+// CaptureHeader is the first line of a capture file.
+type CaptureHeader struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Started time.Time `json:"started"`
+}
+
+// CaptureEvent is one line of a capture file after the header.
+type CaptureEvent struct {
+	Time time.Duration `json:"t"`
+	Dir  string        `json:"dir"`
+	Hex  string        `json:"hex,omitempty"`
+	Text string        `json:"text,omitempty"`
+	Port string        `json:"port,omitempty"`
+	Baud int           `json:"baud,omitempty"`
+}
+
+// Recorder writes capture events to a file. It is safe for concurrent use,
+// so one recorder can serve several ports.
+type Recorder struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+	path   string
+	start  time.Time
+	err    error
+}
+
+// NewRecorder writes a capture to w, starting with the header.
+func NewRecorder(w io.Writer) (*Recorder, error) {
+	r := &Recorder{enc: json.NewEncoder(w), start: time.Now()}
+	if c, ok := w.(io.Closer); ok {
+		r.closer = c
+	}
+	header := CaptureHeader{Format: CaptureFormat, Version: CaptureVersion, Started: r.start.UTC()}
+	if err := r.enc.Encode(header); err != nil {
+		return nil, fmt.Errorf("writing capture header: %w", err)
+	}
+	return r, nil
+}
+
+// CreateRecorder creates the capture file at path.
+func CreateRecorder(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewRecorder(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.path = path
+	return r, nil
+}
+
+// Path returns the capture file path, or "" when not recording to a file.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Close stops recording and closes the underlying file.
+// It returns the first write error, if any.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closer != nil {
+		if err := r.closer.Close(); err != nil && r.err == nil {
+			r.err = err
+		}
+		r.closer = nil
+	}
+	r.enc = nil
+	return r.err
+}
+
+// record appends an event. Events after Close are dropped.
+func (r *Recorder) record(ev CaptureEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.enc == nil {
+		return
+	}
+	ev.Time = time.Since(r.start)
+	if err := r.enc.Encode(ev); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// recordData appends a tx or rx event for data.
+func (r *Recorder) recordData(dir string, data []byte) {
+	r.record(CaptureEvent{Dir: dir, Hex: hex.EncodeToString(data), Text: string(data)})
+}
+
+// recordingPort is a SerialPort that copies its traffic to a Recorder.
+// The recorder can be switched while a command is using the port.
+type recordingPort struct {
+	SerialPort
+	portName string
+	baudRate int
+
+	mu  sync.Mutex // guards rec only, never held during I/O
+	rec *Recorder  // nil while not recording
+}
+
+// RecordingPort wraps port so every Write and Read is recorded to rec.
+// It records an "open" event for portName at baudRate straight away.
+func RecordingPort(port SerialPort, rec *Recorder, portName string, baudRate int) SerialPort {
+	p := &recordingPort{SerialPort: port, portName: portName, baudRate: baudRate}
+	p.setRecorder(rec)
+	return p
+}
+
+// setRecorder switches the port to rec, recording an "open" event to it
+// first. A nil rec stops recording.
+func (p *recordingPort) setRecorder(rec *Recorder) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if rec != nil {
+		rec.record(CaptureEvent{Dir: CaptureOpen, Port: p.portName, Baud: p.baudRate})
+	}
+	p.rec = rec
+}
+
+// recorder returns the current recorder, or nil.
+func (p *recordingPort) recorder() *Recorder {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rec
+}
+
+func (p *recordingPort) Write(data []byte) (int, error) {
+	n, err := p.SerialPort.Write(data)
+	if rec := p.recorder(); rec != nil && n > 0 {
+		rec.recordData(CaptureTX, data[:n])
+	}
+	return n, err
+}
+
+func (p *recordingPort) Read(buf []byte) (int, error) {
+	n, err := p.SerialPort.Read(buf)
+	if rec := p.recorder(); rec != nil && n > 0 {
+		rec.recordData(CaptureRX, buf[:n])
+	}
+	return n, err
+}
+
+func (p *recordingPort) Close() error {
+	if rec := p.recorder(); rec != nil {
+		rec.record(CaptureEvent{Dir: CaptureClose})
+	}
+	return p.SerialPort.Close()
+}
+
+// discardBacklog drops what the wrapped port has buffered.
+func (p *recordingPort) discardBacklog() {
+	discardBacklog(p.SerialPort)
+}
+
+// SetDTR drives DTR on the wrapped port.
+func (p *recordingPort) SetDTR(level bool) error {
+	return setLine(p.SerialPort, LineDTR, level)
+}
+
+// SetRTS drives RTS on the wrapped port.
+func (p *recordingPort) SetRTS(level bool) error {
+	return setLine(p.SerialPort, LineRTS, level)
+}
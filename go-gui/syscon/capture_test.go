@@ -0,0 +1,166 @@
+package syscon
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readCapture parses a capture written by a Recorder.
+func readCapture(t *testing.T, data []byte) (CaptureHeader, []CaptureEvent) {
+	t.Helper()
+	header, events, err := ReadCapture(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadCapture: %v", err)
+	}
+	return header, events
+}
+
+func TestRecordingPort(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+
+	mock := &MockSerialPort{ReadData: []byte("R:3A:OK 00000000\r\n")}
+	port := RecordingPort(mock, rec, "/dev/ttyUSB0", 57600)
+	port.Write([]byte("C:D3:VER\r\n"))
+	port.Read(make([]byte, 64))
+	port.Close()
+
+	header, events := readCapture(t, buf.Bytes())
+	if header.Format != CaptureFormat || header.Version != CaptureVersion {
+		t.Errorf("header = %+v", header)
+	}
+
+	wantDirs := []string{CaptureOpen, CaptureTX, CaptureRX, CaptureClose}
+	if len(events) != len(wantDirs) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(wantDirs), events)
+	}
+	for i, dir := range wantDirs {
+		if events[i].Dir != dir {
+			t.Errorf("event %d dir = %q, want %q", i, events[i].Dir, dir)
+		}
+		if i > 0 && events[i].Time < events[i-1].Time {
+			t.Errorf("event %d time went backwards", i)
+		}
+	}
+
+	if events[0].Port != "/dev/ttyUSB0" || events[0].Baud != 57600 {
+		t.Errorf("open event = %+v", events[0])
+	}
+	if events[1].Hex != "433a44333a5645520d0a" {
+		t.Errorf("tx hex = %q", events[1].Hex)
+	}
+	if events[2].Text != "R:3A:OK 00000000\r\n" {
+		t.Errorf("rx text = %q", events[2].Text)
+	}
+}
+
+func TestRecorderDropsAfterClose(t *testing.T) {
+	var buf bytes.Buffer
+	rec, _ := NewRecorder(&buf)
+	port := RecordingPort(&MockSerialPort{}, rec, "p", 57600)
+
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	port.Write([]byte("VER"))
+
+	if _, events := readCapture(t, buf.Bytes()); len(events) != 1 {
+		t.Errorf("got %d events after Close, want only the open event", len(events))
+	}
+}
+
+func TestCreateRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	rec, err := CreateRecorder(path)
+	if err != nil {
+		t.Fatalf("CreateRecorder: %v", err)
+	}
+	if rec.Path() != path {
+		t.Errorf("Path() = %q, want %q", rec.Path(), path)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header, _ := readCapture(t, data); header.Format != CaptureFormat {
+		t.Errorf("header format = %q", header.Format)
+	}
+}
+
+func TestCreateRecorderBadPath(t *testing.T) {
+	if _, err := CreateRecorder(filepath.Join(t.TempDir(), "missing", "capture.jsonl")); err == nil {
+		t.Error("CreateRecorder into a missing directory succeeded")
+	}
+}
+
+func TestSessionSetRecorder(t *testing.T) {
+	mock := &MockSerialPort{
+		Responses: []string{
+			"R:3A:OK 00000000\r\n",
+			"R:3A:OK 00000000\r\n",
+		},
+	}
+	opener, _ := sessionOpener(mock)
+	s := NewSession(opener)
+	if err := s.Connect("/dev/ttyUSB0", "CXR", 57600); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	var buf bytes.Buffer
+	rec, _ := NewRecorder(&buf)
+	s.SetRecorder(rec)
+	if _, err := s.Command("VER", time.Second); err != nil {
+		t.Fatalf("Command: %v", err)
+	}
+
+	s.SetRecorder(nil)
+	if _, err := s.Command("VER", time.Second); err != nil {
+		t.Fatalf("Command: %v", err)
+	}
+
+	_, events := readCapture(t, buf.Bytes())
+	var tx, rx int
+	for _, ev := range events {
+		switch ev.Dir {
+		case CaptureTX:
+			tx++
+		case CaptureRX:
+			rx++
+		}
+	}
+	if tx == 0 || rx == 0 {
+		t.Errorf("recorded %d tx and %d rx events, want both", tx, rx)
+	}
+
+	// Only the first command was recorded; "VER" is sent as one chunk
+	if tx != 1 {
+		t.Errorf("recorded %d tx events, want 1", tx)
+	}
+}
+
+func TestSessionRecorderOnConnect(t *testing.T) {
+	opener, _ := sessionOpener(&MockSerialPort{})
+	s := NewSession(opener)
+
+	var buf bytes.Buffer
+	rec, _ := NewRecorder(&buf)
+	s.SetRecorder(rec)
+	if err := s.Connect("/dev/ttyUSB0", "CXR", 57600); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	_, events := readCapture(t, buf.Bytes())
+	if len(events) != 1 || events[0].Dir != CaptureOpen {
+		t.Errorf("events after Connect = %+v, want one open event", events)
+	}
+}
@@ -0,0 +1,253 @@
+// Package syscon provides the command catalog file and user overrides.
+package syscon
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// catalogJSON is the built-in command catalog.
+//
+//go:embed catalog.json
+var catalogJSON []byte
+
+// catalogFile is the layout of catalog.json and of override files: a list
+// of commands, each tagged with the mode it belongs to.
+type catalogFile struct {
+	Version  int               `json:"version"`
+	Commands []json.RawMessage `json:"commands"`
+}
+
+// catalog holds the command list of each mode. SW shares the internal
+// command set, so its list only holds the entries that differ from CXRF.
+type catalog struct {
+	CXR  []Command
+	CXRF []Command
+	SW   []Command
+}
+
+func init() {
+	c, err := catalog{}.merge(catalogJSON)
+	if err != nil {
+		panic("syscon: built-in catalog: " + err.Error())
+	}
+	c.use()
+}
+
+// currentCatalog returns the catalog behind the exported command lists.
+func currentCatalog() catalog {
+	var sw []Command
+	for _, cmd := range SWCommands {
+		if cmd.Mode == "SW" {
+			sw = append(sw, cmd)
+		}
+	}
+	return catalog{CXR: MullionCommands, CXRF: CXRFCommands, SW: sw}
+}
+
+// use publishes c as the exported command lists.
+func (c catalog) use() {
+	MullionCommands, CXRFCommands, SWCommands = c.CXR, c.CXRF, c.sherwood()
+}
+
+// sherwood returns the full SW command list: the CXRF commands, with the
+// SW entries replacing those of the same name and adding the rest.
+func (c catalog) sherwood() []Command {
+	cmds := slices.Clone(c.CXRF)
+	for _, sw := range c.SW {
+		if j := slices.IndexFunc(cmds, func(cmd Command) bool { return cmd.Name == sw.Name }); j >= 0 {
+			cmds[j] = sw
+		} else {
+			cmds = append(cmds, sw)
+		}
+	}
+	return cmds
+}
+
+// UnmarshalJSON reads a catalog entry. Permission is a hex string such as
+// "0x0000C0EF" and Timeout a duration such as "15s"; fields missing from
+// data keep their current value, so an override can correct one field.
+// Each subcommand in sub_args and sub_danger replaces the arguments or
+// danger of that subcommand.
+func (c *Command) UnmarshalJSON(data []byte) error {
+	type plain Command
+	aux := struct {
+		*plain
+		Permission string            `json:"permission"`
+		Timeout    string            `json:"timeout"`
+		Args       []Arg             `json:"args"`
+		SubArgs    map[string][]Arg  `json:"sub_args"`
+		SubDanger  map[string]Danger `json:"sub_danger"`
+		Idempotent json.RawMessage   `json:"idempotent"`
+		ReadOnly   json.RawMessage   `json:"read_only"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Idempotent != nil || aux.ReadOnly != nil {
+		return errors.New(`"idempotent" and "read_only" are replaced by danger "safe" in danger or sub_danger`)
+	}
+	if aux.Args != nil {
+		c.Args = aux.Args
+	}
+	for sub, schema := range aux.SubArgs {
+		if c.SubArgs == nil {
+			c.SubArgs = make(map[string][]Arg)
+		}
+		c.SubArgs[sub] = schema
+	}
+	for sub, d := range aux.SubDanger {
+		if c.SubDanger == nil {
+			c.SubDanger = make(map[string]Danger)
+		}
+		c.SubDanger[sub] = d
+	}
+	if aux.Permission != "" {
+		perm, err := strconv.ParseUint(aux.Permission, 0, 32)
+		if err != nil {
+			return fmt.Errorf("invalid permission %q", aux.Permission)
+		}
+		c.Permission = uint32(perm)
+	}
+	if aux.Timeout != "" {
+		timeout, err := time.ParseDuration(aux.Timeout)
+		if err != nil || timeout < 0 {
+			return fmt.Errorf("invalid timeout %q", aux.Timeout)
+		}
+		c.Timeout = timeout
+	}
+	return nil
+}
+
+// list returns the command list for mode, or nil for an unknown mode.
+func (c *catalog) list(mode string) *[]Command {
+	switch mode {
+	case "CXR":
+		return &c.CXR
+	case "CXRF":
+		return &c.CXRF
+	case "SW":
+		return &c.SW
+	default:
+		return nil
+	}
+}
+
+// clone returns a copy of c that shares no slices or maps with it.
+func (c catalog) clone() catalog {
+	cloneList := func(cmds []Command) []Command {
+		out := slices.Clone(cmds)
+		for i := range out {
+			out[i].Subcommands = slices.Clone(out[i].Subcommands)
+			out[i].Examples = slices.Clone(out[i].Examples)
+			out[i].Args = slices.Clone(out[i].Args)
+			out[i].SubArgs = maps.Clone(out[i].SubArgs)
+			out[i].SubDanger = maps.Clone(out[i].SubDanger)
+		}
+		return out
+	}
+	return catalog{CXR: cloneList(c.CXR), CXRF: cloneList(c.CXRF), SW: cloneList(c.SW)}
+}
+
+// merge returns c with the commands in data applied on top. An entry whose
+// mode and name match an existing command updates the fields it sets;
+// any other entry is added. c itself is left unchanged.
+func (c catalog) merge(data []byte) (catalog, error) {
+	var file catalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return c, err
+	}
+
+	out := c.clone()
+	for i, raw := range file.Commands {
+		var key struct {
+			Name string `json:"name"`
+			Mode string `json:"mode"`
+		}
+		if err := json.Unmarshal(raw, &key); err != nil {
+			return c, fmt.Errorf("command %d: %w", i+1, err)
+		}
+		if key.Mode == "CXR" {
+			key.Name = strings.ToUpper(key.Name)
+		}
+		if key.Name == "" {
+			return c, fmt.Errorf("command %d: no name", i+1)
+		}
+		cmds := out.list(key.Mode)
+		if cmds == nil {
+			return c, fmt.Errorf("command %s: unknown mode %q", key.Name, key.Mode)
+		}
+
+		j := slices.IndexFunc(*cmds, func(cmd Command) bool { return cmd.Name == key.Name })
+		if j < 0 {
+			*cmds = append(*cmds, Command{})
+			j = len(*cmds) - 1
+		}
+		cmd := &(*cmds)[j]
+		if err := json.Unmarshal(raw, cmd); err != nil {
+			return c, fmt.Errorf("command %s: %w", key.Name, err)
+		}
+		cmd.Name, cmd.Mode = key.Name, key.Mode
+		if err := validateCommand(cmd); err != nil {
+			return c, fmt.Errorf("command %s: %w", key.Name, err)
+		}
+	}
+	return out, nil
+}
+
+// validateCommand reports subcommand lists, argument definitions and
+// danger levels of a catalog entry that do not fit together.
+func validateCommand(cmd *Command) error {
+	if err := validateSchema(cmd.Args); err != nil {
+		return err
+	}
+	for sub, schema := range cmd.SubArgs {
+		if !slices.Contains(cmd.Subcommands, sub) {
+			return fmt.Errorf("arguments for %q, which is not a subcommand", sub)
+		}
+		if err := validateSchema(schema); err != nil {
+			return fmt.Errorf("%s: %w", sub, err)
+		}
+	}
+	if cmd.Danger != "" && !cmd.Danger.valid() {
+		return fmt.Errorf("unknown danger %q", cmd.Danger)
+	}
+	for sub, d := range cmd.SubDanger {
+		if !slices.Contains(cmd.Subcommands, sub) {
+			return fmt.Errorf("danger for %q, which is not a subcommand", sub)
+		}
+		if !d.valid() {
+			return fmt.Errorf("%s: unknown danger %q", sub, d)
+		}
+	}
+	return nil
+}
+
+// LoadCatalogOverride applies the commands in the file at path on top of
+// the catalog, adding new commands and correcting existing ones. The file
+// has the layout of the built-in catalog.json. A missing file is not an
+// error, and a file with an error leaves the catalog unchanged. Call it
+// before the catalog is used, as the lists are replaced without locking.
+func LoadCatalogOverride(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c, err := currentCatalog().merge(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	c.use()
+	return nil
+}
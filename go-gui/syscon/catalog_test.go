@@ -0,0 +1,151 @@
+package syscon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBuiltinCatalog(t *testing.T) {
+	for mode, cmds := range map[string][]Command{"CXR": MullionCommands, "CXRF": CXRFCommands} {
+		if len(cmds) == 0 {
+			t.Errorf("no %s commands loaded", mode)
+		}
+		for _, cmd := range cmds {
+			if cmd.Mode != mode {
+				t.Errorf("%s: mode %q in the %s list", cmd.Name, cmd.Mode, mode)
+			}
+		}
+	}
+
+	errlog := GetCommand("ERRLOG")
+	if errlog.Timeout != SlowCommandTimeout || errlog.Permission != 0x0000C0DF {
+		t.Errorf("ERRLOG = %+v, want the slow timeout and permission 0x0000C0DF", errlog)
+	}
+	if eep := GetCommand("EEP"); len(eep.Examples) == 0 {
+		t.Error("EEP has no examples")
+	}
+}
+
+func TestSWCommands(t *testing.T) {
+	if len(SWCommands) <= len(CXRFCommands) {
+		t.Fatalf("%d SW commands, want the %d CXRF commands and the SW entries", len(SWCommands), len(CXRFCommands))
+	}
+	for _, name := range []string{"auth", "AUTH1", "AUTH2", "SETCMDLONG"} {
+		if cmd := GetSWCommand(name); cmd == nil || cmd.Mode != "SW" {
+			t.Errorf("GetSWCommand(%q) = %+v, want an SW entry", name, cmd)
+		}
+	}
+	if cmd := GetSWCommand("eepcsum"); cmd == nil || cmd.Mode != "CXRF" {
+		t.Errorf("GetSWCommand(eepcsum) = %+v, want the shared CXRF entry", cmd)
+	}
+	if GetCXRFCommand("SETCMDLONG") != nil {
+		t.Error("SW entry SETCMDLONG leaked into the CXRF list")
+	}
+	if got := CommandTimeout("SW", "errlog"); got != SlowCommandTimeout {
+		t.Errorf("CommandTimeout(SW, errlog) = %v, want %v", got, SlowCommandTimeout)
+	}
+}
+
+func TestCatalogMerge(t *testing.T) {
+	base, err := catalog{}.merge(catalogJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	override := `{"commands": [
+		{"name": "ver", "mode": "CXR", "description": "Firmware version"},
+		{"name": "NEWCMD", "mode": "CXR", "subcommands": ["GET"], "permission": "0xC0DF", "timeout": "3s", "sub_danger": {"GET": "safe"}},
+		{"name": "tmp", "mode": "CXRF", "examples": ["tmp 3"]}
+	]}`
+	got, err := base.merge([]byte(override))
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	find := func(cmds []Command, name string) *Command {
+		for i := range cmds {
+			if cmds[i].Name == name {
+				return &cmds[i]
+			}
+		}
+		t.Fatalf("%s not found", name)
+		return nil
+	}
+
+	ver := find(got.CXR, "VER")
+	if ver.Description != "Firmware version" || ver.Permission != 0x0000C0FF || ver.Danger != DangerSafe {
+		t.Errorf("VER = %+v, want the new description and the other fields kept", ver)
+	}
+	newCmd := find(got.CXR, "NEWCMD")
+	if newCmd.Mode != "CXR" || newCmd.Permission != 0xC0DF || newCmd.Timeout != 3*time.Second {
+		t.Errorf("NEWCMD = %+v", newCmd)
+	}
+	if len(got.CXR) != len(base.CXR)+1 || len(got.CXRF) != len(base.CXRF) {
+		t.Errorf("merged catalog has %d/%d commands, want %d/%d",
+			len(got.CXR), len(got.CXRF), len(base.CXR)+1, len(base.CXRF))
+	}
+
+	if tmp := find(got.CXRF, "tmp"); len(tmp.Examples) != 1 || tmp.Examples[0] != "tmp 3" {
+		t.Errorf("tmp examples = %v", tmp.Examples)
+	}
+	if tmp := find(base.CXRF, "tmp"); tmp.Examples[0] != "tmp 0" {
+		t.Errorf("merge changed the base catalog: tmp examples = %v", tmp.Examples)
+	}
+}
+
+func TestCatalogMergeErrors(t *testing.T) {
+	tests := map[string]string{
+		"bad JSON":     `{"commands": [`,
+		"no name":      `{"commands": [{"mode": "CXR"}]}`,
+		"unknown mode": `{"commands": [{"name": "X", "mode": "PS2"}]}`,
+		"permission":   `{"commands": [{"name": "X", "mode": "CXR", "permission": "high"}]}`,
+		"timeout":      `{"commands": [{"name": "X", "mode": "CXR", "timeout": "soon"}]}`,
+		"read-only":    `{"commands": [{"name": "X", "mode": "CXR", "subcommands": ["GET"], "read_only": ["GET"]}]}`,
+		"idempotent":   `{"commands": [{"name": "X", "mode": "CXR", "idempotent": true}]}`,
+		"danger":       `{"commands": [{"name": "X", "mode": "CXR", "danger": "risky"}]}`,
+		"sub_danger":   `{"commands": [{"name": "X", "mode": "CXR", "sub_danger": {"SET": "destructive"}}]}`,
+	}
+	for name, data := range tests {
+		if _, err := (catalog{}).merge([]byte(data)); err == nil {
+			t.Errorf("%s: merge succeeded", name)
+		}
+	}
+}
+
+func TestLoadCatalogOverride(t *testing.T) {
+	cxr, cxrf, sw := MullionCommands, CXRFCommands, SWCommands
+	t.Cleanup(func() { MullionCommands, CXRFCommands, SWCommands = cxr, cxrf, sw })
+
+	dir := t.TempDir()
+	if err := LoadCatalogOverride(filepath.Join(dir, "missing.json")); err != nil {
+		t.Errorf("missing file: %v", err)
+	}
+
+	path := filepath.Join(dir, "commands.json")
+	bad := `{"commands": [{"name": "VER", "mode": "CXR", "description": "changed"}, {"name": "X"}]}`
+	if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCatalogOverride(path); err == nil {
+		t.Error("override with an unknown mode was accepted")
+	}
+	if GetCommand("VER").Description == "changed" {
+		t.Error("failed override changed the catalog")
+	}
+
+	good := `{"commands": [{"name": "hwinfo", "mode": "CXRF", "description": "Hardware info", "danger": "safe"}]}`
+	if err := os.WriteFile(path, []byte(good), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCatalogOverride(path); err != nil {
+		t.Fatalf("LoadCatalogOverride: %v", err)
+	}
+	if cmd := GetCXRFCommand("hwinfo"); cmd == nil || !IsIdempotent("CXRF", "hwinfo") {
+		t.Errorf("hwinfo = %+v, want the added command", cmd)
+	}
+	if GetSWCommand("hwinfo") == nil || GetSWCommand("SETCMDLONG") == nil {
+		t.Error("override did not carry over to the SW list")
+	}
+}
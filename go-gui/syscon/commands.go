@@ -0,0 +1,167 @@
+// Package syscon provides command definitions for PS3 Syscon UART communication.
+package syscon
+
+import (
+	"strings"
+	"time"
+)
+
+// Command represents a PS3 Syscon command with optional subcommands.
+type Command struct {
+	Name        string        `json:"name"`                  // Command name
+	Mode        string        `json:"mode"`                  // Catalog the command belongs to: CXR, CXRF or SW
+	Subcommands []string      `json:"subcommands,omitempty"` // Optional subcommands
+	Description string        `json:"description,omitempty"` // Human-readable description
+	Permission  uint32        `json:"-"`                     // Permission flags required for command
+	Timeout     time.Duration `json:"-"`                     // Response deadline; zero means DefaultCommandTimeout
+	Examples    []string      `json:"examples,omitempty"`    // Sample command lines
+
+	// Args defines the arguments of a command without subcommands and
+	// SubArgs those of each subcommand; see ArgSchema. A nil list means
+	// the arguments are not known and are sent as typed.
+	Args    []Arg            `json:"args,omitempty"`
+	SubArgs map[string][]Arg `json:"sub_args,omitempty"`
+
+	// Danger classifies what the command can do to the board and
+	// SubDanger overrides it for single subcommands; see DangerOf. Safe
+	// commands are also the ones a Session may re-send; see IsIdempotent.
+	Danger    Danger            `json:"danger,omitempty"`
+	SubDanger map[string]Danger `json:"sub_danger,omitempty"`
+}
+
+// MullionCommands contains all known Mullion (CXR) external commands.
+// These commands are available via UART at 57600 baud. The list is loaded
+// from the built-in catalog.json; see LoadCatalogOverride.
+var MullionCommands []Command
+
+// CXRFCommands contains all known CXRF internal (DIAG mode) commands.
+// These commands are available via UART at 115200 baud with DIAG pin grounded.
+var CXRFCommands []Command
+
+// SWCommands contains all known Sherwood (SW) commands. SW syscons share
+// the CXRF internal command set without DIAG mode, so the list holds the
+// CXRF commands plus the SW-specific entries, whose Mode is "SW".
+var SWCommands []Command
+
+// GetCommandNames returns a list of all CXR command names.
+func GetCommandNames() []string {
+	names := make([]string, len(MullionCommands))
+	for i, cmd := range MullionCommands {
+		names[i] = cmd.Name
+	}
+	return names
+}
+
+// GetCXRFCommandNames returns a list of all CXRF command names.
+func GetCXRFCommandNames() []string {
+	names := make([]string, len(CXRFCommands))
+	for i, cmd := range CXRFCommands {
+		names[i] = cmd.Name
+	}
+	return names
+}
+
+// GetSWCommandNames returns a list of all SW command names.
+func GetSWCommandNames() []string {
+	names := make([]string, len(SWCommands))
+	for i, cmd := range SWCommands {
+		names[i] = cmd.Name
+	}
+	return names
+}
+
+// GetCommand returns the CXR command with the given name (case-insensitive).
+func GetCommand(name string) *Command {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	for i := range MullionCommands {
+		if MullionCommands[i].Name == name {
+			return &MullionCommands[i]
+		}
+	}
+	return nil
+}
+
+// GetCXRFCommand returns the CXRF command with the given name. The shell
+// names are lower case, but a name typed in another case still finds its
+// command, so its danger and argument checks apply.
+func GetCXRFCommand(name string) *Command {
+	return findCommand(CXRFCommands, name)
+}
+
+// GetSWCommand returns the SW command with the given name, in any case
+// as for CXRF.
+func GetSWCommand(name string) *Command {
+	return findCommand(SWCommands, name)
+}
+
+// findCommand returns the command called name in cmds, preferring an exact
+// match over one that differs only in case.
+func findCommand(cmds []Command, name string) *Command {
+	name = strings.TrimSpace(name)
+	folded := -1
+	for i := range cmds {
+		if cmds[i].Name == name {
+			return &cmds[i]
+		}
+		if folded < 0 && strings.EqualFold(cmds[i].Name, name) {
+			folded = i
+		}
+	}
+	if folded < 0 {
+		return nil
+	}
+	return &cmds[folded]
+}
+
+// lookupCommand returns the command called name in the catalog for scType.
+func lookupCommand(scType, name string) *Command {
+	switch scType {
+	case "CXR":
+		return GetCommand(name)
+	case "SW":
+		return GetSWCommand(name)
+	default:
+		return GetCXRFCommand(name)
+	}
+}
+
+// CommandTimeout returns the response deadline for a command line in the
+// given mode, looked up by its first word in the matching catalog.
+func CommandTimeout(scType, cmdLine string) time.Duration {
+	fields := strings.Fields(cmdLine)
+	if len(fields) == 0 {
+		return DefaultCommandTimeout
+	}
+
+	cmd := lookupCommand(scType, fields[0])
+	if cmd == nil || cmd.Timeout == 0 {
+		return DefaultCommandTimeout
+	}
+	return cmd.Timeout
+}
+
+// handshakeCommands are safe but never re-sent, as each step of the
+// authentication handshake depends on the one before.
+var handshakeCommands = []string{"AUTH1", "AUTH2", "auth"}
+
+// IsIdempotent reports whether a command line in the given mode only reads,
+// so it can be re-sent safely: it is safe by the catalog's danger tags and
+// not a step of the authentication handshake. Commands missing from the
+// catalog are not.
+func IsIdempotent(scType, cmdLine string) bool {
+	fields := strings.Fields(cmdLine)
+	if len(fields) == 0 || lookupCommand(scType, fields[0]) == nil {
+		return false
+	}
+	for _, name := range handshakeCommands {
+		if strings.EqualFold(name, fields[0]) {
+			return false
+		}
+	}
+	return CommandDanger(scType, cmdLine) == DangerSafe
+}
+
+// HasSubcommands returns true if the command has subcommands.
+func (c *Command) HasSubcommands() bool {
+	return len(c.Subcommands) > 0
+}
@@ -1,4 +1,4 @@
-package main
+package syscon
 
 import (
 	"testing"
@@ -109,6 +109,21 @@ func TestGetCommand(t *testing.T) {
 	}
 }
 
+func TestGetCXRFCommandAnyCase(t *testing.T) {
+	for input, want := range map[string]string{"w": "w", "W": "w", " EEPCSUM ": "eepcsum", "nosuch": ""} {
+		got := ""
+		if cmd := GetCXRFCommand(input); cmd != nil {
+			got = cmd.Name
+		}
+		if got != want {
+			t.Errorf("GetCXRFCommand(%q) = %q, want %q", input, got, want)
+		}
+	}
+	if cmd := GetSWCommand("Auth"); cmd == nil || cmd.Name != "auth" {
+		t.Errorf("GetSWCommand(%q) = %+v, want auth", "Auth", cmd)
+	}
+}
+
 func TestGetCommandReturnsCorrectSubcommands(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -334,3 +349,40 @@ func TestGetCommandNamesNoDuplicates(t *testing.T) {
 		seen[name] = true
 	}
 }
+
+func TestIsIdempotent(t *testing.T) {
+	tests := []struct {
+		scType string
+		cmd    string
+		want   bool
+	}{
+		{"CXR", "VER", true},
+		{"CXR", "ver", true},
+		{"CXR", "EEP GET 3961 01", true},
+		{"CXR", "eep get 3961 01", true},
+		{"CXR", "EEP SET 3961 01 00", false},
+		{"CXR", "EEP INIT", false},
+		{"CXR", "EEP", false},
+		{"CXR", "W8 00003961 00", false},
+		{"CXR", "AUTH1 10", false},
+		{"CXR", "CID GET", true},
+		{"CXR", "NOSUCH", false},
+		{"CXR", "", false},
+		{"CXRF", "errlog", true},
+		{"CXRF", "r 3961 1", true},
+		{"CXRF", "w 3961 00", false},
+		{"CXRF", "eeprominit", false},
+		{"CXRF", "duty get", true},
+		{"CXRF", "duty set 0 0", false},
+		{"CXRF", "R 3961 1", true},
+		{"CXRF", "W 3961 FF", false},
+		{"SW", "auth", false},
+		{"SW", "eepcsum", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsIdempotent(tt.scType, tt.cmd); got != tt.want {
+			t.Errorf("IsIdempotent(%q, %q) = %v, want %v", tt.scType, tt.cmd, got, tt.want)
+		}
+	}
+}
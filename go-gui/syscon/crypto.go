@@ -1,5 +1,5 @@
-// Package main provides AES cryptographic functions for PS3 Syscon authentication.
-package main
+// Package syscon provides AES cryptographic functions for PS3 Syscon authentication.
+package syscon
 
 import (
 	"bytes"
@@ -1,4 +1,4 @@
-package main
+package syscon
 
 import (
 	"encoding/hex"
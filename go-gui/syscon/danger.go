@@ -0,0 +1,88 @@
+// Package syscon provides the danger classification of catalog commands
+// and the read-only mode check.
+package syscon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Danger says how much harm a command can do to the board.
+type Danger string
+
+// Danger levels used in the catalog.
+const (
+	DangerSafe        Danger = "safe"        // only reads or reports
+	DangerModifying   Danger = "modifying"   // changes state that can be set back
+	DangerDestructive Danger = "destructive" // can brick the board, such as EEPROM writes and firmware updates
+)
+
+// valid reports whether d is a known level.
+func (d Danger) valid() bool {
+	switch d {
+	case DangerSafe, DangerModifying, DangerDestructive:
+		return true
+	}
+	return false
+}
+
+// DangerOf returns the danger of the command, or of its subcommand sub.
+// A subcommand listed in SubDanger has its own level. A command without a
+// tag, such as one added by an override, is modifying.
+func (c *Command) DangerOf(sub string) Danger {
+	if sub != "" {
+		for name, d := range c.SubDanger {
+			if strings.EqualFold(name, sub) {
+				return d
+			}
+		}
+	}
+	if c.Danger == "" {
+		return DangerModifying
+	}
+	return c.Danger
+}
+
+// CommandDanger returns the danger of a command line in the given mode.
+// Commands missing from the catalog are treated as modifying, since
+// nothing is known about them.
+func CommandDanger(scType, cmdLine string) Danger {
+	fields := strings.Fields(cmdLine)
+	if len(fields) == 0 {
+		return DangerSafe
+	}
+	cmd := lookupCommand(scType, fields[0])
+	if cmd == nil {
+		return DangerModifying
+	}
+	sub := ""
+	if len(fields) > 1 {
+		sub = fields[1]
+	}
+	return cmd.DangerOf(sub)
+}
+
+// ReadOnlyError reports a command refused in read-only mode. It wraps
+// ErrReadOnly.
+type ReadOnlyError struct {
+	Command string
+	Danger  Danger
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("%s: %s is %s", ErrReadOnly, e.Command, e.Danger)
+}
+
+func (e *ReadOnlyError) Unwrap() error {
+	return ErrReadOnly
+}
+
+// CheckReadOnly reports whether a command line in the given mode may run
+// in read-only mode, where only safe commands are sent.
+func CheckReadOnly(scType, cmdLine string) error {
+	d := CommandDanger(scType, cmdLine)
+	if d == DangerSafe {
+		return nil
+	}
+	return &ReadOnlyError{Command: strings.TrimSpace(cmdLine), Danger: d}
+}
@@ -0,0 +1,118 @@
+package syscon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandDanger(t *testing.T) {
+	tests := []struct {
+		scType string
+		line   string
+		want   Danger
+	}{
+		{"CXR", "VER", DangerSafe},
+		{"CXR", "EEP GET 3961 01", DangerSafe},
+		{"CXR", "eep set 3961 01 FF", DangerDestructive},
+		{"CXR", "EEP INIT", DangerDestructive},
+		{"CXR", "W8 3961 FF", DangerDestructive},
+		{"CXR", "FAN SETDUTY 40", DangerModifying},
+		{"CXR", "NOSUCH", DangerModifying},
+		{"CXRF", "eepcsum", DangerSafe},
+		{"CXRF", "w 39FE 38 00", DangerDestructive},
+		{"CXRF", "W 3961 FF", DangerDestructive},
+		{"CXRF", "clear_err last", DangerModifying},
+		{"CXRF", "clear_err eeprom", DangerDestructive},
+		{"CXRF", "duty get 0", DangerSafe},
+		{"SW", "eeprominit", DangerDestructive},
+		{"SW", "SETCMDLONG FF FF", DangerSafe},
+		{"CXR", "", DangerSafe},
+	}
+	for _, tt := range tests {
+		if got := CommandDanger(tt.scType, tt.line); got != tt.want {
+			t.Errorf("CommandDanger(%s, %q) = %s, want %s", tt.scType, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogDangerTags(t *testing.T) {
+	for _, cmds := range [][]Command{MullionCommands, CXRFCommands, SWCommands} {
+		for _, cmd := range cmds {
+			if cmd.Danger == "" {
+				t.Errorf("%s %s has no danger tag", cmd.Mode, cmd.Name)
+			}
+		}
+	}
+}
+
+func TestDangerOfDefaults(t *testing.T) {
+	cmd := &Command{Name: "x"}
+	if got := cmd.DangerOf(""); got != DangerModifying {
+		t.Errorf("untagged command = %s, want modifying", got)
+	}
+	cmd = &Command{Name: "x", Subcommands: []string{"get", "set"}, Danger: DangerModifying, SubDanger: map[string]Danger{"get": DangerSafe}}
+	if got := cmd.DangerOf("GET"); got != DangerSafe {
+		t.Errorf("safe subcommand = %s, want safe", got)
+	}
+	if got := cmd.DangerOf("set"); got != DangerModifying {
+		t.Errorf("untagged subcommand = %s, want modifying", got)
+	}
+}
+
+func TestCheckReadOnly(t *testing.T) {
+	if err := CheckReadOnly("CXRF", "errlog"); err != nil {
+		t.Errorf("errlog: %v", err)
+	}
+	err := CheckReadOnly("CXRF", "rtcreset")
+	if !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("rtcreset: %v, want ErrReadOnly", err)
+	}
+	if want := "read-only mode: rtcreset is destructive"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
+
+func TestSessionReadOnly(t *testing.T) {
+	s, port := retrySession(t, "CXR", "R:3A:OK 00000000\r\n")
+	s.SetReadOnly(true)
+	if !s.ReadOnly() {
+		t.Fatal("ReadOnly() = false after SetReadOnly(true)")
+	}
+
+	if _, err := s.Command("W8 3961 FF", 50*time.Millisecond); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("W8 error = %v, want ErrReadOnly", err)
+	}
+	if len(port.WriteData) != 0 {
+		t.Errorf("read-only mode sent %q", port.WriteData)
+	}
+	if _, err := s.Command("VER", 50*time.Millisecond); err != nil {
+		t.Errorf("VER in read-only mode: %v", err)
+	}
+}
+
+func TestFrameCommand(t *testing.T) {
+	long := "w 3900 " + strings.Repeat("FF ", 20)
+	tests := []struct {
+		scType  string
+		cmd     string
+		answers []string
+	}{
+		{"CXR", "VER", []string{"R:3A:OK 00000000\r\n"}},
+		{"CXR", "EEP GET 3961 01", []string{"R:3A:OK 00000000\r\n"}},
+		{"SW", "version", []string{"OK 00000000:3A\r\n"}},
+		{"SW", long, []string{"OK 00000000:3A\r\n", "OK 00000000:3A\r\n"}},
+		{"CXRF", "eepcsum", []string{"sum:0x0100\r\n$ "}},
+	}
+	for _, tt := range tests {
+		s, port := retrySession(t, tt.scType, tt.answers...)
+		if _, err := s.Command(tt.cmd, 200*time.Millisecond); err != nil {
+			t.Errorf("%s %q: %v", tt.scType, tt.cmd, err)
+			continue
+		}
+		if got, want := string(port.WriteData), strings.Join(FrameCommand(tt.scType, tt.cmd), ""); got != want {
+			t.Errorf("%s %q sent %q, FrameCommand gives %q", tt.scType, tt.cmd, got, want)
+		}
+	}
+}
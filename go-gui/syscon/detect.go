@@ -0,0 +1,170 @@
+// Package syscon provides syscon type and baud-rate detection.
+package syscon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Confidence rates how sure Detect is about its answer.
+type Confidence int
+
+// Confidence levels reported by Detect, weakest first.
+const (
+	ConfidenceNone   Confidence = iota // nothing readable came back
+	ConfidenceLow                      // readable text without recognisable framing
+	ConfidenceMedium                   // the right framing, but damaged by line noise
+	ConfidenceHigh                     // a complete, checksummed answer
+)
+
+// String returns the label shown next to a detection result.
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceHigh:
+		return "high"
+	case ConfidenceMedium:
+		return "medium"
+	case ConfidenceLow:
+		return "low"
+	default:
+		return "none"
+	}
+}
+
+// Detection is the outcome of probing a port.
+type Detection struct {
+	SCType     string // "CXR", "CXRF" or "SW"; empty when nothing answered
+	BaudRate   int
+	Confidence Confidence
+}
+
+// String describes the detection for the user.
+func (d Detection) String() string {
+	if d.Confidence == ConfidenceNone {
+		return "no syscon answered"
+	}
+	return fmt.Sprintf("%s at %d baud (%s confidence)", d.SCType, d.BaudRate, d.Confidence)
+}
+
+// DetectBaudRates are the speeds Detect tries, in order.
+var DetectBaudRates = []int{57600, 115200}
+
+// probeTimeout bounds the wait for the answer to a single probe.
+const probeTimeout = 300 * time.Millisecond
+
+// detectProbe is a harmless line sent to find out who is listening.
+type detectProbe struct {
+	line     string
+	complete func(string) bool
+}
+
+// detectProbes returns the probes in the order Detect sends them: a CXR
+// framed VER, an SW framed VER, then a bare newline that makes the CXRF
+// shell print its prompt.
+func detectProbes() []detectProbe {
+	return []detectProbe{
+		{"C:" + checksum("VER") + ":VER\r\n", cxrComplete},
+		{"VER:" + checksum("VER") + "\r\n", swComplete},
+		{"\r\n", cxrfComplete},
+	}
+}
+
+// Detect probes portName at each of DetectBaudRates and classifies the
+// answers. It returns as soon as one answer is certain, otherwise the best
+// guess. The port is closed again before Detect returns.
+func Detect(ctx context.Context, portName string, opener SerialPortOpener) (Detection, error) {
+	var best Detection
+	for _, baud := range DetectBaudRates {
+		found, err := detectAt(ctx, portName, baud, opener)
+		if err != nil {
+			return best, err
+		}
+		if found.Confidence > best.Confidence {
+			best = found
+		}
+		if best.Confidence == ConfidenceHigh {
+			break
+		}
+	}
+	return best, nil
+}
+
+// detectAt runs the probes at one baud rate.
+func detectAt(ctx context.Context, portName string, baud int, opener SerialPortOpener) (Detection, error) {
+	uart, err := NewPS3UARTWithOpener(portName, "", baud, opener)
+	if err != nil {
+		return Detection{}, err
+	}
+	defer uart.Close()
+
+	best := Detection{BaudRate: baud}
+	for _, probe := range detectProbes() {
+		if err := uart.send(ctx, probe.line); err != nil {
+			return best, err
+		}
+		reply := uart.receiveUntil(ctx, probeTimeout, probe.complete)
+		if err := ctx.Err(); err != nil {
+			return best, err
+		}
+		if err := uart.takeIOError(); err != nil {
+			return best, fmt.Errorf("%w: %v", ErrConnectionLost, err)
+		}
+
+		scType, confidence := classifyReply(reply)
+		if confidence > best.Confidence {
+			best.SCType = scType
+			best.Confidence = confidence
+		}
+		// Garbage means the wrong baud rate; no other probe will do better
+		if best.Confidence == ConfidenceHigh || (reply != "" && !printable(reply)) {
+			break
+		}
+	}
+	return best, nil
+}
+
+// classifyReply works out which protocol produced reply.
+func classifyReply(reply string) (string, Confidence) {
+	lines := completeLines(reply)
+	for _, line := range lines {
+		if cxrLine(line) {
+			return "CXR", ConfidenceHigh
+		}
+	}
+	if swComplete(reply) {
+		return "SW", ConfidenceHigh
+	}
+	if cxrfComplete(reply) {
+		return "CXRF", ConfidenceHigh
+	}
+
+	if !printable(reply) || strings.TrimSpace(reply) == "" {
+		return "", ConfidenceNone
+	}
+
+	// Framing is recognisable even when noise broke the checksum
+	for _, line := range lines {
+		if strings.HasPrefix(line, "R:") || strings.HasPrefix(line, "E:") {
+			return "CXR", ConfidenceMedium
+		}
+		if i := strings.LastIndex(line, ":"); i >= 0 && isHex(line[i+1:], 2) {
+			return "SW", ConfidenceMedium
+		}
+	}
+
+	// Unframed text is most likely the internal shell
+	return "CXRF", ConfidenceLow
+}
+
+// printable reports whether s is text rather than the garbage a wrong baud
+// rate produces.
+func printable(s string) bool {
+	for _, c := range s {
+		if (c < 0x20 || c > 0x7E) && c != '\r' && c != '\n' && c != '\t' {
+			return false
+		}
+	}
+	return true
+}
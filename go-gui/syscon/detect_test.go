@@ -0,0 +1,125 @@
+package syscon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// emulatorOpener opens emu at whatever baud the caller asks for.
+func emulatorOpener(emu *Emulator) SerialPortOpener {
+	return func(portName string, mode *serial.Mode) (SerialPort, error) {
+		emu.mu.Lock()
+		emu.closed = false
+		emu.mu.Unlock()
+		emu.SetBaudRate(mode.BaudRate)
+		return emu, nil
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		cfg  EmulatorConfig
+		want Detection
+	}{
+		{EmulatorConfig{Type: "CXR", BaudRate: 57600}, Detection{"CXR", 57600, ConfidenceHigh}},
+		{EmulatorConfig{Type: "SW", BaudRate: 57600}, Detection{"SW", 57600, ConfidenceHigh}},
+		{EmulatorConfig{Type: "CXRF", BaudRate: 115200}, Detection{"CXRF", 115200, ConfidenceHigh}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cfg.Type, func(t *testing.T) {
+			emu := NewEmulator(tt.cfg)
+			got, err := Detect(context.Background(), "emu", emulatorOpener(emu))
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Detect = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectNoAnswer(t *testing.T) {
+	opener := func(portName string, mode *serial.Mode) (SerialPort, error) {
+		return &MockSerialPort{}, nil
+	}
+
+	got, err := Detect(context.Background(), "silent", opener)
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if got.Confidence != ConfidenceNone {
+		t.Errorf("Detect = %+v, want no confidence", got)
+	}
+	if got.String() != "no syscon answered" {
+		t.Errorf("String() = %q", got.String())
+	}
+}
+
+func TestDetectOpenFails(t *testing.T) {
+	opener := func(portName string, mode *serial.Mode) (SerialPort, error) {
+		return nil, errors.New("busy")
+	}
+
+	if _, err := Detect(context.Background(), "busy", opener); !errors.Is(err, ErrSerialOpenFailed) {
+		t.Errorf("Detect error = %v, want %v", err, ErrSerialOpenFailed)
+	}
+}
+
+func TestDetectCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	opener := func(portName string, mode *serial.Mode) (SerialPort, error) {
+		return &MockSerialPort{}, nil
+	}
+
+	if _, err := Detect(ctx, "silent", opener); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Detect error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClassifyReply(t *testing.T) {
+	tests := []struct {
+		name       string
+		reply      string
+		wantType   string
+		confidence Confidence
+	}{
+		{"CXR", "R:" + checksum("OK 00000000 0C3D") + ":OK 00000000 0C3D\r\n", "CXR", ConfidenceHigh},
+		{"CXR noisy", "R:00:OK 00000000 0C3D\r\n", "CXR", ConfidenceMedium},
+		{"SW", "OK 00000000:" + checksum("OK 00000000") + "\r\n", "SW", ConfidenceHigh},
+		{"SW noisy", "OK 00000000:00\r\n", "SW", ConfidenceMedium},
+		{"CXRF", "\r\n$ ", "CXRF", ConfidenceHigh},
+		{"text", "hello\r\n", "CXRF", ConfidenceLow},
+		{"garbage", "\xf8\xf8\xf8", "", ConfidenceNone},
+		{"empty", "", "", ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scType, confidence := classifyReply(tt.reply)
+			if scType != tt.wantType || confidence != tt.confidence {
+				t.Errorf("classifyReply(%q) = %q, %v; want %q, %v", tt.reply, scType, confidence, tt.wantType, tt.confidence)
+			}
+		})
+	}
+}
+
+func TestConfidenceString(t *testing.T) {
+	tests := map[Confidence]string{
+		ConfidenceNone:   "none",
+		ConfidenceLow:    "low",
+		ConfidenceMedium: "medium",
+		ConfidenceHigh:   "high",
+	}
+	for c, want := range tests {
+		if got := c.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", c, got, want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+// Package syscon implements the PS3 Syscon UART protocol.
+//
+// It covers the three syscon flavours the GUI supports:
+//
+//   - CXR: Mullion external mode, "C:xx:" framed commands at 57600 baud
+//   - CXRF: Mullion internal shell, plain text commands at 115200 baud
+//   - SW: Sherwood, "cmd:xx" framed commands
+//
+// PS3UART drives one open port: it frames commands, parses responses and
+// performs the AUTH1/AUTH2 handshake. Session keeps a PS3UART open between
+// commands and reopens the port after a transport failure. The command
+// catalogs (MullionCommands, CXRFCommands, SWCommands) describe the known
+// commands; they are loaded from the embedded catalog.json and can be
+// extended at run time with LoadCatalogOverride.
+//
+// The package has no GUI dependencies and can be imported by other tools.
+package syscon
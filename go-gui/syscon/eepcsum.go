@@ -0,0 +1,119 @@
+// Package syscon provides the eepcsum parser and the EEPROM checksum
+// repair.
+package syscon
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// eepcsumFailMarker precedes the line of a region whose stored checksum
+// is wrong.
+const eepcsumFailMarker = "sum:0x0100"
+
+var eepcsumAddr = regexp.MustCompile(`(?i)Addr:\s*0x([0-9a-f]{1,8})\s+should be\s+0x([0-9a-f]{1,4})`)
+
+// ChecksumRegion is one EEPROM region reported by eepcsum.
+type ChecksumRegion struct {
+	Addr     uint32 // offset of the stored checksum, such as 0x39FE
+	Expected uint16 // value the checksum should hold
+	Bad      bool   // flagged by a sum:0x0100 line
+}
+
+// FixCommand returns the w command that stores the expected checksum.
+// The EEPROM holds it little-endian, so the low byte is written first:
+// 0x0038 at 0x39FE is "w 39FE 38 00".
+func (r ChecksumRegion) FixCommand() string {
+	return fmt.Sprintf("w %04X %02X %02X", r.Addr, byte(r.Expected), byte(r.Expected>>8))
+}
+
+func (r ChecksumRegion) String() string {
+	state := "ok"
+	if r.Bad {
+		state = "bad"
+	}
+	return fmt.Sprintf("0x%04X should be 0x%04X (%s)", r.Addr, r.Expected, state)
+}
+
+// ParseEepcsum decodes the text printed by eepcsum, one region per
+// "Addr:0x000039fe should be 0x0038" line. A sum:0x0100 line marks the
+// region on the next line as bad.
+func ParseEepcsum(text string) []ChecksumRegion {
+	var regions []ChecksumRegion
+	bad := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.Contains(strings.ToLower(line), eepcsumFailMarker) {
+			bad = true
+		}
+		m := eepcsumAddr.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		addr, _ := strconv.ParseUint(m[1], 16, 32)
+		want, _ := strconv.ParseUint(m[2], 16, 16)
+		regions = append(regions, ChecksumRegion{Addr: uint32(addr), Expected: uint16(want), Bad: bad})
+		bad = false
+	}
+	return regions
+}
+
+// DecodeEepcsum decodes the answer to eepcsum in the given mode; ok is
+// false for other commands and for CXR, which has no eepcsum.
+func DecodeEepcsum(scType, cmdLine string, result CommandResult) (regions []ChecksumRegion, ok bool) {
+	if scType == "CXR" || strings.TrimSpace(cmdLine) != "eepcsum" {
+		return nil, false
+	}
+	return ParseEepcsum(resultText(result)), true
+}
+
+// BadChecksums returns the regions flagged as bad.
+func BadChecksums(regions []ChecksumRegion) []ChecksumRegion {
+	var bad []ChecksumRegion
+	for _, r := range regions {
+		if r.Bad {
+			bad = append(bad, r)
+		}
+	}
+	return bad
+}
+
+// ChecksumFixCommands returns the w commands that repair the bad regions.
+func ChecksumFixCommands(regions []ChecksumRegion) []string {
+	var cmds []string
+	for _, r := range BadChecksums(regions) {
+		cmds = append(cmds, r.FixCommand())
+	}
+	return cmds
+}
+
+// RepairChecksums sends the fix for every bad region, then runs eepcsum
+// again and returns what it reports. The error wraps ErrCommandFailed
+// when a write is refused, ErrInvalidResponse when eepcsum lists no
+// regions and ErrChecksumMismatch when a region is still bad afterwards.
+func RepairChecksums(ctx context.Context, send func(ctx context.Context, cmd string) (CommandResult, error), regions []ChecksumRegion) ([]ChecksumRegion, error) {
+	for _, cmd := range ChecksumFixCommands(regions) {
+		result, err := send(ctx, cmd)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cmd, err)
+		}
+		if result.Code != 0 {
+			return nil, fmt.Errorf("%w: %s returned %08X", ErrCommandFailed, cmd, result.Code)
+		}
+	}
+
+	result, err := send(ctx, "eepcsum")
+	if err != nil {
+		return nil, fmt.Errorf("eepcsum: %w", err)
+	}
+	after := ParseEepcsum(resultText(result))
+	if len(after) == 0 {
+		return nil, fmt.Errorf("%w: eepcsum reported no regions", ErrInvalidResponse)
+	}
+	if bad := BadChecksums(after); len(bad) > 0 {
+		return after, fmt.Errorf("%w: %s after repair", ErrChecksumMismatch, bad[0])
+	}
+	return after, nil
+}
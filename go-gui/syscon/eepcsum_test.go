@@ -0,0 +1,94 @@
+package syscon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// guideEepcsum is the example output from the guide.
+const guideEepcsum = "eepcsum\r\n" +
+	"Addr:0x000032fe should be 0x528c\r\n" +
+	"Addr:0x000034fe should be 0x7115\r\n" +
+	"sum:0x0100\r\n" +
+	"Addr:0x000039fe should be 0x0038\r\n" +
+	"Addr:0x00003dfe should be 0x00ff\r\n" +
+	"Addr:0x00003ffe should be 0x00ff\r\n"
+
+func TestParseEepcsum(t *testing.T) {
+	regions := ParseEepcsum(guideEepcsum)
+	if len(regions) != 5 {
+		t.Fatalf("got %d regions, want 5", len(regions))
+	}
+	if r := regions[0]; r.Addr != 0x32FE || r.Expected != 0x528C || r.Bad {
+		t.Errorf("first region = %+v", r)
+	}
+	bad := BadChecksums(regions)
+	if len(bad) != 1 || bad[0].Addr != 0x39FE || bad[0].Expected != 0x0038 {
+		t.Fatalf("bad regions = %+v, want 0x39FE", bad)
+	}
+	if got := ChecksumFixCommands(regions); len(got) != 1 || got[0] != "w 39FE 38 00" {
+		t.Errorf("fix commands = %q, want the guide's w 39FE 38 00", got)
+	}
+	if got := (ChecksumRegion{Addr: 0x32FE, Expected: 0x528C}).FixCommand(); got != "w 32FE 8C 52" {
+		t.Errorf("FixCommand = %q, want the bytes swapped", got)
+	}
+
+	if _, ok := DecodeEepcsum("CXR", "eepcsum", CommandResult{}); ok {
+		t.Error("CXR answer decoded as eepcsum")
+	}
+	if regions, ok := DecodeEepcsum("CXRF", "eepcsum", CommandResult{Data: []string{guideEepcsum}}); !ok || len(regions) != 5 {
+		t.Errorf("CXRF eepcsum = %+v, %v", regions, ok)
+	}
+}
+
+func TestRepairChecksums(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{Type: "CXRF"})
+	s := NewSession(func(string, *serial.Mode) (SerialPort, error) { return emu, nil })
+	if err := s.Connect("emu", "CXRF", 115200); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := s.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+	if _, err := s.Command("w 3961 00", time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.Command("eepcsum", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regions, _ := DecodeEepcsum("CXRF", "eepcsum", result)
+	if bad := BadChecksums(regions); len(bad) != 1 || bad[0].Addr != 0x39FE {
+		t.Fatalf("bad regions after the write = %+v", bad)
+	}
+
+	after, err := RepairChecksums(context.Background(), s.CommandContext, regions)
+	if err != nil {
+		t.Fatalf("RepairChecksums: %v", err)
+	}
+	if len(after) != len(regions) || len(BadChecksums(after)) != 0 {
+		t.Errorf("eepcsum after repair = %+v", after)
+	}
+}
+
+func TestRepairChecksumsStillBad(t *testing.T) {
+	send := func(ctx context.Context, cmd string) (CommandResult, error) {
+		return CommandResult{Data: []string{guideEepcsum}}, nil
+	}
+	after, err := RepairChecksums(context.Background(), send, ParseEepcsum(guideEepcsum))
+	if !errors.Is(err, ErrChecksumMismatch) || len(after) != 5 {
+		t.Errorf("RepairChecksums = %d regions, %v; want ErrChecksumMismatch", len(after), err)
+	}
+
+	refused := func(ctx context.Context, cmd string) (CommandResult, error) {
+		return CommandResult{Code: EmuStatusNotAuthorized}, nil
+	}
+	if _, err := RepairChecksums(context.Background(), refused, ParseEepcsum(guideEepcsum)); !errors.Is(err, ErrCommandFailed) {
+		t.Errorf("refused write: %v, want ErrCommandFailed", err)
+	}
+}
@@ -0,0 +1,270 @@
+// Package syscon provides the EEPROM dump.
+package syscon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EEPROMSize is the size of the syscon EEPROM address space walked by a
+// dump.
+const EEPROMSize = 0x4000
+
+// dumpReader reads one chunk of the EEPROM in a mode.
+type dumpReader struct {
+	chunk   int                                                    // bytes read by one command
+	command func(offset, size int) string                          // command reading size bytes at offset
+	version string                                                 // command answering the firmware version
+	boardID string                                                 // command answering the board ID
+	decode  func(result CommandResult, offset int) ([]byte, error) // bytes answered for offset
+}
+
+// dumpReaders holds the read command of each mode. EEP GET takes a
+// one-byte length and answers in a single CXR line, so CXR reads 0x80
+// bytes at a time. The CXRF shell prints r output 16 bytes per line with
+// no line limit. SW reads whole words with r32; its command lines stay
+// under the 0x40 characters that need SETCMDLONG, which the transport
+// sends first should a longer line ever be used.
+var dumpReaders = map[string]dumpReader{
+	"CXR": {
+		chunk: 0x80,
+		command: func(offset, size int) string {
+			return fmt.Sprintf("EEP GET %04X %02X", offset, size)
+		},
+		version: "VER",
+		boardID: "CID GET",
+		decode:  decodeEEPGet,
+	},
+	"CXRF": {
+		chunk: 0x100,
+		command: func(offset, size int) string {
+			return fmt.Sprintf("r %04X %X", offset, size)
+		},
+		version: "version",
+		boardID: "bsn",
+		decode:  decodeMemoryDump,
+	},
+	"SW": {
+		chunk: 0x100,
+		command: func(offset, size int) string {
+			return fmt.Sprintf("r32 %04X %X", offset, size/4)
+		},
+		version: "version",
+		boardID: "bsn",
+		decode:  decodeMemoryDump,
+	},
+}
+
+// DumpChunk records one chunk of a dump in its sidecar.
+type DumpChunk struct {
+	Offset  int    `json:"offset"`
+	Size    int    `json:"size"`
+	Command string `json:"command"`
+	CRC32   string `json:"crc32"` // IEEE CRC-32 of the chunk, 8 hex digits
+}
+
+// DumpInfo is the JSON sidecar written next to a dump.
+type DumpInfo struct {
+	Mode           string      `json:"mode"`
+	BoardID        string      `json:"board_id,omitempty"`         // empty when the console did not answer
+	BoardIDCommand string      `json:"board_id_command,omitempty"` // command that answered BoardID
+	Version        string      `json:"version,omitempty"`          // firmware version
+	Timestamp      time.Time   `json:"timestamp"`                  // when the last chunk was read
+	Size           int         `json:"size"`
+	SHA256         string      `json:"sha256"`
+	Chunks         []DumpChunk `json:"chunks"`
+}
+
+// EEPROMDumper reads the EEPROM chunk by chunk. A failed chunk stops Run
+// without losing the chunks already read, so calling Run again resumes
+// at the chunk that failed.
+type EEPROMDumper struct {
+	reader     dumpReader
+	info       DumpInfo
+	data       []byte
+	identified bool
+}
+
+// NewEEPROMDumper returns a dumper for the given mode.
+func NewEEPROMDumper(scType string) (*EEPROMDumper, error) {
+	reader, ok := dumpReaders[scType]
+	if !ok {
+		return nil, fmt.Errorf("%w: no EEPROM dump in %q mode", ErrInvalidArgument, scType)
+	}
+	return &EEPROMDumper{reader: reader, info: DumpInfo{Mode: scType}}, nil
+}
+
+// Run reads the remaining chunks, calling progress with the bytes read so
+// far after each one. The console must already be authenticated. The
+// firmware version and board ID are asked for once, before the first
+// chunk; a console that does not answer them leaves them empty.
+func (d *EEPROMDumper) Run(ctx context.Context, send func(ctx context.Context, cmd string) (CommandResult, error), progress func(done, total int)) error {
+	if !d.identified {
+		d.info.Version = d.ask(ctx, send, d.reader.version)
+		if d.info.BoardID = d.ask(ctx, send, d.reader.boardID); d.info.BoardID != "" {
+			d.info.BoardIDCommand = d.reader.boardID
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		d.identified = true
+	}
+
+	if progress != nil {
+		progress(len(d.data), EEPROMSize)
+	}
+	for len(d.data) < EEPROMSize {
+		offset := len(d.data)
+		size := min(d.reader.chunk, EEPROMSize-offset)
+		cmd := d.reader.command(offset, size)
+		result, err := send(ctx, cmd)
+		if err != nil {
+			return fmt.Errorf("chunk at %04X: %s: %w", offset, cmd, err)
+		}
+		data, err := d.reader.decode(result, offset)
+		if err != nil {
+			return fmt.Errorf("chunk at %04X: %s: %w", offset, cmd, err)
+		}
+		if len(data) != size {
+			return fmt.Errorf("%w: chunk at %04X: %s answered %d bytes, want %d", ErrInvalidResponse, offset, cmd, len(data), size)
+		}
+
+		d.data = append(d.data, data...)
+		d.info.Chunks = append(d.info.Chunks, DumpChunk{
+			Offset:  offset,
+			Size:    size,
+			Command: cmd,
+			CRC32:   fmt.Sprintf("%08x", crc32.ChecksumIEEE(data)),
+		})
+		if progress != nil {
+			progress(len(d.data), EEPROMSize)
+		}
+	}
+
+	sum := sha256.Sum256(d.data)
+	d.info.Size = len(d.data)
+	d.info.SHA256 = hex.EncodeToString(sum[:])
+	d.info.Timestamp = time.Now().UTC()
+	return nil
+}
+
+// ask sends cmd and returns the last line of its answer, or "" when the
+// command failed.
+func (d *EEPROMDumper) ask(ctx context.Context, send func(ctx context.Context, cmd string) (CommandResult, error), cmd string) string {
+	result, err := send(ctx, cmd)
+	if err != nil || result.Code != 0 {
+		return ""
+	}
+	var answer string
+	for _, line := range strings.Split(resultText(result), "\n") {
+		if line = strings.TrimSpace(line); line != "" && line != cmd {
+			answer = line
+		}
+	}
+	if strings.HasPrefix(answer, "Error") {
+		return ""
+	}
+	return answer
+}
+
+// Done reports whether every chunk has been read.
+func (d *EEPROMDumper) Done() bool {
+	return len(d.data) == EEPROMSize
+}
+
+// Progress returns the bytes read so far and the size of the EEPROM.
+func (d *EEPROMDumper) Progress() (done, total int) {
+	return len(d.data), EEPROMSize
+}
+
+// Data returns the bytes read so far.
+func (d *EEPROMDumper) Data() []byte {
+	return d.data
+}
+
+// Info returns the sidecar of a finished dump.
+func (d *EEPROMDumper) Info() DumpInfo {
+	return d.info
+}
+
+// Save writes the finished dump to binPath and its sidecar next to it,
+// with the extension replaced by .json, and returns the sidecar path.
+func (d *EEPROMDumper) Save(binPath string) (string, error) {
+	if !d.Done() {
+		return "", errors.New("EEPROM dump is not finished")
+	}
+	info, err := json.MarshalIndent(d.info, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	jsonPath := strings.TrimSuffix(binPath, ".bin") + ".json"
+	if err := os.WriteFile(binPath, d.data, 0o644); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(jsonPath, append(info, '\n'), 0o644); err != nil {
+		return "", err
+	}
+	return jsonPath, nil
+}
+
+// decodeEEPGet returns the bytes answered by EEP GET.
+func decodeEEPGet(result CommandResult, offset int) ([]byte, error) {
+	if result.Code != 0 {
+		return nil, fmt.Errorf("%w: status %08X", ErrCommandFailed, result.Code)
+	}
+	if len(result.Data) != 1 {
+		return nil, fmt.Errorf("%w: EEP GET answered %d fields", ErrInvalidResponse, len(result.Data))
+	}
+	data, err := hex.DecodeString(result.Data[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return data, nil
+}
+
+// decodeMemoryDump returns the bytes printed by r, r16 or r32 as
+// "00003900: 01 02 ..." lines. Words are printed in memory order, so
+// their hex digits are the bytes as stored. Lines without an address,
+// such as the shell's echo of the command, are skipped; the others must
+// follow on from offset without a gap.
+func decodeMemoryDump(result CommandResult, offset int) ([]byte, error) {
+	if result.Code != 0 {
+		return nil, fmt.Errorf("%w: status %08X", ErrCommandFailed, result.Code)
+	}
+	var data []byte
+	lines := 0
+	for _, line := range strings.Split(resultText(result), "\n") {
+		addr, words, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		a, err := strconv.ParseUint(addr, 16, 32)
+		if err != nil {
+			continue
+		}
+		if want := offset + len(data); int(a) != want {
+			return nil, fmt.Errorf("%w: line at %04X, want %04X", ErrInvalidResponse, a, want)
+		}
+		for _, w := range strings.Fields(words) {
+			b, err := hex.DecodeString(w)
+			if err != nil {
+				return nil, fmt.Errorf("%w: word %q at %04X", ErrInvalidResponse, w, a)
+			}
+			data = append(data, b...)
+		}
+		lines++
+	}
+	if lines == 0 {
+		return nil, fmt.Errorf("%w: no dump lines in %q", ErrInvalidResponse, strings.TrimSpace(resultText(result)))
+	}
+	return data, nil
+}
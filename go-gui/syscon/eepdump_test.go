@@ -0,0 +1,173 @@
+package syscon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEEPROMDumper(t *testing.T) {
+	tests := []struct {
+		scType  string
+		chunks  int
+		first   string
+		boardID string
+	}{
+		{"CXR", EEPROMSize / 0x80, "EEP GET 0000 80", emuChipID},
+		{"CXRF", EEPROMSize / 0x100, "r 0000 100", emuBoardSerial},
+		{"SW", EEPROMSize / 0x100, "r32 0000 40", emuBoardSerial},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scType, func(t *testing.T) {
+			uart, emu := emulatorUART(t, tt.scType, EmulatorConfig{Type: tt.scType})
+			if err := uart.Auth(); err != nil {
+				t.Fatalf("Auth: %v", err)
+			}
+
+			d, err := NewEEPROMDumper(tt.scType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var last int
+			if err := d.Run(context.Background(), uart.CommandContext, func(done, total int) { last = done }); err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if !d.Done() || last != EEPROMSize {
+				t.Errorf("Done = %v, last progress %X, want finished at %X", d.Done(), last, EEPROMSize)
+			}
+			if !bytes.Equal(d.Data(), emu.EEPROM()) {
+				t.Error("dump differs from the emulated EEPROM")
+			}
+
+			info := d.Info()
+			if info.Version != "0C3D" || info.BoardID != tt.boardID {
+				t.Errorf("version %q, board ID %q, want 0C3D and %q", info.Version, info.BoardID, tt.boardID)
+			}
+			if len(info.Chunks) != tt.chunks || info.Chunks[0].Command != tt.first {
+				t.Fatalf("%d chunks starting with %+v, want %d starting with %q", len(info.Chunks), info.Chunks[0], tt.chunks, tt.first)
+			}
+			for _, c := range info.Chunks {
+				if want := fmt.Sprintf("%08x", crc32.ChecksumIEEE(emu.EEPROM()[c.Offset:c.Offset+c.Size])); c.CRC32 != want {
+					t.Errorf("chunk at %04X CRC32 = %s, want %s", c.Offset, c.CRC32, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEEPROMDumperResume(t *testing.T) {
+	uart, emu := emulatorUART(t, "CXRF", EmulatorConfig{Type: "CXRF"})
+	if err := uart.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+
+	calls := 0
+	send := func(ctx context.Context, cmd string) (CommandResult, error) {
+		calls++
+		if calls == 6 {
+			return CommandResult{}, &ResponseError{Err: ErrNoResponse}
+		}
+		return uart.CommandContext(ctx, cmd)
+	}
+
+	d, _ := NewEEPROMDumper("CXRF")
+	err := d.Run(context.Background(), send, nil)
+	if !errors.Is(err, ErrNoResponse) {
+		t.Fatalf("Run error = %v, want %v", err, ErrNoResponse)
+	}
+	if done, _ := d.Progress(); done != 0x300 || d.Done() {
+		t.Fatalf("progress after failure = %X, want 300", done)
+	}
+	if _, err := d.Save(filepath.Join(t.TempDir(), "eeprom.bin")); err == nil {
+		t.Error("Save of an unfinished dump succeeded")
+	}
+
+	if err := d.Run(context.Background(), send, nil); err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	if !bytes.Equal(d.Data(), emu.EEPROM()) {
+		t.Error("resumed dump differs from the emulated EEPROM")
+	}
+	if n := len(d.Info().Chunks); n != EEPROMSize/0x100 {
+		t.Errorf("%d chunks after resuming, want %d", n, EEPROMSize/0x100)
+	}
+}
+
+func TestEEPROMDumperNotAuthenticated(t *testing.T) {
+	uart, _ := emulatorUART(t, "CXR", EmulatorConfig{Type: "CXR"})
+	d, _ := NewEEPROMDumper("CXR")
+	if err := d.Run(context.Background(), uart.CommandContext, nil); !errors.Is(err, ErrCommandFailed) {
+		t.Errorf("Run error = %v, want %v", err, ErrCommandFailed)
+	}
+}
+
+func TestNewEEPROMDumperUnknownMode(t *testing.T) {
+	if _, err := NewEEPROMDumper("PS2"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestDecodeMemoryDump(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []byte
+		err  error
+	}{
+		{"bytes after echo", "r 3900 4\n00003900: 01 02 03 04", []byte{1, 2, 3, 4}, nil},
+		{"words", "00003900: 01020304 05060708", []byte{1, 2, 3, 4, 5, 6, 7, 8}, nil},
+		{"gap", "00003900: 01 02\n00003910: 03", nil, ErrInvalidResponse},
+		{"wrong start", "00003800: 01", nil, ErrInvalidResponse},
+		{"error text", "Error: bad address", nil, ErrInvalidResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeMemoryDump(CommandResult{Data: []string{tt.text}}, 0x3900)
+			if !errors.Is(err, tt.err) || !bytes.Equal(got, tt.want) {
+				t.Errorf("decodeMemoryDump = %X, %v, want %X, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestEEPROMDumperSave(t *testing.T) {
+	uart, emu := emulatorUART(t, "CXR", EmulatorConfig{Type: "CXR"})
+	if err := uart.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+	d, _ := NewEEPROMDumper("CXR")
+	if err := d.Run(context.Background(), uart.CommandContext, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	binPath := filepath.Join(t.TempDir(), "eeprom.bin")
+	jsonPath, err := d.Save(binPath)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if jsonPath != filepath.Join(filepath.Dir(binPath), "eeprom.json") {
+		t.Errorf("sidecar path = %s", jsonPath)
+	}
+
+	data, err := os.ReadFile(binPath)
+	if err != nil || !bytes.Equal(data, emu.EEPROM()) {
+		t.Errorf("dump file differs from the EEPROM (%v)", err)
+	}
+	raw, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info DumpInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		t.Fatalf("sidecar: %v", err)
+	}
+	if info.Mode != "CXR" || info.Size != EEPROMSize || info.SHA256 != d.Info().SHA256 || info.Timestamp.IsZero() || len(info.Chunks) != EEPROMSize/0x80 {
+		t.Errorf("sidecar = %+v", info)
+	}
+}
@@ -0,0 +1,685 @@
+// Package syscon provides an in-process virtual syscon for demos and tests.
+package syscon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DemoPortName is the port list entry that opens the built-in emulator.
+const DemoPortName = "Demo device"
+
+// Status codes returned by the emulator for rejected commands.
+const (
+	EmuStatusBadChecksum    uint32 = 0xF0000001
+	EmuStatusUnknownCommand uint32 = 0xF0000002
+	EmuStatusBadArguments   uint32 = 0xF0000003
+	EmuStatusTooLong        uint32 = 0xF0000004
+	EmuStatusNotAuthorized  uint32 = 0xF0000005
+)
+
+// EEPROM layout emulated by Emulator.
+const (
+	// EmuEEPROMSize is the size of the emulated EEPROM address space.
+	EmuEEPROMSize = 0x4000
+
+	// emuSWShortLimit is the longest SW line accepted before SETCMDLONG.
+	emuSWShortLimit = 0x40
+)
+
+// Identifiers answered by CID GET and bsn.
+const (
+	emuChipID      = "0A2B3C4D"
+	emuBoardSerial = "1C123456789"
+)
+
+// emuChecksumRegions are the 256-byte EEPROM regions covered by eepcsum.
+// Each region stores a little-endian 16-bit sum of its first 0xFE bytes in
+// its last two bytes.
+var emuChecksumRegions = []int{0x3200, 0x3400, 0x3900, 0x3D00, 0x3F00}
+
+// EmulatorFaults injects transport problems into the emulator's answers.
+type EmulatorFaults struct {
+	Delay        time.Duration // wait before an answer becomes readable
+	ChunkSize    int           // most bytes returned by one Read; 0 means no limit
+	CorruptEvery int           // corrupt the checksum of every Nth framed answer
+	DropEvery    int           // swallow every Nth answer
+	Echo         bool          // echo written bytes back, like shorted RX/TX
+}
+
+// EmulatorConfig configures a new Emulator.
+type EmulatorConfig struct {
+	Type     string // "CXR", "CXRF" or "SW"; empty answers whichever framing arrives
+	BaudRate int    // baud the device listens at; other rates read as garbage. 0 accepts any
+	Faults   EmulatorFaults
+}
+
+// emuOutput is a chunk of answer bytes that becomes readable at readyAt.
+type emuOutput struct {
+	data    []byte
+	readyAt time.Time
+}
+
+// Emulator is a virtual syscon that implements SerialPort.
+// It speaks CXR "C:xx:" framing, SW "cmd:xx" framing and the CXRF shell,
+// runs the AUTH1/AUTH2 handshake and keeps an EEPROM that answers
+// EEP GET/SET, r/w, eepcsum and errlog.
+type Emulator struct {
+	mu          sync.Mutex
+	cfg         EmulatorConfig
+	baudRate    int
+	readTimeout time.Duration
+	closed      bool
+	dtr, rts    bool // adapter control lines
+	input       []byte
+	output      []emuOutput
+	answers     int
+
+	eeprom        []byte
+	errlog        []uint32
+	authenticated bool
+	nonce         []byte
+	shellAuth     int // CXRF handshake step: 0 idle, 1 after scopen, 2 after AUTH1
+	longCommands  bool
+}
+
+// NewEmulator creates an open emulator with a factory-fresh EEPROM.
+func NewEmulator(cfg EmulatorConfig) *Emulator {
+	e := &Emulator{
+		cfg:         cfg,
+		baudRate:    cfg.BaudRate,
+		readTimeout: 100 * time.Millisecond,
+		dtr:         true,
+		rts:         true,
+		nonce:       mustDecodeHex("0123456789ABCDEF"),
+	}
+	e.resetEEPROM()
+	e.errlog = []uint32{0xA0022110, 0xA0801200, 0xA0403034}
+	return e
+}
+
+// demoEmulator backs DemoPortName so its EEPROM survives reconnects.
+var demoEmulator = NewEmulator(EmulatorConfig{})
+
+// openDemo reopens the shared demo emulator at baudRate.
+func openDemo(baudRate int) *Emulator {
+	e := demoEmulator
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = false
+	e.baudRate = baudRate
+	e.input = nil
+	e.output = nil
+	e.authenticated = false
+	e.shellAuth = 0
+	e.longCommands = false
+	return e
+}
+
+// SetFaults replaces the injected faults.
+func (e *Emulator) SetFaults(f EmulatorFaults) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cfg.Faults = f
+}
+
+// SetBaudRate changes the rate the host is talking at, as if the port had
+// been reopened with a different speed.
+func (e *Emulator) SetBaudRate(baudRate int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.baudRate = baudRate
+}
+
+// EEPROM returns a copy of the emulated EEPROM.
+func (e *Emulator) EEPROM() []byte {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]byte(nil), e.eeprom...)
+}
+
+// SetReadTimeout sets how long Read waits for an answer.
+func (e *Emulator) SetReadTimeout(d time.Duration) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.readTimeout = d
+	return nil
+}
+
+// SetDTR sets the emulated DTR line.
+func (e *Emulator) SetDTR(level bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.dtr = level
+	return nil
+}
+
+// SetRTS sets the emulated RTS line.
+func (e *Emulator) SetRTS(level bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rts = level
+	return nil
+}
+
+// Lines returns the DTR and RTS levels last set by the host.
+func (e *Emulator) Lines() (dtr, rts bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.dtr, e.rts
+}
+
+// Close closes the emulated port. The device state is kept.
+func (e *Emulator) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	return nil
+}
+
+// Write feeds host bytes to the emulator and queues any answers.
+func (e *Emulator) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return 0, errors.New("emulator: port closed")
+	}
+	if e.cfg.Faults.Echo {
+		e.queueLocked(append([]byte(nil), p...), false)
+	}
+	if e.cfg.BaudRate != 0 && e.baudRate != e.cfg.BaudRate {
+		// At the wrong baud every byte the syscon sends back is garbage
+		e.queueLocked(bytes.Repeat([]byte{0xF8}, len(p)/4+1), false)
+		return len(p), nil
+	}
+
+	e.input = append(e.input, p...)
+	for {
+		i := bytes.IndexByte(e.input, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(e.input[:i]), "\r")
+		e.input = e.input[i+1:]
+		e.handleLineLocked(line)
+	}
+	return len(p), nil
+}
+
+// Read returns queued answer bytes, waiting up to the read timeout.
+func (e *Emulator) Read(p []byte) (int, error) {
+	e.mu.Lock()
+	timeout := e.readTimeout
+	e.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		e.mu.Lock()
+		if e.closed {
+			e.mu.Unlock()
+			return 0, errors.New("emulator: port closed")
+		}
+		if n := e.readLocked(p); n > 0 {
+			e.mu.Unlock()
+			return n, nil
+		}
+		e.mu.Unlock()
+
+		if !time.Now().Before(deadline) {
+			return 0, nil
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// readLocked copies ready output into p, honouring the chunk size fault.
+func (e *Emulator) readLocked(p []byte) int {
+	limit := len(p)
+	if c := e.cfg.Faults.ChunkSize; c > 0 && c < limit {
+		limit = c
+	}
+
+	n := 0
+	now := time.Now()
+	for n < limit && len(e.output) > 0 && !now.Before(e.output[0].readyAt) {
+		out := &e.output[0]
+		copied := copy(p[n:limit], out.data)
+		out.data = out.data[copied:]
+		n += copied
+		if len(out.data) == 0 {
+			e.output = e.output[1:]
+		}
+	}
+	return n
+}
+
+// queueLocked schedules data for reading. Answers count towards the drop
+// fault; echoes and noise do not.
+func (e *Emulator) queueLocked(data []byte, answer bool) {
+	if answer {
+		e.answers++
+		if n := e.cfg.Faults.DropEvery; n > 0 && e.answers%n == 0 {
+			return
+		}
+	}
+	e.output = append(e.output, emuOutput{data: data, readyAt: time.Now().Add(e.cfg.Faults.Delay)})
+}
+
+// corruptLocked reports whether the next framed answer gets a bad checksum.
+func (e *Emulator) corruptLocked() bool {
+	n := e.cfg.Faults.CorruptEvery
+	return n > 0 && (e.answers+1)%n == 0
+}
+
+// handleLineLocked dispatches one host line by framing.
+func (e *Emulator) handleLineLocked(line string) {
+	switch {
+	case strings.HasPrefix(line, "C:") && e.accepts("CXR"):
+		e.handleCXRLocked(line)
+	case e.accepts("SW") && swFramed(line):
+		e.handleSWLocked(line)
+	case e.accepts("CXRF"):
+		e.handleShellLocked(line)
+	}
+}
+
+// accepts reports whether the emulator answers scType framing.
+func (e *Emulator) accepts(scType string) bool {
+	return e.cfg.Type == "" || e.cfg.Type == scType
+}
+
+// swFramed reports whether line carries a valid SW checksum suffix.
+func swFramed(line string) bool {
+	i := strings.LastIndex(line, ":")
+	return i > 0 && line[i+1:] == checksum(line[:i])
+}
+
+// handleCXRLocked answers a "C:xx:cmd" line.
+func (e *Emulator) handleCXRLocked(line string) {
+	parts := strings.SplitN(line, ":", 3)
+	if len(parts) != 3 || parts[1] != checksum(parts[2]) {
+		e.replyCXRLocked(EmuStatusBadChecksum, nil)
+		return
+	}
+	code, data := e.externalLocked(parts[2])
+	e.replyCXRLocked(code, data)
+}
+
+// replyCXRLocked queues an "R:xx:OK code data" or "E:xx:NG code" answer.
+func (e *Emulator) replyCXRLocked(code uint32, data []string) {
+	magic, body := "R", fmt.Sprintf("OK %08X", code)
+	if code != 0 {
+		magic, body = "E", fmt.Sprintf("NG %08X", code)
+	} else if len(data) > 0 {
+		body += " " + strings.Join(data, " ")
+	}
+	sum := checksum(body)
+	if e.corruptLocked() {
+		sum = "00"
+		if checksum(body) == "00" {
+			sum = "01"
+		}
+	}
+	e.queueLocked([]byte(fmt.Sprintf("%s:%s:%s\r\n", magic, sum, body)), true)
+}
+
+// handleSWLocked answers a "cmd:xx" line.
+func (e *Emulator) handleSWLocked(line string) {
+	cmd := line[:strings.LastIndex(line, ":")]
+	if len(cmd) >= emuSWShortLimit && !e.longCommands {
+		e.replySWLocked(EmuStatusTooLong, nil, nil)
+		return
+	}
+
+	name := strings.Fields(cmd + " ")[0]
+	switch strings.ToUpper(name) {
+	case "AUTH1", "AUTH2", "SETCMDLONG":
+		code, data := e.externalLocked(cmd)
+		e.replySWLocked(code, data, nil)
+	default:
+		lines, code := e.internalLocked(cmd)
+		e.replySWLocked(code, nil, lines)
+	}
+}
+
+// replySWLocked queues checksummed SW output lines and the status line.
+func (e *Emulator) replySWLocked(code uint32, data, lines []string) {
+	status := fmt.Sprintf("OK %08X", code)
+	if code != 0 {
+		status = fmt.Sprintf("NG %08X", code)
+	} else if len(data) > 0 {
+		status += " " + strings.Join(data, " ")
+	}
+
+	corrupt := e.corruptLocked()
+	var out strings.Builder
+	for _, l := range append(lines, status) {
+		sum := checksum(l)
+		if corrupt {
+			sum = "00"
+			if checksum(l) == "00" {
+				sum = "01"
+			}
+		}
+		fmt.Fprintf(&out, "%s:%s\r\n", l, sum)
+	}
+	e.queueLocked([]byte(out.String()), true)
+}
+
+// externalLocked runs a Mullion external command and returns its status
+// and data fields.
+func (e *Emulator) externalLocked(cmd string) (uint32, []string) {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return EmuStatusUnknownCommand, nil
+	}
+
+	name := strings.ToUpper(fields[0])
+	args := fields[1:]
+	switch name {
+	case "AUTH1":
+		if len(args) != 1 {
+			return EmuStatusBadArguments, nil
+		}
+		resp, err := e.auth1Locked(args[0])
+		if err != nil {
+			return EmuStatusBadArguments, nil
+		}
+		return 0, []string{resp}
+	case "AUTH2":
+		if len(args) != 1 || !e.auth2Locked(args[0]) {
+			return EmuStatusNotAuthorized, nil
+		}
+		return 0, nil
+	case "VER":
+		return 0, []string{"0C3D"}
+	case "SETCMDLONG":
+		e.longCommands = true
+		return 0, nil
+	}
+
+	if !e.authenticated {
+		return EmuStatusNotAuthorized, nil
+	}
+
+	switch name {
+	case "EEP":
+		return e.eepLocked(args)
+	case "CID":
+		if len(args) != 1 || strings.ToUpper(args[0]) != "GET" {
+			return EmuStatusBadArguments, nil
+		}
+		return 0, []string{emuChipID}
+	case "ERRLOG":
+		if len(args) == 2 && strings.ToUpper(args[0]) == "GET" {
+			i, err := strconv.ParseUint(args[1], 16, 8)
+			if err != nil || int(i) >= len(e.errlog) {
+				return EmuStatusBadArguments, nil
+			}
+			return 0, []string{fmt.Sprintf("%08X", e.errlog[i]), fmt.Sprintf("%08X", (i+1)*0x1F40)}
+		}
+		if len(args) == 1 && strings.ToUpper(args[0]) == "CLEAR" {
+			e.errlog = nil
+			return 0, nil
+		}
+		return EmuStatusBadArguments, nil
+	}
+	return EmuStatusUnknownCommand, nil
+}
+
+// eepLocked answers EEP GET/SET/INIT.
+func (e *Emulator) eepLocked(args []string) (uint32, []string) {
+	if len(args) == 1 && strings.ToUpper(args[0]) == "INIT" {
+		e.resetEEPROM()
+		return 0, nil
+	}
+	if len(args) < 3 {
+		return EmuStatusBadArguments, nil
+	}
+
+	offset, err1 := strconv.ParseUint(args[1], 16, 16)
+	length, err2 := strconv.ParseUint(args[2], 16, 8)
+	if err1 != nil || err2 != nil || length == 0 || int(offset+length) > EmuEEPROMSize {
+		return EmuStatusBadArguments, nil
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		if len(args) != 3 {
+			return EmuStatusBadArguments, nil
+		}
+		return 0, []string{strings.ToUpper(hex.EncodeToString(e.eeprom[offset : offset+length]))}
+	case "SET":
+		value, err := hex.DecodeString(strings.Join(args[3:], ""))
+		if err != nil || uint64(len(value)) != length {
+			return EmuStatusBadArguments, nil
+		}
+		copy(e.eeprom[offset:], value)
+		return 0, nil
+	}
+	return EmuStatusBadArguments, nil
+}
+
+// handleShellLocked answers one CXRF shell line: echo, output, prompt.
+func (e *Emulator) handleShellLocked(line string) {
+	lines, _ := e.shellLocked(line)
+	var out strings.Builder
+	out.WriteString(line + "\r\n")
+	for _, l := range lines {
+		out.WriteString(l + "\r\n")
+	}
+	out.WriteString(CXRFPrompt)
+	e.queueLocked([]byte(out.String()), true)
+}
+
+// shellLocked runs a CXRF shell line, including the bare-hex AUTH1/AUTH2
+// lines that follow scopen.
+func (e *Emulator) shellLocked(line string) ([]string, uint32) {
+	switch {
+	case strings.TrimSpace(line) == "scopen":
+		e.shellAuth = 1
+		return []string{"SC_READY"}, 0
+	case e.shellAuth == 1 && isHex(line, len(line)) && len(line) > 0:
+		resp, err := e.auth1Locked(line)
+		if err != nil {
+			e.shellAuth = 0
+			return []string{"SC_FAILED"}, EmuStatusBadArguments
+		}
+		e.shellAuth = 2
+		return []string{resp}, 0
+	case e.shellAuth == 2 && isHex(line, len(line)) && len(line) > 0:
+		e.shellAuth = 0
+		if !e.auth2Locked(line) {
+			return []string{"SC_FAILED"}, EmuStatusNotAuthorized
+		}
+		return []string{"SC_SUCCESS"}, 0
+	}
+	return e.internalLocked(line)
+}
+
+// internalLocked runs an internal command shared by CXRF and SW and
+// returns its output lines and status.
+func (e *Emulator) internalLocked(cmd string) ([]string, uint32) {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return nil, 0
+	}
+
+	name, args := fields[0], fields[1:]
+	if name == "version" {
+		return []string{"0C3D"}, 0
+	}
+	if !e.authenticated {
+		return []string{"Error: not authenticated"}, EmuStatusNotAuthorized
+	}
+
+	switch name {
+	case "r", "r16", "r32":
+		return e.readMemLocked(name, args)
+	case "w", "w16", "w32":
+		return e.writeMemLocked(name, args)
+	case "eepcsum":
+		return e.eepcsumLocked(), 0
+	case "bsn":
+		return []string{emuBoardSerial}, 0
+	case "errlog":
+		lines := make([]string, len(e.errlog))
+		for i, code := range e.errlog {
+			lines[i] = fmt.Sprintf("%02X: %08X %08X", i, code, (i+1)*0x1F40)
+		}
+		return lines, 0
+	case "lasterrlog":
+		if len(e.errlog) == 0 {
+			return nil, 0
+		}
+		return []string{fmt.Sprintf("%08X", e.errlog[len(e.errlog)-1])}, 0
+	case "clearerrlog":
+		e.errlog = nil
+		return nil, 0
+	case "eeprominit":
+		e.resetEEPROM()
+		return nil, 0
+	}
+	return []string{fmt.Sprintf("Error: unknown command %q", name)}, EmuStatusUnknownCommand
+}
+
+// emuWordSize returns the access width in bytes of an r/w command.
+func emuWordSize(name string) int {
+	switch strings.TrimLeft(name, "rw") {
+	case "16":
+		return 2
+	case "32":
+		return 4
+	}
+	return 1
+}
+
+// readMemLocked answers "r offset [count]" with 16 bytes per line.
+func (e *Emulator) readMemLocked(name string, args []string) ([]string, uint32) {
+	if len(args) < 1 || len(args) > 2 {
+		return []string{"Error: usage " + name + " [offset] [length]"}, EmuStatusBadArguments
+	}
+	size := emuWordSize(name)
+	offset, err := strconv.ParseUint(args[0], 16, 16)
+	count := uint64(1)
+	if err == nil && len(args) == 2 {
+		count, err = strconv.ParseUint(args[1], 16, 16)
+	}
+	total := count * uint64(size)
+	if err != nil || count == 0 || int(offset+total) > EmuEEPROMSize {
+		return []string{"Error: bad address"}, EmuStatusBadArguments
+	}
+
+	var lines []string
+	for start := offset; start < offset+total; start += 16 {
+		end := start + 16
+		if end > offset+total {
+			end = offset + total
+		}
+		var words []string
+		for a := start; a < end; a += uint64(size) {
+			words = append(words, strings.ToUpper(hex.EncodeToString(e.eeprom[a:a+uint64(size)])))
+		}
+		lines = append(lines, fmt.Sprintf("%08X: %s", start, strings.Join(words, " ")))
+	}
+	return lines, 0
+}
+
+// writeMemLocked answers "w offset value..." writing consecutive words.
+func (e *Emulator) writeMemLocked(name string, args []string) ([]string, uint32) {
+	if len(args) < 2 {
+		return []string{"Error: usage " + name + " [offset] [value]"}, EmuStatusBadArguments
+	}
+	size := emuWordSize(name)
+	offset, err := strconv.ParseUint(args[0], 16, 16)
+	if err != nil || int(offset)+len(args[1:])*size > EmuEEPROMSize {
+		return []string{"Error: bad address"}, EmuStatusBadArguments
+	}
+
+	var data []byte
+	for _, v := range args[1:] {
+		b, err := hex.DecodeString(fmt.Sprintf("%0*s", size*2, v))
+		if err != nil || len(b) != size {
+			return []string{"Error: bad value " + v}, EmuStatusBadArguments
+		}
+		data = append(data, b...)
+	}
+	copy(e.eeprom[offset:], data)
+	return nil, 0
+}
+
+// eepcsumLocked reports each region's expected checksum, preceded by a
+// "sum:0x0100" line when the stored value is wrong.
+func (e *Emulator) eepcsumLocked() []string {
+	var lines []string
+	for _, start := range emuChecksumRegions {
+		want := emuRegionSum(e.eeprom[start : start+0xFE])
+		got := binary.LittleEndian.Uint16(e.eeprom[start+0xFE:])
+		if got != want {
+			lines = append(lines, "sum:0x0100")
+		}
+		lines = append(lines, fmt.Sprintf("Addr:0x%08x should be 0x%04x", start+0xFE, want))
+	}
+	return lines
+}
+
+// emuRegionSum is the 16-bit byte sum stored at the end of a region.
+func emuRegionSum(b []byte) uint16 {
+	var sum uint16
+	for _, v := range b {
+		sum += uint16(v)
+	}
+	return sum
+}
+
+// resetEEPROM fills the EEPROM with factory data and valid checksums.
+func (e *Emulator) resetEEPROM() {
+	e.eeprom = make([]byte, EmuEEPROMSize)
+	for i := range e.eeprom {
+		e.eeprom[i] = byte(i * 7)
+	}
+	e.eeprom[0x3961] = 0xFF
+	for _, start := range emuChecksumRegions {
+		binary.LittleEndian.PutUint16(e.eeprom[start+0xFE:], emuRegionSum(e.eeprom[start:start+0xFE]))
+	}
+}
+
+// auth1Locked answers an AUTH1 challenge with the encrypted syscon token.
+func (e *Emulator) auth1Locked(challenge string) (string, error) {
+	if _, err := hex.DecodeString(challenge); err != nil {
+		return "", err
+	}
+
+	plain := make([]byte, 0x30)
+	copy(plain[0x0:0x8], e.nonce)
+	copy(plain[0x10:0x20], authValue)
+	body, err := aesEncryptCBC(sc2tb, zeroIV, plain)
+	if err != nil {
+		return "", err
+	}
+
+	e.authenticated = false
+	return strings.ToUpper(hex.EncodeToString(append(append([]byte(nil), auth1rHdr...), body...))), nil
+}
+
+// auth2Locked checks the host's AUTH2 answer to the token.
+func (e *Emulator) auth2Locked(answer string) bool {
+	raw, err := hex.DecodeString(answer)
+	if err != nil || len(raw) != len(auth2Header)+0x30 || !bytes.Equal(raw[:len(auth2Header)], auth2Header) {
+		return false
+	}
+	plain, err := aesDecryptCBC(tb2sc, zeroIV, raw[len(auth2Header):])
+	if err != nil {
+		return false
+	}
+	e.authenticated = bytes.Equal(plain[0x8:0x10], e.nonce) && bytes.Equal(plain[0x0:0x8], zeroIV[:0x8])
+	return e.authenticated
+}
@@ -0,0 +1,203 @@
+package syscon
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// emulatorUART connects a PS3UART to a fresh emulator.
+func emulatorUART(t *testing.T, scType string, cfg EmulatorConfig) (*PS3UART, *Emulator) {
+	t.Helper()
+	emu := NewEmulator(cfg)
+	uart := NewPS3UARTWithPort(emu, scType, 57600)
+	if err := emu.SetReadTimeout(10 * time.Millisecond); err != nil {
+		t.Fatalf("SetReadTimeout: %v", err)
+	}
+	return uart, emu
+}
+
+func TestEmulatorCXRRequiresAuth(t *testing.T) {
+	uart, _ := emulatorUART(t, "CXR", EmulatorConfig{Type: "CXR"})
+
+	if result := mustCommand(t, uart, "VER", time.Second); result.Code != 0 || len(result.Data) != 1 {
+		t.Errorf("VER = %+v, want OK with version", result)
+	}
+	if result := mustCommand(t, uart, "EEP GET 3961 01", time.Second); result.Code != EmuStatusNotAuthorized {
+		t.Errorf("EEP GET before auth code = %08X, want %08X", result.Code, EmuStatusNotAuthorized)
+	}
+	if result := mustCommand(t, uart, "BOGUS", time.Second); result.Code != EmuStatusNotAuthorized {
+		t.Errorf("unknown command before auth code = %08X, want %08X", result.Code, EmuStatusNotAuthorized)
+	}
+}
+
+func TestEmulatorCXREEPROM(t *testing.T) {
+	uart, emu := emulatorUART(t, "CXR", EmulatorConfig{Type: "CXR"})
+
+	if err := uart.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+
+	result := mustCommand(t, uart, "EEP GET 3961 01", time.Second)
+	if result.Code != 0 || len(result.Data) != 1 || result.Data[0] != "FF" {
+		t.Errorf("EEP GET = %+v, want FF", result)
+	}
+
+	if result := mustCommand(t, uart, "EEP SET 3961 01 00", time.Second); result.Code != 0 {
+		t.Fatalf("EEP SET code = %08X", result.Code)
+	}
+	if got := emu.EEPROM()[0x3961]; got != 0x00 {
+		t.Errorf("EEPROM[0x3961] = %02X, want 00", got)
+	}
+
+	if result := mustCommand(t, uart, "BOGUS", time.Second); result.Code != EmuStatusUnknownCommand {
+		t.Errorf("unknown command code = %08X, want %08X", result.Code, EmuStatusUnknownCommand)
+	}
+}
+
+func TestEmulatorCXRErrlog(t *testing.T) {
+	uart, _ := emulatorUART(t, "CXR", EmulatorConfig{Type: "CXR"})
+	if err := uart.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+
+	result := mustCommand(t, uart, "ERRLOG GET 00", time.Second)
+	if result.Code != 0 || len(result.Data) != 2 || result.Data[0] != "A0022110" {
+		t.Errorf("ERRLOG GET 00 = %+v", result)
+	}
+}
+
+func TestEmulatorCXRF(t *testing.T) {
+	uart, emu := emulatorUART(t, "CXRF", EmulatorConfig{Type: "CXRF"})
+
+	if err := uart.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+
+	result := mustCommand(t, uart, "r 3961 1", time.Second)
+	if !strings.Contains(result.Data[0], "00003961: FF") {
+		t.Errorf("r 3961 = %q, want a dump line with FF", result.Data[0])
+	}
+
+	mustCommand(t, uart, "w 3961 00", time.Second)
+	if got := emu.EEPROM()[0x3961]; got != 0x00 {
+		t.Errorf("EEPROM[0x3961] = %02X, want 00", got)
+	}
+
+	result = mustCommand(t, uart, "eepcsum", time.Second)
+	if !strings.Contains(result.Data[0], "sum:0x0100") {
+		t.Errorf("eepcsum after write = %q, want a mismatch", result.Data[0])
+	}
+
+	result = mustCommand(t, uart, "errlog", time.Second)
+	if !strings.Contains(result.Data[0], "00: A0022110") {
+		t.Errorf("errlog = %q", result.Data[0])
+	}
+}
+
+func TestEmulatorEepcsumFactory(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{})
+	eeprom := emu.EEPROM()
+
+	for _, start := range emuChecksumRegions {
+		want := emuRegionSum(eeprom[start : start+0xFE])
+		if got := binary.LittleEndian.Uint16(eeprom[start+0xFE:]); got != want {
+			t.Errorf("region %04X checksum = %04X, want %04X", start, got, want)
+		}
+	}
+}
+
+func TestEmulatorSW(t *testing.T) {
+	uart, _ := emulatorUART(t, "SW", EmulatorConfig{Type: "SW"})
+
+	if err := uart.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+
+	result := mustCommand(t, uart, "r 3961 1", time.Second)
+	if result.Code != 0 || len(result.Data) != 1 || !strings.Contains(result.Data[0], "FF") {
+		t.Errorf("r 3961 = %+v", result)
+	}
+}
+
+func TestEmulatorSWRejectsLongWithoutSetcmdlong(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{Type: "SW"})
+	cmd := "r " + strings.Repeat("0", emuSWShortLimit)
+	emu.Write([]byte(cmd + ":" + checksum(cmd) + "\r\n"))
+
+	buf := make([]byte, 64)
+	n, _ := emu.Read(buf)
+	if !strings.HasPrefix(string(buf[:n]), "NG F0000004") {
+		t.Errorf("long command answer = %q, want NG F0000004", buf[:n])
+	}
+}
+
+func TestEmulatorFaults(t *testing.T) {
+	t.Run("corrupt", func(t *testing.T) {
+		uart, _ := emulatorUART(t, "CXR", EmulatorConfig{Type: "CXR", Faults: EmulatorFaults{CorruptEvery: 1}})
+		_, err := uart.Command("VER", 50*time.Millisecond)
+		var re *ResponseError
+		if !errors.Is(err, ErrChecksumMismatch) || !errors.As(err, &re) || re.Answer == "" {
+			t.Errorf("VER error = %v, want a checksum mismatch with the answer", err)
+		}
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		uart, _ := emulatorUART(t, "CXR", EmulatorConfig{Type: "CXR", Faults: EmulatorFaults{DropEvery: 2}})
+		if result := mustCommand(t, uart, "VER", 50*time.Millisecond); result.Code != 0 {
+			t.Errorf("first VER code = %08X, want 0", result.Code)
+		}
+		if _, err := uart.Command("VER", 50*time.Millisecond); !errors.Is(err, ErrNoResponse) {
+			t.Errorf("dropped VER error = %v, want %v", err, ErrNoResponse)
+		}
+	})
+
+	t.Run("chunked and delayed", func(t *testing.T) {
+		uart, _ := emulatorUART(t, "CXR", EmulatorConfig{
+			Type:   "CXR",
+			Faults: EmulatorFaults{ChunkSize: 3, Delay: 20 * time.Millisecond},
+		})
+		if result := mustCommand(t, uart, "VER", time.Second); result.Code != 0 {
+			t.Errorf("VER = %+v, want OK", result)
+		}
+	})
+
+	t.Run("wrong baud", func(t *testing.T) {
+		uart, emu := emulatorUART(t, "CXR", EmulatorConfig{Type: "CXR", BaudRate: 115200})
+		emu.SetBaudRate(57600)
+		if _, err := uart.Command("VER", 50*time.Millisecond); err == nil {
+			t.Error("VER at wrong baud succeeded")
+		}
+	})
+}
+
+func TestEmulatorClosed(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{})
+	emu.Close()
+
+	if _, err := emu.Write([]byte("VER\r\n")); err == nil {
+		t.Error("Write on closed emulator succeeded")
+	}
+	if _, err := emu.Read(make([]byte, 8)); err == nil {
+		t.Error("Read on closed emulator succeeded")
+	}
+}
+
+func TestDefaultOpenerDemoPort(t *testing.T) {
+	port, err := OpenPort(DemoPortName, 57600)
+	if err != nil {
+		t.Fatalf("OpenPort(%q): %v", DemoPortName, err)
+	}
+	defer port.Close()
+
+	if _, ok := port.(*Emulator); !ok {
+		t.Errorf("OpenPort(%q) = %T, want *Emulator", DemoPortName, port)
+	}
+
+	ports := ListPorts()
+	if len(ports) == 0 || ports[len(ports)-1] != DemoPortName {
+		t.Errorf("ListPorts() = %v, want %q last", ports, DemoPortName)
+	}
+}
@@ -0,0 +1,315 @@
+// Package syscon provides the error-code database and the error log
+// decoder.
+package syscon
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// errorCodesJSON is the built-in error-code database.
+//
+//go:embed errorcodes.json
+var errorCodesJSON []byte
+
+// ErrorInfo describes a known error code.
+type ErrorInfo struct {
+	Code        string   `json:"code"` // eight hex digits, such as "A0801200"
+	Description string   `json:"description"`
+	Hint        string   `json:"hint,omitempty"`    // what to check or repair
+	Related     []string `json:"related,omitempty"` // codes usually seen alongside
+}
+
+// errorDB holds the built-in error codes in file order.
+var errorDB []ErrorInfo
+
+func init() {
+	var file struct {
+		Version int         `json:"version"`
+		Errors  []ErrorInfo `json:"errors"`
+	}
+	if err := json.Unmarshal(errorCodesJSON, &file); err != nil {
+		panic("syscon: built-in error codes: " + err.Error())
+	}
+	for _, info := range file.Errors {
+		if _, err := ParseErrorCode(info.Code); err != nil {
+			panic("syscon: built-in error codes: " + err.Error())
+		}
+	}
+	errorDB = file.Errors
+	for _, info := range errorDB {
+		for _, code := range info.Related {
+			if _, ok := findErrorInfo(code); !ok {
+				panic("syscon: built-in error codes: " + info.Code + " relates to unknown code " + code)
+			}
+		}
+	}
+}
+
+// findErrorInfo returns the database entry for exactly code.
+func findErrorInfo(code string) (ErrorInfo, bool) {
+	for _, e := range errorDB {
+		if strings.EqualFold(e.Code, code) {
+			return e, true
+		}
+	}
+	return ErrorInfo{}, false
+}
+
+// ErrorCodes returns the built-in error codes in database order.
+func ErrorCodes() []ErrorInfo {
+	return append([]ErrorInfo(nil), errorDB...)
+}
+
+// SearchErrorCodes returns the known codes matching query. Every word of
+// the query must appear in the code, the description or the hint,
+// ignoring case, so "A04044", "VRAM" and "tokin RSX" all work. Codes
+// matched by their code or description come first, in database order,
+// then those whose hint only mentions the query. A full code that is only
+// known at another step finds that entry. An empty query matches every
+// code.
+func SearchErrorCodes(query string) []ErrorInfo {
+	words := strings.Fields(strings.ToLower(query))
+	var found, mentions []ErrorInfo
+	for _, e := range errorDB {
+		head := strings.ToLower(e.Code + " " + e.Description)
+		hint := strings.ToLower(e.Hint)
+		inHint := false
+		match := true
+		for _, w := range words {
+			switch {
+			case strings.Contains(head, w):
+			case strings.Contains(hint, w):
+				inHint = true
+			default:
+				match = false
+			}
+		}
+		switch {
+		case !match:
+		case inHint:
+			mentions = append(mentions, e)
+		default:
+			found = append(found, e)
+		}
+	}
+	found = append(found, mentions...)
+	if len(found) == 0 && len(words) == 1 {
+		if code, err := ParseErrorCode(words[0]); err == nil {
+			if info, _, ok := code.Lookup(); ok {
+				found = append(found, info)
+			}
+		}
+	}
+	return found
+}
+
+// ErrorCategory is the kind of error, the fifth hex digit of a code.
+type ErrorCategory uint8
+
+// Error categories listed in the README.
+const (
+	CategorySystem    ErrorCategory = 1
+	CategoryFatal     ErrorCategory = 2
+	CategoryFatalBoot ErrorCategory = 3
+	CategoryDataError ErrorCategory = 4
+)
+
+// String returns the category name.
+func (c ErrorCategory) String() string {
+	switch c {
+	case CategorySystem:
+		return "system error"
+	case CategoryFatal:
+		return "fatal error"
+	case CategoryFatalBoot:
+		return "fatal booting error"
+	case CategoryDataError:
+		return "data error"
+	default:
+		return fmt.Sprintf("category %X", uint8(c))
+	}
+}
+
+// Power states in the step field of an error code; lower values are steps
+// of the power-on sequence.
+const (
+	StatePowerOn      = 0x80
+	StatePowerOff     = 0x90
+	StateAfterSCReset = 0xA0
+)
+
+// ErrorCode is a syscon error code such as A0801200, split into its
+// fields: the fixed A0, the power-on step or state, the category and the
+// detail.
+type ErrorCode struct {
+	Raw      uint32
+	Step     uint8 // 0x00-0x7F power-on step, or StatePowerOn, StatePowerOff or StateAfterSCReset
+	Category ErrorCategory
+	Detail   uint16 // last three hex digits
+}
+
+// ParseErrorCode parses eight hex digits starting with the fixed A0.
+func ParseErrorCode(s string) (ErrorCode, error) {
+	if !isHex(s, 8) || !strings.EqualFold(s[:2], "A0") {
+		return ErrorCode{}, fmt.Errorf("invalid error code %q", s)
+	}
+	raw, _ := strconv.ParseUint(s, 16, 32)
+	return ErrorCode{
+		Raw:      uint32(raw),
+		Step:     uint8(raw >> 16),
+		Category: ErrorCategory(raw >> 12 & 0xF),
+		Detail:   uint16(raw & 0xFFF),
+	}, nil
+}
+
+func (c ErrorCode) String() string {
+	return fmt.Sprintf("%08X", c.Raw)
+}
+
+// Phase describes when the error was recorded.
+func (c ErrorCode) Phase() string {
+	switch {
+	case c.Step < StatePowerOn:
+		return fmt.Sprintf("power-on step %02X", c.Step)
+	case c.Step == StatePowerOn:
+		return "power on"
+	case c.Step == StatePowerOff:
+		return "power off"
+	case c.Step == StateAfterSCReset:
+		return "power on after syscon reset"
+	default:
+		return fmt.Sprintf("state %02X", c.Step)
+	}
+}
+
+// Lookup returns what the database knows about c. exact is false when only
+// the same category and detail are known, recorded at another step.
+func (c ErrorCode) Lookup() (info ErrorInfo, exact, ok bool) {
+	code := c.String()
+	if e, ok := findErrorInfo(code); ok {
+		return e, true, true
+	}
+	for _, e := range errorDB {
+		if strings.EqualFold(e.Code[4:], code[4:]) {
+			return e, false, true
+		}
+	}
+	return ErrorInfo{}, false, false
+}
+
+// ErrorLogEntry is one decoded error log line.
+type ErrorLogEntry struct {
+	Index   int // slot in the log, or -1 when the output does not say
+	Code    ErrorCode
+	Time    uint32 // value recorded with the code, as printed
+	HasTime bool
+	Info    ErrorInfo // description and hint; empty when unknown
+	Known   bool
+	Exact   bool // Info is for this exact code, not the same error at another step
+}
+
+// IsErrorLogCommand reports whether cmdLine reads the error log in the
+// given mode: ERRLOG GET for CXR, errlog, geterrlog and lasterrlog for the
+// internal commands.
+func IsErrorLogCommand(scType, cmdLine string) bool {
+	fields := strings.Fields(cmdLine)
+	if len(fields) == 0 {
+		return false
+	}
+	if scType == "CXR" {
+		return len(fields) >= 2 && strings.EqualFold(fields[0], "ERRLOG") && strings.EqualFold(fields[1], "GET")
+	}
+	switch fields[0] {
+	case "errlog", "geterrlog", "lasterrlog":
+		return true
+	}
+	return false
+}
+
+var (
+	hexWord    = regexp.MustCompile(`\b[0-9A-Fa-f]{8}\b`)
+	slotPrefix = regexp.MustCompile(`^\s*([0-9A-Fa-f]{1,2})\s*:`)
+)
+
+// ParseErrorLog decodes the text printed by errlog, geterrlog or
+// lasterrlog. Each line holding an error code gives one entry; the word
+// after the code is its time and a leading "NN:" its slot. Lines without
+// a code, such as empty FFFFFFFF slots, are skipped.
+func ParseErrorLog(text string) []ErrorLogEntry {
+	var entries []ErrorLogEntry
+	for _, line := range strings.Split(text, "\n") {
+		words := hexWord.FindAllString(line, -1)
+		for i, w := range words {
+			code, err := ParseErrorCode(w)
+			if err != nil {
+				continue
+			}
+			entry := newErrorLogEntry(code)
+			if m := slotPrefix.FindStringSubmatch(line); m != nil {
+				idx, _ := strconv.ParseUint(m[1], 16, 8)
+				entry.Index = int(idx)
+			}
+			if i+1 < len(words) {
+				t, _ := strconv.ParseUint(words[i+1], 16, 32)
+				entry.Time, entry.HasTime = uint32(t), true
+			}
+			entries = append(entries, entry)
+			break
+		}
+	}
+	return entries
+}
+
+// resultText returns the text printed by an internal command.
+// Multi-line output keeps its line ends; a one-line SW answer is split
+// into words.
+func resultText(result CommandResult) string {
+	sep := " "
+	if len(result.Data) > 0 && strings.Contains(result.Data[0], "\n") {
+		sep = ""
+	}
+	return strings.Join(result.Data, sep)
+}
+
+// newErrorLogEntry returns an entry for code annotated from the database.
+func newErrorLogEntry(code ErrorCode) ErrorLogEntry {
+	entry := ErrorLogEntry{Index: -1, Code: code}
+	entry.Info, entry.Exact, entry.Known = code.Lookup()
+	return entry
+}
+
+// DecodeErrorLog decodes the answer to an error log command in the given
+// mode; ok is false for other commands. CXR ERRLOG GET answers with the
+// code and time of the slot named in the command.
+func DecodeErrorLog(scType, cmdLine string, result CommandResult) (entries []ErrorLogEntry, ok bool) {
+	if !IsErrorLogCommand(scType, cmdLine) {
+		return nil, false
+	}
+	if scType != "CXR" {
+		return ParseErrorLog(resultText(result)), true
+	}
+
+	if result.Code != 0 || len(result.Data) == 0 {
+		return nil, true
+	}
+	code, err := ParseErrorCode(result.Data[0])
+	if err != nil {
+		return nil, true
+	}
+	entry := newErrorLogEntry(code)
+	if fields := strings.Fields(cmdLine); len(fields) >= 3 {
+		if idx, err := strconv.ParseUint(fields[2], 16, 8); err == nil {
+			entry.Index = int(idx)
+		}
+	}
+	if len(result.Data) >= 2 && isHex(result.Data[1], 8) {
+		t, _ := strconv.ParseUint(result.Data[1], 16, 32)
+		entry.Time, entry.HasTime = uint32(t), true
+	}
+	return []ErrorLogEntry{entry}, true
+}
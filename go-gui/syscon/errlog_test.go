@@ -0,0 +1,200 @@
+package syscon
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go.bug.st/serial"
+)
+
+func TestParseErrorCode(t *testing.T) {
+	code, err := ParseErrorCode("A0801200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Step != StatePowerOn || code.Category != CategorySystem || code.Detail != 0x200 {
+		t.Errorf("A0801200 = %+v", code)
+	}
+	if code.Phase() != "power on" || code.Category.String() != "system error" {
+		t.Errorf("phase %q, category %q", code.Phase(), code.Category)
+	}
+
+	code, _ = ParseErrorCode("a0022110")
+	if code.String() != "A0022110" || code.Phase() != "power-on step 02" || code.Category != CategoryFatal {
+		t.Errorf("A0022110 = %s, %s, %s", code, code.Phase(), code.Category)
+	}
+
+	for _, s := range []string{"", "A080120", "FFFFFFFF", "B0801200", "A08012G0"} {
+		if _, err := ParseErrorCode(s); err == nil {
+			t.Errorf("ParseErrorCode(%q) succeeded", s)
+		}
+	}
+}
+
+func TestErrorCodeLookup(t *testing.T) {
+	tests := []struct {
+		code  string
+		exact bool
+		ok    bool
+		desc  string
+	}{
+		{"A0801200", true, true, "CELL overheating"},
+		{"A0A01200", false, true, "CELL overheating"},
+		{"A0409999", false, false, ""},
+	}
+	for _, tt := range tests {
+		code, _ := ParseErrorCode(tt.code)
+		info, exact, ok := code.Lookup()
+		if exact != tt.exact || ok != tt.ok || info.Description != tt.desc {
+			t.Errorf("Lookup(%s) = %+v, %v, %v", tt.code, info, exact, ok)
+		}
+	}
+}
+
+func TestBuiltinErrorCodes(t *testing.T) {
+	seen := map[string]bool{}
+	for _, info := range errorDB {
+		if info.Description == "" {
+			t.Errorf("%s has no description", info.Code)
+		}
+		if seen[info.Code] {
+			t.Errorf("%s listed twice", info.Code)
+		}
+		seen[info.Code] = true
+	}
+}
+
+func TestErrorCodeDescriptionsMatchPhase(t *testing.T) {
+	phases := map[string]string{
+		" in power-off state": "power off",
+		" in power-on state":  "power on",
+		"at power-on step ":   "power-on step",
+	}
+	for _, info := range errorDB {
+		code, err := ParseErrorCode(info.Code)
+		if err != nil {
+			t.Errorf("%s: %v", info.Code, err)
+			continue
+		}
+		for says, phase := range phases {
+			if strings.Contains(info.Description, says) && !strings.HasPrefix(code.Phase(), phase) {
+				t.Errorf("%s says %q but was recorded at %s", info.Code, strings.TrimSpace(says), code.Phase())
+			}
+		}
+	}
+
+	code, _ := ParseErrorCode("A0093003")
+	if info, _, _ := code.Lookup(); code.Phase() != "power-on step 09" || !strings.Contains(info.Description, "power-on step 09") {
+		t.Errorf("A0093003: phase %q, description %q", code.Phase(), info.Description)
+	}
+}
+
+func TestErrorCodeRelated(t *testing.T) {
+	// Cross-references go both ways
+	for _, info := range ErrorCodes() {
+		for _, code := range info.Related {
+			other, _ := findErrorInfo(code)
+			if !slices.Contains(other.Related, info.Code) {
+				t.Errorf("%s relates to %s but not the other way round", info.Code, code)
+			}
+		}
+	}
+	info, _ := findErrorInfo("A0403034")
+	for _, code := range []string{"A0404401", "A0404402"} {
+		if !slices.Contains(info.Related, code) {
+			t.Errorf("A0403034 is not related to %s", code)
+		}
+	}
+}
+
+func TestSearchErrorCodes(t *testing.T) {
+	codes := func(infos []ErrorInfo) []string {
+		var s []string
+		for _, info := range infos {
+			s = append(s, info.Code)
+		}
+		return s
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"A0801200", []string{"A0801200"}},
+		{"a04044", []string{"A0404401", "A0404402", "A0404411", "A0313032", "A0403034"}},
+		{"vram rsx", []string{"A0201B02", "A0401002", "A0801002"}},
+		{"tokin power-off", []string{"A0093003", "A0093004"}},
+		{"A0A01200", []string{"A0801200"}},
+		{"no such thing", nil},
+	}
+	for _, tt := range tests {
+		if got := codes(SearchErrorCodes(tt.query)); !slices.Equal(got, tt.want) {
+			t.Errorf("SearchErrorCodes(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+	if got := len(SearchErrorCodes(" ")); got != len(ErrorCodes()) {
+		t.Errorf("empty query found %d codes, want all %d", got, len(ErrorCodes()))
+	}
+}
+
+func TestParseErrorLog(t *testing.T) {
+	text := "errlog\r\n00: A0022110 00001F40\r\n01: FFFFFFFF FFFFFFFF\r\n02: A0403034 00005DC0\r\n"
+	entries := ParseErrorLog(text)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	e := entries[1]
+	if e.Index != 2 || e.Code.String() != "A0403034" || !e.HasTime || e.Time != 0x5DC0 || !e.Known || !e.Exact {
+		t.Errorf("entry = %+v", e)
+	}
+
+	entries = ParseErrorLog("A0801200")
+	if len(entries) != 1 || entries[0].Index != -1 || entries[0].HasTime {
+		t.Errorf("lasterrlog entries = %+v", entries)
+	}
+}
+
+func TestDecodeErrorLog(t *testing.T) {
+	entries, ok := DecodeErrorLog("CXR", "ERRLOG GET 01", CommandResult{Data: []string{"A0801200", "00003E80"}})
+	if !ok || len(entries) != 1 {
+		t.Fatalf("ERRLOG GET = %+v, %v", entries, ok)
+	}
+	if e := entries[0]; e.Index != 1 || e.Time != 0x3E80 || e.Info.Description != "CELL overheating" {
+		t.Errorf("entry = %+v", e)
+	}
+
+	if _, ok := DecodeErrorLog("CXR", "ERRLOG CLEAR", CommandResult{}); ok {
+		t.Error("ERRLOG CLEAR decoded as an error log")
+	}
+	if entries, ok := DecodeErrorLog("CXR", "ERRLOG GET 05", CommandResult{Code: 0x14}); !ok || len(entries) != 0 {
+		t.Errorf("failed ERRLOG GET = %+v, %v", entries, ok)
+	}
+
+	sw := CommandResult{Data: []string{"00: A0022110 00001F40\n", "01: A0801200 00003E80\n"}}
+	if entries, ok := DecodeErrorLog("SW", "errlog", sw); !ok || len(entries) != 2 || entries[1].Index != 1 {
+		t.Errorf("SW errlog = %+v, %v", entries, ok)
+	}
+	oneLine := CommandResult{Data: []string{"A0801200", "00003E80"}}
+	if entries, _ := DecodeErrorLog("SW", "lasterrlog", oneLine); len(entries) != 1 || entries[0].Time != 0x3E80 {
+		t.Errorf("SW lasterrlog = %+v", entries)
+	}
+}
+
+func TestDecodeEmulatorErrorLog(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{Type: "CXRF"})
+	s := NewSession(func(string, *serial.Mode) (SerialPort, error) { return emu, nil })
+	if err := s.Connect("emu", "CXRF", 115200); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := s.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+	result, err := s.Command("errlog", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, ok := DecodeErrorLog("CXRF", "errlog", result)
+	if !ok || len(entries) != 3 || entries[0].Code.String() != "A0022110" {
+		t.Errorf("errlog = %+v", entries)
+	}
+}
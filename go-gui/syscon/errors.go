@@ -0,0 +1,92 @@
+// Package syscon provides sentinel errors for the syscon protocol.
+package syscon
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors for common error conditions.
+var (
+	// ErrCommandFailed indicates a command execution failed.
+	ErrCommandFailed = errors.New("command failed")
+
+	// ErrAuthFailed indicates authentication failed.
+	ErrAuthFailed = errors.New("authentication failed")
+
+	// ErrInvalidResponse indicates an invalid response from the device.
+	ErrInvalidResponse = errors.New("invalid response")
+
+	// ErrChecksumMismatch indicates a checksum verification failed.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrDecryptionFailed indicates AES decryption failed.
+	ErrDecryptionFailed = errors.New("decryption failed")
+
+	// ErrEncryptionFailed indicates AES encryption failed.
+	ErrEncryptionFailed = errors.New("encryption failed")
+
+	// ErrInvalidAuthResponse indicates an invalid authentication response.
+	ErrInvalidAuthResponse = errors.New("invalid auth response")
+
+	// ErrSerialOpenFailed indicates failure to open serial port.
+	ErrSerialOpenFailed = errors.New("failed to open serial port")
+
+	// ErrNotConnected indicates a session command was issued without an open port.
+	ErrNotConnected = errors.New("not connected")
+
+	// ErrConnectionLost indicates the serial port failed during a command.
+	ErrConnectionLost = errors.New("connection lost")
+
+	// ErrPortBusy indicates a shared port is already open with other settings.
+	ErrPortBusy = errors.New("port busy")
+
+	// ErrNoResponse indicates the device sent nothing before the timeout.
+	ErrNoResponse = errors.New("no response")
+
+	// ErrInvalidArgument indicates a command argument that does not match
+	// its catalog definition.
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrNotPermitted indicates a command that its permission mask does not
+	// allow in the current authentication state.
+	ErrNotPermitted = errors.New("not permitted")
+
+	// ErrReadOnly indicates a command that is not safe, refused because
+	// the session is in read-only mode.
+	ErrReadOnly = errors.New("read-only mode")
+
+	// ErrUnexpectedAnswer indicates a well-formed answer that differs from
+	// the one a guided step expects.
+	ErrUnexpectedAnswer = errors.New("unexpected answer")
+)
+
+// ResponseError describes an answer that could not be parsed. Err is one
+// of ErrNoResponse, ErrInvalidResponse or ErrChecksumMismatch, so callers
+// can match it with errors.Is. A status code reported by the syscon is not
+// a ResponseError; it is returned in CommandResult.Code.
+type ResponseError struct {
+	Err    error  // sentinel describing the fault
+	Reason string // what was wrong with the answer
+	Want   string // expected checksum, for ErrChecksumMismatch
+	Got    string // received checksum, for ErrChecksumMismatch
+	Answer string // raw answer as received
+}
+
+func (e *ResponseError) Error() string {
+	msg := e.Err.Error()
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.Want != "" || e.Got != "" {
+		msg += fmt.Sprintf(" (want %s, got %s)", e.Want, e.Got)
+	}
+	if e.Answer != "" {
+		msg += fmt.Sprintf(" in %q", e.Answer)
+	}
+	return msg
+}
+
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
@@ -1,4 +1,4 @@
-package main
+package syscon
 
 import (
 	"errors"
@@ -11,9 +11,6 @@ func TestSentinelErrors(t *testing.T) {
 		err  error
 		msg  string
 	}{
-		{"ErrPortNotSelected", ErrPortNotSelected, "serial port not selected"},
-		{"ErrModeNotSelected", ErrModeNotSelected, "mode not selected"},
-		{"ErrCommandEmpty", ErrCommandEmpty, "command is empty"},
 		{"ErrCommandFailed", ErrCommandFailed, "command failed"},
 		{"ErrAuthFailed", ErrAuthFailed, "authentication failed"},
 		{"ErrInvalidResponse", ErrInvalidResponse, "invalid response"},
@@ -22,6 +19,14 @@ func TestSentinelErrors(t *testing.T) {
 		{"ErrEncryptionFailed", ErrEncryptionFailed, "encryption failed"},
 		{"ErrInvalidAuthResponse", ErrInvalidAuthResponse, "invalid auth response"},
 		{"ErrSerialOpenFailed", ErrSerialOpenFailed, "failed to open serial port"},
+		{"ErrNotConnected", ErrNotConnected, "not connected"},
+		{"ErrConnectionLost", ErrConnectionLost, "connection lost"},
+		{"ErrPortBusy", ErrPortBusy, "port busy"},
+		{"ErrNoResponse", ErrNoResponse, "no response"},
+		{"ErrInvalidArgument", ErrInvalidArgument, "invalid argument"},
+		{"ErrNotPermitted", ErrNotPermitted, "not permitted"},
+		{"ErrReadOnly", ErrReadOnly, "read-only mode"},
+		{"ErrUnexpectedAnswer", ErrUnexpectedAnswer, "unexpected answer"},
 	}
 
 	for _, tt := range tests {
@@ -39,9 +44,6 @@ func TestSentinelErrors(t *testing.T) {
 
 func TestErrorsAreDistinct(t *testing.T) {
 	allErrors := []error{
-		ErrPortNotSelected,
-		ErrModeNotSelected,
-		ErrCommandEmpty,
 		ErrCommandFailed,
 		ErrAuthFailed,
 		ErrInvalidResponse,
@@ -50,6 +52,14 @@ func TestErrorsAreDistinct(t *testing.T) {
 		ErrEncryptionFailed,
 		ErrInvalidAuthResponse,
 		ErrSerialOpenFailed,
+		ErrNotConnected,
+		ErrConnectionLost,
+		ErrPortBusy,
+		ErrNoResponse,
+		ErrInvalidArgument,
+		ErrNotPermitted,
+		ErrReadOnly,
+		ErrUnexpectedAnswer,
 	}
 
 	for i, err1 := range allErrors {
@@ -62,8 +72,34 @@ func TestErrorsAreDistinct(t *testing.T) {
 }
 
 func TestErrorsCanBeWrapped(t *testing.T) {
-	wrapped := errors.New("wrapper: " + ErrPortNotSelected.Error())
-	if wrapped.Error() != "wrapper: serial port not selected" {
+	wrapped := errors.New("wrapper: " + ErrCommandFailed.Error())
+	if wrapped.Error() != "wrapper: command failed" {
 		t.Errorf("Wrapped error message incorrect: %s", wrapped.Error())
 	}
 }
+
+func TestResponseError(t *testing.T) {
+	err := error(&ResponseError{
+		Err:    ErrChecksumMismatch,
+		Reason: "answer checksum",
+		Want:   "3A",
+		Got:    "3B",
+		Answer: "R:3B:OK 00000000",
+	})
+
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Error("ResponseError does not unwrap to its sentinel")
+	}
+	if errors.Is(err, ErrInvalidResponse) {
+		t.Error("ResponseError matches an unrelated sentinel")
+	}
+	want := `checksum mismatch: answer checksum (want 3A, got 3B) in "R:3B:OK 00000000"`
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	var re *ResponseError
+	if !errors.As(err, &re) || re.Answer != "R:3B:OK 00000000" {
+		t.Errorf("errors.As = %+v", re)
+	}
+}
@@ -0,0 +1,51 @@
+package syscon_test
+
+import (
+	"fmt"
+	"log"
+
+	"ps3syscon-gui/syscon"
+)
+
+// Open a port, authenticate and read the syscon version in CXR mode.
+func ExamplePS3UART() {
+	uart, err := syscon.NewPS3UART("/dev/ttyUSB0", "CXR", 57600)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer uart.Close()
+
+	if err := uart.Auth(); err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := uart.Command("VER", 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%08X %v\n", result.Code, result.Data)
+}
+
+// Keep one connection open for several commands.
+func ExampleSession() {
+	session := syscon.NewSession(syscon.DefaultSerialPortOpener)
+	if err := session.Connect("/dev/ttyUSB0", "CXRF", 115200); err != nil {
+		log.Fatal(err)
+	}
+	defer session.Disconnect()
+
+	for _, cmd := range []string{"version", "errlog"} {
+		result, err := session.Command(cmd, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(result.Data)
+	}
+}
+
+// Look up a command in the catalog before sending it.
+func ExampleGetCommand() {
+	cmd := syscon.GetCommand("eep")
+	fmt.Println(cmd.Name, cmd.Subcommands)
+	// Output: EEP [GET SET INIT]
+}
@@ -0,0 +1,147 @@
+// Package syscon provides response framing for the syscon protocols.
+package syscon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Command deadlines used when the caller does not pass one.
+const (
+	// DefaultCommandTimeout bounds a command that has no Timeout in the catalog.
+	DefaultCommandTimeout = 2 * time.Second
+
+	// SlowCommandTimeout bounds commands that print long reports.
+	SlowCommandTimeout = 15 * time.Second
+)
+
+// SW lines of swLongLimit characters or more are refused unless
+// swLongCommand was sent first.
+const (
+	swLongLimit   = 0x40
+	swLongCommand = "SETCMDLONG FF FF"
+)
+
+// CXRFPrompt is the prompt the internal shell is believed to print once
+// a command has finished. It is unverified: no hardware capture shows it
+// (testdata/cxrf_auth.jsonl was recorded from the emulator), so it only
+// ends an answer early when it does appear. Override it for firmware that
+// prints a different prompt.
+var CXRFPrompt = "$ "
+
+// CXRFIdleGap ends a CXRF answer that has started when nothing more
+// arrives for this long. It is how a CXRF answer normally ends until
+// CXRFPrompt is confirmed on hardware.
+var CXRFIdleGap = 500 * time.Millisecond
+
+// checksum returns the two-digit hex sum of s used by CXR and SW framing.
+func checksum(s string) string {
+	sum := 0
+	for _, c := range s {
+		sum += int(c)
+	}
+	return fmt.Sprintf("%02X", sum%0x100)
+}
+
+// FrameCommand returns the lines sent for cmd in the given mode, exactly as
+// they go out on the wire. A long SW line is preceded by the SETCMDLONG line
+// the transport sends first.
+func FrameCommand(scType, cmd string) []string {
+	switch scType {
+	case "CXR":
+		return []string{fmt.Sprintf("C:%s:%s\r\n", checksum(cmd), cmd)}
+	case "SW":
+		line := fmt.Sprintf("%s:%s\r\n", cmd, checksum(cmd))
+		if len(cmd) >= swLongLimit {
+			return append(FrameCommand("SW", swLongCommand), line)
+		}
+		return []string{line}
+	default:
+		return []string{cmd + "\r\n"}
+	}
+}
+
+// completeLines returns the newline-terminated lines of buf without their
+// line endings, skipping blank lines. A trailing partial line is ignored.
+func completeLines(buf string) []string {
+	end := strings.LastIndex(buf, "\n")
+	if end < 0 {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(buf[:end], "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// cxrLine reports whether line is a whole "R:xx:..." or "E:xx:..." answer
+// whose checksum matches.
+func cxrLine(line string) bool {
+	parts := strings.SplitN(line, ":", 3)
+	if len(parts) != 3 || (parts[0] != "R" && parts[0] != "E") {
+		return false
+	}
+	return parts[1] == checksum(parts[2])
+}
+
+// cxrComplete reports whether buf holds a full CXR answer.
+func cxrComplete(buf string) bool {
+	lines := completeLines(buf)
+	return len(lines) > 0 && cxrLine(lines[len(lines)-1])
+}
+
+// swComplete reports whether buf ends with the SW status line, a
+// checksummed line whose second field is an 8-digit hex code.
+func swComplete(buf string) bool {
+	lines := completeLines(buf)
+	if len(lines) == 0 {
+		return false
+	}
+	last := lines[len(lines)-1]
+	i := strings.LastIndex(last, ":")
+	if i < 0 || last[i+1:] != checksum(last[:i]) {
+		return false
+	}
+	fields := strings.Fields(last[:i])
+	return len(fields) >= 2 && isHex(fields[1], 8)
+}
+
+// cxrfComplete reports whether the shell prompt follows the output in buf.
+func cxrfComplete(buf string) bool {
+	prompt := strings.TrimSpace(CXRFPrompt)
+	return prompt != "" && strings.HasSuffix(strings.TrimRight(buf, " \t"), prompt)
+}
+
+// cxrfStarted returns whether buf holds shell output beyond the echo of
+// cmd, after which an idle gap ends the answer.
+func cxrfStarted(cmd string) func(buf string) bool {
+	return func(buf string) bool {
+		out := strings.TrimLeft(buf, "\r\n")
+		out = strings.TrimPrefix(out, cmd)
+		return strings.TrimSpace(out) != ""
+	}
+}
+
+// trimPrompt removes the trailing shell prompt from a CXRF answer.
+func trimPrompt(answer string) string {
+	prompt := strings.TrimSpace(CXRFPrompt)
+	return strings.TrimSuffix(strings.TrimRight(answer, " \t"), prompt)
+}
+
+// isHex reports whether s is exactly n hex digits.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package syscon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChecksum(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"OK 00000000", "3A"},
+		{"OK", "9A"},
+		{"", "00"},
+	}
+
+	for _, tt := range tests {
+		if got := checksum(tt.input); got != tt.want {
+			t.Errorf("checksum(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCXRComplete(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  string
+		want bool
+	}{
+		{"full answer", "R:3A:OK 00000000\r\n", true},
+		{"error answer", "E:" + checksum("ERR 00000001") + ":ERR 00000001\n", true},
+		{"no line ending yet", "R:3A:OK 00000000", false},
+		{"partial", "R:3A:OK 000", false},
+		{"bad checksum", "R:00:OK 00000000\r\n", false},
+		{"bad magic", "X:3A:OK 00000000\r\n", false},
+		{"noise then answer", "\x00garbage\r\nR:3A:OK 00000000\r\n", true},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cxrComplete(tt.buf); got != tt.want {
+				t.Errorf("cxrComplete(%q) = %v, want %v", tt.buf, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSWComplete(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  string
+		want bool
+	}{
+		{"status line", "OK 00000000:3A\n", true},
+		{"data then status", "DATA LINE 1:" + checksum("DATA LINE 1") + "\r\nOK 00000000:3A\r\n", true},
+		{"data line only", "DATA LINE 1:" + checksum("DATA LINE 1") + "\n", false},
+		{"status without newline", "OK 00000000:3A", false},
+		{"bad checksum", "OK 00000000:00\n", false},
+		{"short status", "OK:9A\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := swComplete(tt.buf); got != tt.want {
+				t.Errorf("swComplete(%q) = %v, want %v", tt.buf, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCXRFComplete(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  string
+		want bool
+	}{
+		{"prompt", "version\r\n0C.3.D\r\n$ ", true},
+		{"prompt without space", "version\r\n0C.3.D\r\n$", true},
+		{"still printing", "errlog\r\n0001 A0022110\r\n", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cxrfComplete(tt.buf); got != tt.want {
+				t.Errorf("cxrfComplete(%q) = %v, want %v", tt.buf, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCXRFStarted(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  string
+		want bool
+	}{
+		{"nothing", "", false},
+		{"echo only", "version\r\n", false},
+		{"partial echo", "vers", true},
+		{"output", "version\r\n0C3D\r\n", true},
+		{"no echo", "0C3D\r\n", true},
+	}
+
+	started := cxrfStarted("version")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := started(tt.buf); got != tt.want {
+				t.Errorf("cxrfStarted(%q) = %v, want %v", tt.buf, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimPrompt(t *testing.T) {
+	if got := trimPrompt("version\r\n0C.3.D\r\n$ "); got != "version\r\n0C.3.D\r\n" {
+		t.Errorf("trimPrompt = %q", got)
+	}
+	if got := trimPrompt("no prompt"); got != "no prompt" {
+		t.Errorf("trimPrompt = %q", got)
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	tests := []struct {
+		scType string
+		cmd    string
+		want   time.Duration
+	}{
+		{"CXR", "VER", DefaultCommandTimeout},
+		{"CXR", "ERRLOG GET 00", SlowCommandTimeout},
+		{"CXRF", "eepcsum", SlowCommandTimeout},
+		{"CXRF", "version", DefaultCommandTimeout},
+		{"SW", "errlog", SlowCommandTimeout},
+		{"CXRF", "unknown", DefaultCommandTimeout},
+		{"CXR", "", DefaultCommandTimeout},
+	}
+
+	for _, tt := range tests {
+		if got := CommandTimeout(tt.scType, tt.cmd); got != tt.want {
+			t.Errorf("CommandTimeout(%q, %q) = %v, want %v", tt.scType, tt.cmd, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package syscon provides DIAG and power control through adapter lines.
+package syscon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoModemControl is returned when a port cannot drive DTR or RTS.
+var ErrNoModemControl = errors.New("port has no modem control lines")
+
+// ErrLineNotMapped is returned when DIAG or power is not wired to a line.
+var ErrLineNotMapped = errors.New("control line not mapped")
+
+// ModemControl is implemented by ports that can drive the adapter's DTR
+// and RTS outputs, such as real serial ports.
+type ModemControl interface {
+	SetDTR(bool) error
+	SetRTS(bool) error
+}
+
+// Adapter control lines a HardwareControl can use.
+const (
+	LineNone = ""
+	LineDTR  = "dtr"
+	LineRTS  = "rts"
+)
+
+// ControlLines lists the values for HardwareControl.DIAG and Power.
+var ControlLines = []string{LineNone, LineDTR, LineRTS}
+
+// Default timings for HardwareControl.
+const (
+	DefaultPowerOffTime = 2 * time.Second
+	DefaultSettleTime   = time.Second
+)
+
+// HardwareControl maps adapter control lines to the syscon's DIAG pin and
+// to a relay in the console's power feed. With a line mapped, raising it
+// grounds DIAG or switches the power on; Invert flips that for wiring
+// where the line is active low.
+type HardwareControl struct {
+	DIAG         string        `json:"diag,omitempty"` // LineDTR, LineRTS or LineNone
+	DIAGInvert   bool          `json:"diag_invert,omitempty"`
+	Power        string        `json:"power,omitempty"` // LineDTR, LineRTS or LineNone
+	PowerInvert  bool          `json:"power_invert,omitempty"`
+	PowerOffTime time.Duration `json:"power_off_time,omitempty"` // zero means DefaultPowerOffTime
+	SettleTime   time.Duration `json:"settle_time,omitempty"`    // zero means DefaultSettleTime
+}
+
+// Enabled reports whether any line is mapped.
+func (h HardwareControl) Enabled() bool {
+	return h.DIAG != LineNone || h.Power != LineNone
+}
+
+// Validate reports an unknown line name or both functions on one line.
+func (h HardwareControl) Validate() error {
+	for _, line := range []string{h.DIAG, h.Power} {
+		if line != LineNone && line != LineDTR && line != LineRTS {
+			return fmt.Errorf("invalid control line %q", line)
+		}
+	}
+	if h.DIAG != LineNone && h.DIAG == h.Power {
+		return fmt.Errorf("DIAG and power both mapped to %s", h.DIAG)
+	}
+	if h.PowerOffTime < 0 || h.SettleTime < 0 {
+		return errors.New("invalid power cycle timing")
+	}
+	return nil
+}
+
+// Levels returns the DTR and RTS levels that put DIAG and power in the
+// given states, starting from the current dtr and rts. A line that is not
+// mapped keeps its level, so a profile that holds RTS low, for an adapter
+// wired to reset, is not overridden.
+func (h HardwareControl) Levels(dtr, rts, diagGrounded, powerOn bool) (bool, bool) {
+	set := func(line string, level bool) {
+		switch line {
+		case LineDTR:
+			dtr = level
+		case LineRTS:
+			rts = level
+		}
+	}
+	set(h.DIAG, diagGrounded != h.DIAGInvert)
+	set(h.Power, powerOn != h.PowerInvert)
+	return dtr, rts
+}
+
+// setLine drives one control line of port.
+func setLine(port SerialPort, line string, level bool) error {
+	mc, ok := port.(ModemControl)
+	if !ok {
+		return ErrNoModemControl
+	}
+	switch line {
+	case LineDTR:
+		return mc.SetDTR(level)
+	case LineRTS:
+		return mc.SetRTS(level)
+	default:
+		return ErrLineNotMapped
+	}
+}
+
+// SetDIAG grounds or releases the DIAG pin through port.
+func (h HardwareControl) SetDIAG(port SerialPort, grounded bool) error {
+	if h.DIAG == LineNone {
+		return fmt.Errorf("%w: DIAG", ErrLineNotMapped)
+	}
+	return setLine(port, h.DIAG, grounded != h.DIAGInvert)
+}
+
+// SetPower switches the console's power relay through port.
+func (h HardwareControl) SetPower(port SerialPort, on bool) error {
+	if h.Power == LineNone {
+		return fmt.Errorf("%w: power", ErrLineNotMapped)
+	}
+	return setLine(port, h.Power, on != h.PowerInvert)
+}
+
+// powerOffTime returns how long the power stays off in a cycle.
+func (h HardwareControl) powerOffTime() time.Duration {
+	if h.PowerOffTime > 0 {
+		return h.PowerOffTime
+	}
+	return DefaultPowerOffTime
+}
+
+// settleTime returns how long the syscon gets to start after power on.
+func (h HardwareControl) settleTime() time.Duration {
+	if h.SettleTime > 0 {
+		return h.SettleTime
+	}
+	return DefaultSettleTime
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,228 @@
+package syscon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+func TestHardwareControlLevels(t *testing.T) {
+	hw := HardwareControl{DIAG: LineDTR, Power: LineRTS, PowerInvert: true}
+	tests := []struct {
+		diag, power bool
+		dtr, rts    bool
+	}{
+		{diag: true, power: true, dtr: true, rts: false},
+		{diag: true, power: false, dtr: true, rts: true},
+		{diag: false, power: true, dtr: false, rts: false},
+		{diag: false, power: false, dtr: false, rts: true},
+	}
+	for _, tt := range tests {
+		dtr, rts := hw.Levels(true, true, tt.diag, tt.power)
+		if dtr != tt.dtr || rts != tt.rts {
+			t.Errorf("Levels(diag %v, power %v) = %v, %v; want %v, %v", tt.diag, tt.power, dtr, rts, tt.dtr, tt.rts)
+		}
+	}
+
+	// Unmapped lines keep their levels
+	if dtr, rts := (HardwareControl{}).Levels(true, false, true, false); !dtr || rts {
+		t.Errorf("unmapped Levels = %v, %v; want true, false unchanged", dtr, rts)
+	}
+	if dtr, rts := (HardwareControl{Power: LineDTR}).Levels(true, false, false, false); dtr || rts {
+		t.Errorf("Levels with RTS unmapped = %v, %v; want power off and RTS still low", dtr, rts)
+	}
+}
+
+func TestHardwareControlValidate(t *testing.T) {
+	valid := []HardwareControl{
+		{},
+		{DIAG: LineDTR},
+		{DIAG: LineDTR, Power: LineRTS},
+	}
+	for _, hw := range valid {
+		if err := hw.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v", hw, err)
+		}
+	}
+
+	invalid := []HardwareControl{
+		{DIAG: "cts"},
+		{DIAG: LineRTS, Power: LineRTS},
+		{Power: LineDTR, SettleTime: -time.Second},
+	}
+	for _, hw := range invalid {
+		if err := hw.Validate(); err == nil {
+			t.Errorf("Validate(%+v) accepted", hw)
+		}
+	}
+}
+
+func TestHardwareControlErrors(t *testing.T) {
+	hw := HardwareControl{DIAG: LineDTR}
+	if err := hw.SetDIAG(&MockSerialPort{}, true); !errors.Is(err, ErrNoModemControl) {
+		t.Errorf("SetDIAG on a port without modem lines = %v, want %v", err, ErrNoModemControl)
+	}
+	if err := hw.SetPower(NewEmulator(EmulatorConfig{}), true); !errors.Is(err, ErrLineNotMapped) {
+		t.Errorf("SetPower unmapped = %v, want %v", err, ErrLineNotMapped)
+	}
+}
+
+// linePort logs every control line change.
+type linePort struct {
+	*Emulator
+	log *[]string
+}
+
+func (p *linePort) SetDTR(level bool) error {
+	*p.log = append(*p.log, fmt.Sprintf("dtr=%v", level))
+	return p.Emulator.SetDTR(level)
+}
+
+func (p *linePort) SetRTS(level bool) error {
+	*p.log = append(*p.log, fmt.Sprintf("rts=%v", level))
+	return p.Emulator.SetRTS(level)
+}
+
+func TestSessionSwitchMode(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{})
+	var lines []string
+	var modes []*serial.Mode
+	broker := NewBroker(func(name string, mode *serial.Mode) (SerialPort, error) {
+		modes = append(modes, mode)
+		lines = append(lines, "open")
+		emu.mu.Lock()
+		emu.closed = false
+		emu.mu.Unlock()
+		return &linePort{Emulator: emu, log: &lines}, nil
+	})
+	s := NewSession(broker.Open)
+
+	profile := DefaultProfile(57600)
+	profile.Hardware = HardwareControl{
+		DIAG:         LineRTS,
+		Power:        LineDTR,
+		PowerOffTime: time.Millisecond,
+		SettleTime:   time.Millisecond,
+	}
+	if err := s.ConnectProfile("emu", "CXR", profile); err != nil {
+		t.Fatalf("ConnectProfile: %v", err)
+	}
+	var buf bytes.Buffer
+	rec, _ := NewRecorder(&buf)
+	s.SetRecorder(rec)
+
+	if err := s.SwitchMode(context.Background(), "CXRF", 115200); err != nil {
+		t.Fatalf("SwitchMode: %v", err)
+	}
+
+	// Power off, reopen with power still off and DIAG grounded, then power on
+	want := []string{"open", "dtr=false", "open", "rts=true", "dtr=true"}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("line changes = %v, want %v", lines, want)
+	}
+	reopen := modes[len(modes)-1]
+	if reopen.BaudRate != 115200 || reopen.InitialStatusBits == nil || reopen.InitialStatusBits.DTR {
+		t.Errorf("reopened with %+v, status %+v; want 115200 with power off", reopen, reopen.InitialStatusBits)
+	}
+	if dtr, rts := emu.Lines(); !dtr || !rts {
+		t.Errorf("final lines = %v, %v; want power on and DIAG grounded", dtr, rts)
+	}
+
+	if result, err := s.Command("version", time.Second); err != nil || len(result.Data) == 0 {
+		t.Errorf("version after switching = %+v, %v", result, err)
+	}
+}
+
+func TestSessionSwitchModeKeepsUnmappedLine(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{})
+	var modes []*serial.Mode
+	s := NewSession(func(name string, mode *serial.Mode) (SerialPort, error) {
+		modes = append(modes, mode)
+		emu.mu.Lock()
+		emu.closed = false
+		emu.mu.Unlock()
+		return emu, nil
+	})
+
+	// RTS drives the adapter's reset and is held low on purpose
+	profile := DefaultProfile(57600)
+	profile.RTS = false
+	profile.Hardware = HardwareControl{Power: LineDTR, PowerOffTime: time.Millisecond, SettleTime: time.Millisecond}
+	if err := s.ConnectProfile("emu", "CXR", profile); err != nil {
+		t.Fatalf("ConnectProfile: %v", err)
+	}
+	if err := s.SwitchMode(context.Background(), "SW", 57600); err != nil {
+		t.Fatalf("SwitchMode: %v", err)
+	}
+
+	if reopen := modes[len(modes)-1]; reopen.InitialStatusBits == nil || reopen.InitialStatusBits.RTS {
+		t.Errorf("reopened with status %+v, want RTS still low", reopen.InitialStatusBits)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.profile.RTS {
+		t.Error("SwitchMode raised RTS in the profile")
+	}
+}
+
+func TestSessionConnectAppliesHardwareLevels(t *testing.T) {
+	tests := []struct {
+		scType   string
+		dtr, rts bool
+	}{
+		{"CXR", false, false},
+		{"CXRF", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scType, func(t *testing.T) {
+			var got *serial.ModemOutputBits
+			s := NewSession(func(name string, mode *serial.Mode) (SerialPort, error) {
+				got = mode.InitialStatusBits
+				return NewEmulator(EmulatorConfig{}), nil
+			})
+
+			// DIAG on DTR, and an active-low power relay on RTS
+			profile := DefaultProfile(57600)
+			profile.Hardware = HardwareControl{DIAG: LineDTR, Power: LineRTS, PowerInvert: true}
+			if err := s.ConnectProfile("emu", tt.scType, profile); err != nil {
+				t.Fatalf("ConnectProfile: %v", err)
+			}
+			if got == nil || got.DTR != tt.dtr || got.RTS != tt.rts {
+				t.Errorf("opened with status %+v, want DTR %v, RTS %v", got, tt.dtr, tt.rts)
+			}
+		})
+	}
+}
+
+func TestSessionSwitchModeNeedsPowerLine(t *testing.T) {
+	opener, _ := sessionOpener(&MockSerialPort{})
+	s := NewSession(opener)
+	if err := s.Connect("/dev/ttyUSB0", "CXR", 57600); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := s.SwitchMode(context.Background(), "CXRF", 115200); !errors.Is(err, ErrLineNotMapped) {
+		t.Errorf("SwitchMode without a power line = %v, want %v", err, ErrLineNotMapped)
+	}
+}
+
+func TestSessionSwitchModeCancelled(t *testing.T) {
+	s := NewSession(func(string, *serial.Mode) (SerialPort, error) {
+		return NewEmulator(EmulatorConfig{}), nil
+	})
+	profile := DefaultProfile(57600)
+	profile.Hardware = HardwareControl{Power: LineDTR, PowerOffTime: time.Hour}
+	if err := s.ConnectProfile("emu", "CXR", profile); err != nil {
+		t.Fatalf("ConnectProfile: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.SwitchMode(ctx, "CXR", 57600); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SwitchMode error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,223 @@
+// Package syscon provides the steps of the internal mode wizard.
+package syscon
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// InternalModeOffset is the EEPROM byte that selects internal mode. The
+// factory value FF boots normally; 00 opens the CXRF shell once DIAG is
+// grounded, and stops the console booting until the checksums are fixed.
+const InternalModeOffset = 0x3961
+
+// WizardEnv is what the wizard steps run against.
+type WizardEnv struct {
+	Send         func(ctx context.Context, cmd string) (CommandResult, error)
+	Authenticate func(ctx context.Context) error
+	// SwitchMode power-cycles the console into scType, grounding DIAG for
+	// CXRF and releasing it otherwise. It returns ErrLineNotMapped when
+	// that has to be done by hand.
+	SwitchMode func(ctx context.Context, scType string) error
+}
+
+// WizardRecord keeps what the steps found along the way.
+type WizardRecord struct {
+	Original    byte // value at InternalModeOffset before the wizard changed it
+	HasOriginal bool
+}
+
+// WizardStep is one step of enabling or reverting internal mode.
+type WizardStep struct {
+	Title      string // what the step does
+	Mode       string // mode the console is in when the step runs
+	SwitchesTo string // mode the console is in afterwards, for power-cycle steps
+	Manual     string // how to do the step by hand when SwitchMode cannot
+	Command    string // command line the step writes, confirmed before it runs
+	// Run performs the step and describes what happened. An answer that
+	// differs from the expected one is an error wrapping ErrUnexpectedAnswer.
+	Run func(ctx context.Context, env WizardEnv, rec *WizardRecord) (string, error)
+}
+
+// EnableInternalModeSteps returns the guide's sequence for entering
+// internal mode: authenticate in CXR, check that 3961 reads FF, set it to
+// 00 and read it back, power-cycle with DIAG grounded, authenticate in
+// CXRF and fix the checksums.
+func EnableInternalModeSteps() []WizardStep {
+	get := fmt.Sprintf("EEP GET %04X 01", InternalModeOffset)
+	set := fmt.Sprintf("EEP SET %04X 01 00", InternalModeOffset)
+	return []WizardStep{
+		authStep("CXR"),
+		{
+			Title: "Read the internal mode byte",
+			Mode:  "CXR",
+			Run: func(ctx context.Context, env WizardEnv, rec *WizardRecord) (string, error) {
+				result, err := env.Send(ctx, get)
+				if err != nil {
+					return "", err
+				}
+				if v, ok := eepByte(result); ok {
+					rec.Original, rec.HasOriginal = v, true
+				}
+				answer, err := expectAnswer(get, result, "00000000 FF")
+				if err != nil {
+					return answer, err
+				}
+				return fmt.Sprintf("%s\nOriginal value %02X recorded", answer, rec.Original), nil
+			},
+		},
+		{
+			Title:   "Set the internal mode byte to 00",
+			Mode:    "CXR",
+			Command: set,
+			Run: func(ctx context.Context, env WizardEnv, rec *WizardRecord) (string, error) {
+				result, err := env.Send(ctx, set)
+				if err != nil {
+					return "", err
+				}
+				return expectAnswer(set, result, "00000000")
+			},
+		},
+		{
+			Title: "Verify the internal mode byte",
+			Mode:  "CXR",
+			Run: func(ctx context.Context, env WizardEnv, rec *WizardRecord) (string, error) {
+				result, err := env.Send(ctx, get)
+				if err != nil {
+					return "", err
+				}
+				return expectAnswer(get, result, "00000000 00")
+			},
+		},
+		switchStep("CXR", "CXRF"),
+		authStep("CXRF"),
+		checksumStep(),
+	}
+}
+
+// RevertInternalModeSteps returns the reverse sequence, run from internal
+// mode: set 3961 back to FF and read it back, fix the checksums and
+// power-cycle with DIAG released so the console boots normally.
+func RevertInternalModeSteps() []WizardStep {
+	write := fmt.Sprintf("w %04X FF", InternalModeOffset)
+	read := fmt.Sprintf("r %04X 1", InternalModeOffset)
+	return []WizardStep{
+		authStep("CXRF"),
+		{
+			Title:   "Set the internal mode byte back to FF",
+			Mode:    "CXRF",
+			Command: write,
+			Run: func(ctx context.Context, env WizardEnv, rec *WizardRecord) (string, error) {
+				result, err := env.Send(ctx, write)
+				if err != nil {
+					return "", err
+				}
+				if result.Code != 0 {
+					return "", fmt.Errorf("%w: %s returned %08X", ErrCommandFailed, write, result.Code)
+				}
+				return write + " sent", nil
+			},
+		},
+		{
+			Title: "Verify the internal mode byte",
+			Mode:  "CXRF",
+			Run: func(ctx context.Context, env WizardEnv, rec *WizardRecord) (string, error) {
+				result, err := env.Send(ctx, read)
+				if err != nil {
+					return "", err
+				}
+				text := strings.TrimSpace(resultText(result))
+				m := dumpByte.FindStringSubmatch(text)
+				if m == nil || !strings.EqualFold(m[1], "FF") {
+					return text, fmt.Errorf("%w: %s answered %q, want FF at %04X", ErrUnexpectedAnswer, read, text, InternalModeOffset)
+				}
+				return text, nil
+			},
+		},
+		checksumStep(),
+		switchStep("CXRF", "CXR"),
+	}
+}
+
+// dumpByte matches the first byte of an "r 3961 1" dump line.
+var dumpByte = regexp.MustCompile(fmt.Sprintf(`(?i)%04X:\s*([0-9a-f]{2})`, InternalModeOffset))
+
+// eepByte returns the single byte answered by EEP GET.
+func eepByte(result CommandResult) (byte, bool) {
+	if result.Code != 0 || len(result.Data) != 1 || !isHex(result.Data[0], 2) {
+		return 0, false
+	}
+	v, _ := strconv.ParseUint(result.Data[0], 16, 8)
+	return byte(v), true
+}
+
+// expectAnswer compares a CXR answer, printed as code and data, with want.
+func expectAnswer(cmd string, result CommandResult, want string) (string, error) {
+	answer := strings.TrimSpace(fmt.Sprintf("%08X %s", result.Code, strings.Join(result.Data, " ")))
+	if !strings.EqualFold(answer, want) {
+		return answer, fmt.Errorf("%w: %s answered %q, want %q", ErrUnexpectedAnswer, cmd, answer, want)
+	}
+	return answer, nil
+}
+
+func authStep(mode string) WizardStep {
+	return WizardStep{
+		Title: "Authenticate in " + mode,
+		Mode:  mode,
+		Run: func(ctx context.Context, env WizardEnv, rec *WizardRecord) (string, error) {
+			if err := env.Authenticate(ctx); err != nil {
+				return "", err
+			}
+			return "Authenticated", nil
+		},
+	}
+}
+
+func switchStep(from, to string) WizardStep {
+	title, manual := "Power off, ground DIAG and power on in CXRF",
+		"Switch the console off, connect DIAG to ground and switch it on again; the LED flashes red."
+	if to != "CXRF" {
+		title, manual = "Power off, release DIAG and power on in "+to,
+			"Switch the console off, disconnect DIAG from ground and switch it on again."
+	}
+	return WizardStep{
+		Title:      title,
+		Mode:       from,
+		SwitchesTo: to,
+		Manual:     manual,
+		Run: func(ctx context.Context, env WizardEnv, rec *WizardRecord) (string, error) {
+			if err := env.SwitchMode(ctx, to); err != nil {
+				return "", err
+			}
+			return "Console restarted in " + to + " mode", nil
+		},
+	}
+}
+
+func checksumStep() WizardStep {
+	return WizardStep{
+		Title: "Fix the EEPROM checksums",
+		Mode:  "CXRF",
+		Run: func(ctx context.Context, env WizardEnv, rec *WizardRecord) (string, error) {
+			result, err := env.Send(ctx, "eepcsum")
+			if err != nil {
+				return "", err
+			}
+			regions := ParseEepcsum(resultText(result))
+			if len(regions) == 0 {
+				return "", fmt.Errorf("%w: eepcsum reported no regions", ErrInvalidResponse)
+			}
+			fixes := ChecksumFixCommands(regions)
+			if len(fixes) == 0 {
+				return "All checksums OK", nil
+			}
+			if _, err := RepairChecksums(ctx, env.Send, regions); err != nil {
+				return "", err
+			}
+			return "Sent " + strings.Join(fixes, ", ") + "\nAll checksums OK", nil
+		},
+	}
+}
@@ -0,0 +1,121 @@
+package syscon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.bug.st/serial"
+)
+
+// rebootedEmulator survives the session closing the port, like a console
+// that keeps its EEPROM across a power cycle.
+type rebootedEmulator struct{ *Emulator }
+
+func (rebootedEmulator) Close() error { return nil }
+
+// emulatorWizardEnv runs the wizard against an emulator answering any
+// framing; switching mode reconnects the session in the new mode.
+func emulatorWizardEnv(t *testing.T, emu *Emulator) WizardEnv {
+	t.Helper()
+	s := NewSession(func(string, *serial.Mode) (SerialPort, error) { return rebootedEmulator{emu}, nil })
+	if err := s.Connect("emu", "CXR", 57600); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	return WizardEnv{
+		Send:         s.CommandContext,
+		Authenticate: s.AuthContext,
+		SwitchMode: func(ctx context.Context, scType string) error {
+			if err := s.Disconnect(); err != nil {
+				return err
+			}
+			return s.Connect("emu", scType, 115200)
+		},
+	}
+}
+
+func runWizard(t *testing.T, env WizardEnv, steps []WizardStep, rec *WizardRecord) {
+	t.Helper()
+	for _, step := range steps {
+		if _, err := step.Run(context.Background(), env, rec); err != nil {
+			t.Fatalf("%s: %v", step.Title, err)
+		}
+	}
+}
+
+func TestInternalModeWizard(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{})
+	env := emulatorWizardEnv(t, emu)
+
+	var rec WizardRecord
+	runWizard(t, env, EnableInternalModeSteps(), &rec)
+	if !rec.HasOriginal || rec.Original != 0xFF {
+		t.Errorf("record = %+v, want the original FF", rec)
+	}
+	if got := emu.EEPROM()[InternalModeOffset]; got != 0x00 {
+		t.Errorf("EEPROM[3961] = %02X after enabling, want 00", got)
+	}
+	if bad := BadChecksums(emulatorEepcsum(t, env)); len(bad) != 0 {
+		t.Errorf("bad checksums after enabling: %+v", bad)
+	}
+
+	runWizard(t, env, RevertInternalModeSteps(), &rec)
+	if got := emu.EEPROM()[InternalModeOffset]; got != 0xFF {
+		t.Errorf("EEPROM[3961] = %02X after reverting, want FF", got)
+	}
+}
+
+// emulatorEepcsum authenticates in CXRF and returns the eepcsum regions.
+func emulatorEepcsum(t *testing.T, env WizardEnv) []ChecksumRegion {
+	t.Helper()
+	if err := env.SwitchMode(context.Background(), "CXRF"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.Authenticate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	result, err := env.Send(context.Background(), "eepcsum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ParseEepcsum(resultText(result))
+}
+
+func TestInternalModeWizardChecksAnswers(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{})
+	env := emulatorWizardEnv(t, emu)
+	steps := EnableInternalModeSteps()
+
+	var rec WizardRecord
+	runWizard(t, env, steps[:1], &rec)
+	if _, err := env.Send(context.Background(), "EEP SET 3961 01 00"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 3961 already reads 00, so the wizard stops before changing anything
+	answer, err := steps[1].Run(context.Background(), env, &rec)
+	if !errors.Is(err, ErrUnexpectedAnswer) {
+		t.Fatalf("read step: %v, want ErrUnexpectedAnswer", err)
+	}
+	if answer != "00000000 00" || rec.Original != 0x00 || !rec.HasOriginal {
+		t.Errorf("answer %q, record %+v", answer, rec)
+	}
+}
+
+func TestInternalModeWizardCommandsAreDestructive(t *testing.T) {
+	for _, steps := range [][]WizardStep{EnableInternalModeSteps(), RevertInternalModeSteps()} {
+		writes := 0
+		for _, step := range steps {
+			if step.Command == "" {
+				continue
+			}
+			writes++
+			if danger := CommandDanger(step.Mode, step.Command); danger != DangerDestructive {
+				t.Errorf("%s in %s mode is %v, want destructive", step.Command, step.Mode, danger)
+			}
+		}
+		if writes != 1 {
+			t.Errorf("%d steps name their write, want 1", writes)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package syscon provides authentication state tracking and the Mullion
+// command permission masks.
+package syscon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AuthState is the syscon's authentication state as tracked by a Session.
+type AuthState int
+
+// Authentication states reported by Session.AuthState.
+const (
+	AuthNone AuthState = iota // no successful AUTH1/AUTH2 handshake on this connection
+	AuthAuthenticated
+)
+
+// String returns the label shown in the CONNECTION card.
+func (s AuthState) String() string {
+	if s == AuthAuthenticated {
+		return "AUTHENTICATED"
+	}
+	return "NOT AUTHENTICATED"
+}
+
+// Permission bits of the Mullion external command table. The catalog
+// gives 0x20 to VER, AUTH1 and AUTH2 only, the commands that answer before
+// the handshake, and 0x10 to every command but AUTH1 and AUTH2, which are
+// refused once the handshake is done.
+const (
+	PermBeforeAuth uint32 = 0x20
+	PermAfterAuth  uint32 = 0x10
+)
+
+// permissionNames names the permission bits whose meaning is known.
+var permissionNames = []struct {
+	bit  uint32
+	name string
+}{
+	{PermBeforeAuth, "before authentication"},
+	{PermAfterAuth, "after authentication"},
+}
+
+// Mask returns the permission bit a command needs to run in state s.
+func (s AuthState) Mask() uint32 {
+	if s == AuthAuthenticated {
+		return PermAfterAuth
+	}
+	return PermBeforeAuth
+}
+
+// DescribePermission returns the access levels granted by mask, such as
+// "after authentication", followed by each bit whose meaning is unknown
+// in hex.
+func DescribePermission(mask uint32) string {
+	var levels []string
+	rest := mask
+	for _, p := range permissionNames {
+		if mask&p.bit != 0 {
+			levels = append(levels, p.name)
+		}
+		rest &^= p.bit
+	}
+	if len(levels) == 0 {
+		levels = append(levels, "never")
+	}
+	desc := strings.Join(levels, ", ")
+	if rest == 0 {
+		return desc
+	}
+	var unknown []string
+	for bit := uint32(1); bit != 0; bit <<= 1 {
+		if rest&bit != 0 {
+			unknown = append(unknown, fmt.Sprintf("0x%X", bit))
+		}
+	}
+	return fmt.Sprintf("%s (unknown bits %s)", desc, strings.Join(unknown, ", "))
+}
+
+// Allowed reports whether c may run in state. Commands without a
+// permission mask, such as the CXRF shell commands, are always allowed.
+func (c *Command) Allowed(state AuthState) bool {
+	return c.Permission == 0 || c.Permission&state.Mask() != 0
+}
+
+// PermissionError reports a command whose permission mask does not allow
+// it in the current authentication state. It wraps ErrNotPermitted.
+type PermissionError struct {
+	Command    string
+	Permission uint32
+	State      AuthState
+}
+
+func (e *PermissionError) Error() string {
+	when := "before authentication"
+	if e.State == AuthAuthenticated {
+		when = "after authentication"
+	}
+	return fmt.Sprintf("%s: %s is not allowed %s (permission 0x%08X: %s)",
+		ErrNotPermitted, e.Command, when, e.Permission, DescribePermission(e.Permission))
+}
+
+func (e *PermissionError) Unwrap() error {
+	return ErrNotPermitted
+}
+
+// CheckPermission reports whether a command line in the given mode may run
+// in state, according to the catalog. Commands missing from the catalog
+// are allowed.
+func CheckPermission(scType, cmdLine string, state AuthState) error {
+	fields := strings.Fields(cmdLine)
+	if len(fields) == 0 {
+		return nil
+	}
+	cmd := lookupCommand(scType, fields[0])
+	if cmd == nil || cmd.Allowed(state) {
+		return nil
+	}
+	return &PermissionError{Command: cmd.Name, Permission: cmd.Permission, State: state}
+}
+
+// authAfter returns the authentication state after cmdLine was answered
+// with result, for handshakes typed by hand: a successful AUTH2 completes
+// it and AUTH1 starts a new one. A successful AUTHVER SET changes the
+// version the handshake is done with, so the console has to be
+// authenticated again. SW passes these to its external command handler,
+// as CXR does.
+func authAfter(state AuthState, scType, cmdLine string, result CommandResult) AuthState {
+	fields := strings.Fields(cmdLine)
+	if (scType != "CXR" && scType != "SW") || len(fields) == 0 {
+		return state
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "AUTH1":
+		return AuthNone
+	case "AUTH2":
+		if result.Code == 0 {
+			return AuthAuthenticated
+		}
+	case "AUTHVER":
+		if len(fields) > 1 && strings.EqualFold(fields[1], "SET") && result.Code == 0 {
+			return AuthNone
+		}
+	}
+	return state
+}
@@ -0,0 +1,166 @@
+package syscon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.bug.st/serial"
+)
+
+func TestPermissionBitsMatchCatalog(t *testing.T) {
+	var beforeAuth, notAfterAuth []string
+	for _, cmd := range MullionCommands {
+		if cmd.Permission&PermBeforeAuth != 0 {
+			beforeAuth = append(beforeAuth, cmd.Name)
+		}
+		if cmd.Permission&PermAfterAuth == 0 {
+			notAfterAuth = append(notAfterAuth, cmd.Name)
+		}
+	}
+	if got := strings.Join(beforeAuth, " "); got != "AUTH1 AUTH2 VER" {
+		t.Errorf("commands allowed before authentication: %s", got)
+	}
+	if got := strings.Join(notAfterAuth, " "); got != "AUTH1 AUTH2" {
+		t.Errorf("commands refused after authentication: %s", got)
+	}
+}
+
+func TestDescribePermission(t *testing.T) {
+	tests := []struct {
+		mask uint32
+		want string
+	}{
+		{0x30, "before authentication, after authentication"},
+		{0x0000C0EF, "before authentication (unknown bits 0x1, 0x2, 0x4, 0x8, 0x40, 0x80, 0x4000, 0x8000)"},
+		{0x0000C0DF, "after authentication (unknown bits 0x1, 0x2, 0x4, 0x8, 0x40, 0x80, 0x4000, 0x8000)"},
+		{0x0000C0C0, "never (unknown bits 0x40, 0x80, 0x4000, 0x8000)"},
+	}
+	for _, tt := range tests {
+		if got := DescribePermission(tt.mask); got != tt.want {
+			t.Errorf("DescribePermission(%08X) = %q, want %q", tt.mask, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPermission(t *testing.T) {
+	tests := []struct {
+		scType string
+		line   string
+		state  AuthState
+		ok     bool
+	}{
+		{"CXR", "VER", AuthNone, true},
+		{"CXR", "VER", AuthAuthenticated, true},
+		{"CXR", "EEP GET 3961 01", AuthNone, false},
+		{"CXR", "eep get 3961 01", AuthAuthenticated, true},
+		{"CXR", "AUTH1 10000000", AuthAuthenticated, false},
+		{"CXR", "NOSUCH", AuthNone, true},
+		{"CXRF", "eepcsum", AuthNone, true},
+		{"SW", "AUTH1 10000000", AuthAuthenticated, true},
+		{"SW", "SETCMDLONG FF FF", AuthNone, true},
+	}
+	for _, tt := range tests {
+		err := CheckPermission(tt.scType, tt.line, tt.state)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckPermission(%s, %q, %v) = %v, want ok %v", tt.scType, tt.line, tt.state, err, tt.ok)
+		}
+		if err != nil && !errors.Is(err, ErrNotPermitted) {
+			t.Errorf("CheckPermission error %v does not wrap ErrNotPermitted", err)
+		}
+	}
+
+	err := CheckPermission("CXR", "EEP GET 3961 01", AuthNone)
+	want := "not permitted: EEP is not allowed before authentication (permission 0x0000C0DF: after authentication (unknown bits 0x1, 0x2, 0x4, 0x8, 0x40, 0x80, 0x4000, 0x8000))"
+	if err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
+
+func TestAuthAfter(t *testing.T) {
+	ok, failed := CommandResult{}, CommandResult{Code: 0xF0000001}
+	tests := []struct {
+		scType string
+		line   string
+		result CommandResult
+		state  AuthState
+		want   AuthState
+	}{
+		{"CXR", "AUTH2 00", ok, AuthNone, AuthAuthenticated},
+		{"CXR", "AUTH2 00", failed, AuthNone, AuthNone},
+		{"CXR", "AUTH1 10000000", ok, AuthAuthenticated, AuthNone},
+		{"CXR", "AUTHVER SET 0001", ok, AuthAuthenticated, AuthNone},
+		{"CXR", "authver set 0001", ok, AuthAuthenticated, AuthNone},
+		{"CXR", "AUTHVER SET 0001", failed, AuthAuthenticated, AuthAuthenticated},
+		{"CXR", "AUTHVER GET", ok, AuthAuthenticated, AuthAuthenticated},
+		{"SW", "AUTHVER SET 0001", ok, AuthAuthenticated, AuthNone},
+		{"CXRF", "AUTH1 10000000", ok, AuthAuthenticated, AuthAuthenticated},
+	}
+	for _, tt := range tests {
+		if got := authAfter(tt.state, tt.scType, tt.line, tt.result); got != tt.want {
+			t.Errorf("authAfter(%v, %s, %q, %08X) = %v, want %v", tt.state, tt.scType, tt.line, tt.result.Code, got, tt.want)
+		}
+	}
+}
+
+func TestSessionTracksAuthState(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{Type: "CXR"})
+	s := NewSession(func(string, *serial.Mode) (SerialPort, error) { return emu, nil })
+	if err := s.Connect("emu", "CXR", 57600); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if s.AuthState() != AuthNone {
+		t.Errorf("state after Connect = %v", s.AuthState())
+	}
+
+	if err := s.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+	if s.AuthState() != AuthAuthenticated {
+		t.Errorf("state after Auth = %v", s.AuthState())
+	}
+
+	// A hand-typed AUTH1 starts a new handshake
+	if _, err := s.Command("AUTH1 10000000", 0); err != nil {
+		t.Fatalf("AUTH1: %v", err)
+	}
+	if s.AuthState() != AuthNone {
+		t.Errorf("state after AUTH1 = %v", s.AuthState())
+	}
+
+	if err := s.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+	if err := s.Disconnect(); err != nil {
+		t.Fatalf("Disconnect: %v", err)
+	}
+	if s.AuthState() != AuthNone {
+		t.Errorf("state after Disconnect = %v", s.AuthState())
+	}
+}
+
+func TestSessionTracksSWAuthState(t *testing.T) {
+	emu := NewEmulator(EmulatorConfig{Type: "SW"})
+	s := NewSession(func(string, *serial.Mode) (SerialPort, error) { return emu, nil })
+	if err := s.Connect("emu", "SW", 57600); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := s.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+	if s.AuthState() != AuthAuthenticated {
+		t.Errorf("state after Auth = %v", s.AuthState())
+	}
+	if _, err := s.Command("AUTH1 10000000", 0); err != nil {
+		t.Fatalf("AUTH1: %v", err)
+	}
+	if s.AuthState() != AuthNone {
+		t.Errorf("state after AUTH1 = %v", s.AuthState())
+	}
+}
+
+func TestAuthStateString(t *testing.T) {
+	if AuthNone.String() != "NOT AUTHENTICATED" || AuthAuthenticated.String() != "AUTHENTICATED" {
+		t.Errorf("String() = %q, %q", AuthNone, AuthAuthenticated)
+	}
+}
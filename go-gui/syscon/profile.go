@@ -0,0 +1,196 @@
+// Package syscon provides serial line settings saved per port.
+package syscon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// BaudRates lists the rates offered for a connection profile. The syscon
+// uses 57600 (CXR, SW) and 115200 (CXRF); the others are for other boards.
+var BaudRates = []int{9600, 19200, 38400, 57600, 115200}
+
+// Parity and stop bit names used by ConnectionProfile.
+var (
+	Parities = []string{"none", "odd", "even", "mark", "space"}
+	StopBits = []string{"1", "1.5", "2"}
+)
+
+var parityModes = map[string]serial.Parity{
+	"none":  serial.NoParity,
+	"odd":   serial.OddParity,
+	"even":  serial.EvenParity,
+	"mark":  serial.MarkParity,
+	"space": serial.SpaceParity,
+}
+
+var stopBitModes = map[string]serial.StopBits{
+	"1":   serial.OneStopBit,
+	"1.5": serial.OnePointFiveStopBits,
+	"2":   serial.TwoStopBits,
+}
+
+// ConnectionProfile holds the line settings used to open a port.
+type ConnectionProfile struct {
+	BaudRate   int           `json:"baud"`      // zero picks the mode's rate
+	DataBits   int           `json:"data_bits"` // 5 to 8
+	Parity     string        `json:"parity"`    // one of Parities
+	StopBits   string        `json:"stop_bits"` // one of StopBits
+	WriteDelay time.Duration `json:"write_delay"`
+	DTR        bool          `json:"dtr"` // DTR state on open
+	RTS        bool          `json:"rts"` // RTS state on open
+
+	// Hardware maps DTR/RTS to the DIAG pin and a power relay
+	Hardware HardwareControl `json:"hardware,omitempty"`
+}
+
+// DefaultProfile returns the syscon's 8N1 settings at baudRate with DTR
+// and RTS raised, as the serial driver does by default.
+func DefaultProfile(baudRate int) ConnectionProfile {
+	return ConnectionProfile{
+		BaudRate: baudRate,
+		DataBits: 8,
+		Parity:   "none",
+		StopBits: "1",
+		DTR:      true,
+		RTS:      true,
+	}
+}
+
+// WithBaudRate returns p with baudRate filled in if p leaves it to the mode.
+func (p ConnectionProfile) WithBaudRate(baudRate int) ConnectionProfile {
+	if p.BaudRate == 0 {
+		p.BaudRate = baudRate
+	}
+	return p
+}
+
+// Validate reports the first setting that cannot be used.
+func (p ConnectionProfile) Validate() error {
+	if p.BaudRate < 0 {
+		return fmt.Errorf("invalid baud rate %d", p.BaudRate)
+	}
+	if p.DataBits < 5 || p.DataBits > 8 {
+		return fmt.Errorf("invalid data bits %d, want 5 to 8", p.DataBits)
+	}
+	if _, ok := parityModes[p.Parity]; !ok {
+		return fmt.Errorf("invalid parity %q", p.Parity)
+	}
+	if _, ok := stopBitModes[p.StopBits]; !ok {
+		return fmt.Errorf("invalid stop bits %q", p.StopBits)
+	}
+	if p.WriteDelay < 0 {
+		return fmt.Errorf("invalid write delay %v", p.WriteDelay)
+	}
+	return p.Hardware.Validate()
+}
+
+// Mode returns the serial settings for opening a port with p.
+func (p ConnectionProfile) Mode() (*serial.Mode, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if p.BaudRate == 0 {
+		return nil, errors.New("no baud rate set")
+	}
+	mode := &serial.Mode{
+		BaudRate: p.BaudRate,
+		DataBits: p.DataBits,
+		Parity:   parityModes[p.Parity],
+		StopBits: stopBitModes[p.StopBits],
+	}
+	// The driver raises both lines on open; only ask for a change, since
+	// ports without modem lines such as pseudo-terminals reject the request
+	if !p.DTR || !p.RTS {
+		mode.InitialStatusBits = &serial.ModemOutputBits{DTR: p.DTR, RTS: p.RTS}
+	}
+	return mode, nil
+}
+
+// String returns the settings in the usual short form, such as "57600 8N1".
+func (p ConnectionProfile) String() string {
+	baud := "auto"
+	if p.BaudRate > 0 {
+		baud = fmt.Sprint(p.BaudRate)
+	}
+	parity := "?"
+	if p.Parity != "" {
+		parity = strings.ToUpper(p.Parity[:1])
+	}
+	return fmt.Sprintf("%s %d%s%s", baud, p.DataBits, parity, p.StopBits)
+}
+
+// sameLine reports whether two modes describe the same line settings.
+func sameLine(a, b *serial.Mode) bool {
+	return a.BaudRate == b.BaudRate && a.DataBits == b.DataBits &&
+		a.Parity == b.Parity && a.StopBits == b.StopBits
+}
+
+// ProfileStore keeps one ConnectionProfile per port name in a JSON file.
+type ProfileStore struct {
+	mu       sync.Mutex
+	path     string
+	profiles map[string]ConnectionProfile
+}
+
+// NewProfileStore returns an empty store that saves to path, replacing
+// whatever is there on the first Set.
+func NewProfileStore(path string) *ProfileStore {
+	return &ProfileStore{path: path, profiles: make(map[string]ConnectionProfile)}
+}
+
+// OpenProfileStore loads the profiles saved at path. A missing file gives
+// an empty store that is created on the first Set.
+func OpenProfileStore(path string) (*ProfileStore, error) {
+	s := NewProfileStore(path)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.profiles); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns the profile saved for portName, or the default profile with
+// the baud rate left to the mode.
+func (s *ProfileStore) Get(portName string) ConnectionProfile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.profiles[portName]; ok {
+		return p
+	}
+	return DefaultProfile(0)
+}
+
+// Set validates p, saves it for portName and writes the file.
+func (s *ProfileStore) Set(portName string, p ConnectionProfile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles[portName] = p
+
+	data, err := json.MarshalIndent(s.profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
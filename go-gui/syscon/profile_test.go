@@ -0,0 +1,158 @@
+package syscon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+func TestDefaultProfileMode(t *testing.T) {
+	mode, err := DefaultProfile(57600).Mode()
+	if err != nil {
+		t.Fatalf("Mode: %v", err)
+	}
+	if mode.BaudRate != 57600 || mode.DataBits != 8 || mode.Parity != serial.NoParity || mode.StopBits != serial.OneStopBit {
+		t.Errorf("mode = %+v, want 57600 8N1", mode)
+	}
+	if bits := mode.InitialStatusBits; bits != nil {
+		t.Errorf("status bits = %+v, want the driver default of both raised", bits)
+	}
+}
+
+func TestProfileMode(t *testing.T) {
+	p := ConnectionProfile{BaudRate: 9600, DataBits: 7, Parity: "even", StopBits: "2", RTS: false, DTR: true}
+	mode, err := p.Mode()
+	if err != nil {
+		t.Fatalf("Mode: %v", err)
+	}
+	if mode.DataBits != 7 || mode.Parity != serial.EvenParity || mode.StopBits != serial.TwoStopBits {
+		t.Errorf("mode = %+v", mode)
+	}
+	if bits := mode.InitialStatusBits; bits == nil || bits.RTS || !bits.DTR {
+		t.Errorf("status bits = %+v, want DTR raised and RTS held low", bits)
+	}
+	if got := p.String(); got != "9600 7E2" {
+		t.Errorf("String() = %q, want %q", got, "9600 7E2")
+	}
+}
+
+func TestProfileValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(*ConnectionProfile)
+	}{
+		{"negative baud", func(p *ConnectionProfile) { p.BaudRate = -1 }},
+		{"data bits", func(p *ConnectionProfile) { p.DataBits = 9 }},
+		{"parity", func(p *ConnectionProfile) { p.Parity = "sometimes" }},
+		{"stop bits", func(p *ConnectionProfile) { p.StopBits = "3" }},
+		{"write delay", func(p *ConnectionProfile) { p.WriteDelay = -time.Millisecond }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultProfile(57600)
+			tt.edit(&p)
+			if err := p.Validate(); err == nil {
+				t.Error("Validate accepted an invalid profile")
+			}
+		})
+	}
+
+	if _, err := DefaultProfile(0).Mode(); err == nil {
+		t.Error("Mode succeeded without a baud rate")
+	}
+	if got := DefaultProfile(0).WithBaudRate(115200).BaudRate; got != 115200 {
+		t.Errorf("WithBaudRate on auto = %d, want 115200", got)
+	}
+	if got := DefaultProfile(9600).WithBaudRate(115200).BaudRate; got != 9600 {
+		t.Errorf("WithBaudRate on a fixed rate = %d, want 9600", got)
+	}
+}
+
+func TestProfileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "profiles.json")
+	store, err := OpenProfileStore(path)
+	if err != nil {
+		t.Fatalf("OpenProfileStore on a missing file: %v", err)
+	}
+	if got := store.Get("/dev/ttyUSB0"); got != DefaultProfile(0) {
+		t.Errorf("Get on an empty store = %+v", got)
+	}
+
+	p := DefaultProfile(38400)
+	p.RTS = false
+	p.WriteDelay = 5 * time.Millisecond
+	if err := store.Set("/dev/ttyUSB0", p); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	bad := p
+	bad.Parity = "x"
+	if err := store.Set("/dev/ttyUSB1", bad); err == nil {
+		t.Error("Set accepted an invalid profile")
+	}
+
+	reopened, err := OpenProfileStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got := reopened.Get("/dev/ttyUSB0"); got != p {
+		t.Errorf("saved profile = %+v, want %+v", got, p)
+	}
+	if got := reopened.Get("/dev/ttyUSB1"); got != DefaultProfile(0) {
+		t.Errorf("invalid profile was saved: %+v", got)
+	}
+}
+
+func TestProfileStoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	os.WriteFile(path, []byte("{"), 0o644)
+	if _, err := OpenProfileStore(path); err == nil {
+		t.Error("OpenProfileStore accepted a corrupt file")
+	}
+}
+
+func TestSessionConnectProfile(t *testing.T) {
+	var got *serial.Mode
+	s := NewSession(func(name string, mode *serial.Mode) (SerialPort, error) {
+		got = mode
+		return &MockSerialPort{}, nil
+	})
+
+	p := DefaultProfile(9600)
+	p.RTS = false
+	if err := s.ConnectProfile("/dev/ttyUSB0", "CXR", p); err != nil {
+		t.Fatalf("ConnectProfile: %v", err)
+	}
+	if got.BaudRate != 9600 || got.InitialStatusBits.RTS {
+		t.Errorf("opened with %+v", got)
+	}
+
+	if err := s.ConnectProfile("/dev/ttyUSB0", "CXR", DefaultProfile(0)); !errors.Is(err, ErrSerialOpenFailed) {
+		t.Errorf("ConnectProfile without a baud rate error = %v, want %v", err, ErrSerialOpenFailed)
+	}
+}
+
+func TestWriteDelayBetweenChunks(t *testing.T) {
+	mock := &MockSerialPort{ReadData: []byte("R:3A:OK 00000000\r\n")}
+	p := DefaultProfile(57600)
+	p.WriteDelay = 20 * time.Millisecond
+	uart, err := NewPS3UARTWithProfile("p", "CXR", p, func(string, *serial.Mode) (SerialPort, error) {
+		return mock, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 10 + 15 + rest: three chunks, two pauses
+	start := time.Now()
+	mustCommand(t, uart, "AUTH1 10000000000000000000000000", time.Second)
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("long command took %v, want at least two 20ms pauses", elapsed)
+	}
+	if mock.WriteCalls != 3 {
+		t.Errorf("write calls = %d, want 3", mock.WriteCalls)
+	}
+}
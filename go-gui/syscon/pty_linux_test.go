@@ -0,0 +1,197 @@
+//go:build linux
+
+package syscon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// openPTY creates a pseudo-terminal pair and returns the master side and
+// the path of the slave device. The test is skipped when the system has
+// no pseudo-terminals.
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo-terminals not available: %v", err)
+	}
+
+	var unlock int32
+	var ptn uint32
+	if err := ptyIoctl(master, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err != nil {
+		master.Close()
+		t.Skipf("unlocking pseudo-terminal: %v", err)
+	}
+	if err := ptyIoctl(master, syscall.TIOCGPTN, unsafe.Pointer(&ptn)); err != nil {
+		master.Close()
+		t.Skipf("reading pseudo-terminal number: %v", err)
+	}
+
+	slave := fmt.Sprintf("/dev/pts/%d", ptn)
+	if _, err := os.Stat(slave); err != nil {
+		master.Close()
+		t.Skipf("pseudo-terminal slave not available: %v", err)
+	}
+	return master, slave
+}
+
+// ptyIoctl runs an ioctl against f's descriptor.
+func ptyIoctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	if err := conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	}); err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// runPTYDevice connects emu to the master side of a pseudo-terminal and
+// returns the slave path for the code under test to open. The pump stops
+// when the test finishes.
+func runPTYDevice(t *testing.T, emu *Emulator) string {
+	t.Helper()
+	master, slave := openPTY(t)
+	emu.SetReadTimeout(5 * time.Millisecond)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{}, 2)
+
+	// Host → device
+	go func() {
+		defer func() { stopped <- struct{}{} }()
+		buf := make([]byte, 256)
+		for {
+			n, err := master.Read(buf)
+			if n > 0 {
+				emu.Write(buf[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// Device → host
+	go func() {
+		defer func() { stopped <- struct{}{} }()
+		buf := make([]byte, 256)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			n, _ := emu.Read(buf)
+			if n > 0 {
+				if _, err := master.Write(buf[:n]); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	t.Cleanup(func() {
+		close(done)
+		master.Close()
+		<-stopped
+		<-stopped
+	})
+	return slave
+}
+
+func TestPTYAuthAndCommands(t *testing.T) {
+	tests := []struct {
+		scType string
+		speed  int
+		read   string
+		want   string
+	}{
+		{"CXR", 57600, "EEP GET 3961 01", "FF"},
+		{"CXRF", 115200, "r 3961 1", "00003961: FF"},
+		{"SW", 57600, "r 3961 1", "00003961: FF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scType, func(t *testing.T) {
+			slave := runPTYDevice(t, NewEmulator(EmulatorConfig{Type: tt.scType}))
+
+			uart, err := NewPS3UART(slave, tt.scType, tt.speed)
+			if err != nil {
+				t.Fatalf("NewPS3UART(%s): %v", slave, err)
+			}
+			defer uart.Close()
+
+			if err := uart.Auth(); err != nil {
+				t.Fatalf("Auth: %v", err)
+			}
+			result := mustCommand(t, uart, tt.read, time.Second)
+			if result.Code != 0 || len(result.Data) == 0 || !strings.Contains(result.Data[0], tt.want) {
+				t.Errorf("%s = %+v, want %q", tt.read, result, tt.want)
+			}
+		})
+	}
+}
+
+func TestPTYChunkedAnswer(t *testing.T) {
+	slave := runPTYDevice(t, NewEmulator(EmulatorConfig{
+		Type:   "CXR",
+		Faults: EmulatorFaults{ChunkSize: 2, Delay: 10 * time.Millisecond},
+	}))
+
+	uart, err := NewPS3UART(slave, "CXR", 57600)
+	if err != nil {
+		t.Fatalf("NewPS3UART: %v", err)
+	}
+	defer uart.Close()
+
+	if result := mustCommand(t, uart, "VER", time.Second); result.Code != 0 {
+		t.Errorf("VER = %+v, want OK", result)
+	}
+}
+
+func TestPTYReadTimeout(t *testing.T) {
+	slave := runPTYDevice(t, NewEmulator(EmulatorConfig{
+		Type:   "CXR",
+		Faults: EmulatorFaults{DropEvery: 1},
+	}))
+
+	uart, err := NewPS3UART(slave, "CXR", 57600)
+	if err != nil {
+		t.Fatalf("NewPS3UART: %v", err)
+	}
+	defer uart.Close()
+
+	start := time.Now()
+	_, err = uart.Command("VER", 300*time.Millisecond)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrNoResponse) {
+		t.Errorf("VER with no answer error = %v, want %v", err, ErrNoResponse)
+	}
+	if elapsed < 300*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("VER with no answer took %v, want about the 300ms deadline", elapsed)
+	}
+}
+
+func TestPTYOpenMissingDevice(t *testing.T) {
+	_, err := NewPS3UART("/dev/pts/does-not-exist", "CXR", 57600)
+	if !errors.Is(err, ErrSerialOpenFailed) {
+		t.Errorf("NewPS3UART error = %v, want %v", err, ErrSerialOpenFailed)
+	}
+}
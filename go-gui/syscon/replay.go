@@ -0,0 +1,230 @@
+// Package syscon provides playback of captured serial sessions.
+package syscon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrReplayMismatch is returned by ReplayPort.Write when the host sends
+// something other than what the capture recorded.
+var ErrReplayMismatch = errors.New("write does not match capture")
+
+// ReadCapture parses a capture file written by a Recorder.
+func ReadCapture(r io.Reader) (CaptureHeader, []CaptureEvent, error) {
+	var header CaptureHeader
+	var events []CaptureEvent
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if line == 1 {
+			if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+				return header, nil, fmt.Errorf("capture line 1: %w", err)
+			}
+			if header.Format != CaptureFormat {
+				return header, nil, fmt.Errorf("capture line 1: format %q, want %q", header.Format, CaptureFormat)
+			}
+			if header.Version > CaptureVersion {
+				return header, nil, fmt.Errorf("capture line 1: version %d is newer than %d", header.Version, CaptureVersion)
+			}
+			continue
+		}
+
+		var ev CaptureEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return header, nil, fmt.Errorf("capture line %d: %w", line, err)
+		}
+		events = append(events, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return header, nil, err
+	}
+	return header, events, nil
+}
+
+// replayEvent is a tx or rx capture event with its bytes decoded.
+type replayEvent struct {
+	dir  string
+	at   time.Duration
+	data []byte
+}
+
+// ReplayPort is a SerialPort that plays a capture back. Writes must match
+// the recorded tx bytes, though they may be split differently; recorded rx
+// bytes become readable once the writes before them have been matched.
+type ReplayPort struct {
+	mu          sync.Mutex
+	events      []replayEvent
+	pos         int // next event to match or release
+	txOff       int // bytes of events[pos] already matched
+	realtime    bool
+	readTimeout time.Duration
+	closed      bool
+	output      []emuOutput
+
+	// Timing anchor: the capture time and wall time of the last matched write
+	anchorAt   time.Duration
+	anchorWall time.Time
+}
+
+// NewReplayPort plays events back. With realtime set, replies are delayed
+// by the gaps recorded in the capture; otherwise they are readable at once.
+func NewReplayPort(events []CaptureEvent, realtime bool) (*ReplayPort, error) {
+	p := &ReplayPort{
+		realtime:    realtime,
+		readTimeout: 100 * time.Millisecond,
+		anchorWall:  time.Now(),
+	}
+	for i, ev := range events {
+		if ev.Dir != CaptureTX && ev.Dir != CaptureRX {
+			continue
+		}
+		data, err := hex.DecodeString(ev.Hex)
+		if err != nil {
+			return nil, fmt.Errorf("capture event %d: %w", i+1, err)
+		}
+		p.events = append(p.events, replayEvent{dir: ev.Dir, at: ev.Time, data: data})
+	}
+
+	p.releaseLocked()
+	return p, nil
+}
+
+// OpenReplay loads the capture file at path and plays it back.
+func OpenReplay(path string, realtime bool) (*ReplayPort, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	_, events, err := ReadCapture(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayPort(events, realtime)
+}
+
+// Done reports whether every recorded event has been played.
+func (p *ReplayPort) Done() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pos >= len(p.events) && len(p.output) == 0
+}
+
+// SetReadTimeout sets how long Read waits for a reply.
+func (p *ReplayPort) SetReadTimeout(d time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.readTimeout = d
+	return nil
+}
+
+// Close closes the port.
+func (p *ReplayPort) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+// Write matches data against the recorded writes.
+func (p *ReplayPort) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, errors.New("replay: port closed")
+	}
+
+	written := 0
+	for written < len(data) {
+		if p.pos >= len(p.events) {
+			return written, fmt.Errorf("%w: unexpected write %q after the end of the capture", ErrReplayMismatch, data[written:])
+		}
+
+		ev := p.events[p.pos]
+		want := ev.data[p.txOff:]
+		got := data[written:]
+		n := 0
+		for n < len(want) && n < len(got) && want[n] == got[n] {
+			n++
+		}
+		if n < len(want) && n < len(got) {
+			return written, fmt.Errorf("%w: event %d expected %q, got %q", ErrReplayMismatch, p.pos+1, want, got)
+		}
+
+		written += n
+		p.txOff += n
+		if p.txOff == len(ev.data) {
+			p.pos++
+			p.txOff = 0
+			p.anchorAt = ev.at
+			p.anchorWall = time.Now()
+			p.releaseLocked()
+		}
+	}
+	return written, nil
+}
+
+// releaseLocked queues the rx events that follow the matched writes.
+func (p *ReplayPort) releaseLocked() {
+	for p.pos < len(p.events) && p.events[p.pos].dir == CaptureRX {
+		ev := p.events[p.pos]
+		readyAt := time.Now()
+		if p.realtime {
+			readyAt = p.anchorWall.Add(ev.at - p.anchorAt)
+		}
+		p.output = append(p.output, emuOutput{data: ev.data, readyAt: readyAt})
+		p.pos++
+	}
+}
+
+// Read returns released reply bytes, waiting up to the read timeout.
+// Once the capture is exhausted it behaves like a quiet line.
+func (p *ReplayPort) Read(buf []byte) (int, error) {
+	p.mu.Lock()
+	timeout := p.readTimeout
+	p.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return 0, errors.New("replay: port closed")
+		}
+		n := 0
+		now := time.Now()
+		for n < len(buf) && len(p.output) > 0 && !now.Before(p.output[0].readyAt) {
+			out := &p.output[0]
+			copied := copy(buf[n:], out.data)
+			out.data = out.data[copied:]
+			n += copied
+			if len(out.data) == 0 {
+				p.output = p.output[1:]
+			}
+		}
+		p.mu.Unlock()
+
+		if n > 0 {
+			return n, nil
+		}
+		if !time.Now().Before(deadline) {
+			return 0, nil
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,125 @@
+package syscon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplaySWMultiline(t *testing.T) {
+	port, err := OpenReplay("testdata/sw_multiline.jsonl", false)
+	if err != nil {
+		t.Fatalf("OpenReplay: %v", err)
+	}
+	uart := NewPS3UARTWithPort(port, "SW", 57600)
+
+	if err := uart.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+
+	result := mustCommand(t, uart, "r 3960 20", time.Second)
+	if result.Code != 0 || len(result.Data) != 2 {
+		t.Fatalf("r 3960 20 = %+v, want two dump lines", result)
+	}
+	if !strings.HasPrefix(result.Data[0], "00003960: ") || !strings.HasPrefix(result.Data[1], "00003970: ") {
+		t.Errorf("dump lines = %q", result.Data)
+	}
+	if !port.Done() {
+		t.Error("capture not fully replayed")
+	}
+}
+
+func TestReplayCXRFAuth(t *testing.T) {
+	port, err := OpenReplay("testdata/cxrf_auth.jsonl", false)
+	if err != nil {
+		t.Fatalf("OpenReplay: %v", err)
+	}
+	uart := NewPS3UARTWithPort(port, "CXRF", 115200)
+
+	if err := uart.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+
+	result := mustCommand(t, uart, "errlog", time.Second)
+	if !strings.Contains(result.Data[0], "00: A0022110") {
+		t.Errorf("errlog = %q", result.Data[0])
+	}
+}
+
+func TestReplaySplitWrites(t *testing.T) {
+	port, err := NewReplayPort([]CaptureEvent{
+		{Dir: CaptureTX, Hex: "56455230"},
+		{Dir: CaptureRX, Hex: "4f4b"},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port.Write([]byte("VE"))
+	if n, _ := port.Read(make([]byte, 8)); n != 0 {
+		t.Error("reply released before the write was complete")
+	}
+	if _, err := port.Write([]byte("R0")); err != nil {
+		t.Fatalf("second half of write: %v", err)
+	}
+
+	buf := make([]byte, 8)
+	n, _ := port.Read(buf)
+	if string(buf[:n]) != "OK" {
+		t.Errorf("Read = %q, want OK", buf[:n])
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	port, _ := NewReplayPort([]CaptureEvent{{Dir: CaptureTX, Hex: "564552"}}, false)
+
+	if _, err := port.Write([]byte("VEX")); !errors.Is(err, ErrReplayMismatch) {
+		t.Errorf("Write error = %v, want %v", err, ErrReplayMismatch)
+	}
+
+	port, _ = NewReplayPort(nil, false)
+	if _, err := port.Write([]byte("VER")); !errors.Is(err, ErrReplayMismatch) {
+		t.Errorf("Write past the end error = %v, want %v", err, ErrReplayMismatch)
+	}
+}
+
+func TestReplayRealtime(t *testing.T) {
+	port, _ := NewReplayPort([]CaptureEvent{
+		{Time: 0, Dir: CaptureTX, Hex: "41"},
+		{Time: 50 * time.Millisecond, Dir: CaptureRX, Hex: "42"},
+	}, true)
+	port.SetReadTimeout(10 * time.Millisecond)
+
+	port.Write([]byte("A"))
+	buf := make([]byte, 8)
+	if n, _ := port.Read(buf); n != 0 {
+		t.Error("reply arrived before its recorded delay")
+	}
+
+	port.SetReadTimeout(200 * time.Millisecond)
+	if n, _ := port.Read(buf); string(buf[:n]) != "B" {
+		t.Errorf("Read = %q, want B", buf[:n])
+	}
+}
+
+func TestReadCaptureBadHeader(t *testing.T) {
+	if _, _, err := ReadCapture(strings.NewReader(`{"format":"pcap","version":1}` + "\n")); err == nil {
+		t.Error("ReadCapture accepted a foreign format")
+	}
+	if _, _, err := ReadCapture(strings.NewReader(`{"format":"ps3syscon-capture","version":99}` + "\n")); err == nil {
+		t.Error("ReadCapture accepted a newer version")
+	}
+}
+
+func TestReplayClosed(t *testing.T) {
+	port, _ := NewReplayPort(nil, false)
+	port.Close()
+
+	if _, err := port.Write([]byte("A")); err == nil {
+		t.Error("Write on closed port succeeded")
+	}
+	if _, err := port.Read(make([]byte, 1)); err == nil {
+		t.Error("Read on closed port succeeded")
+	}
+}
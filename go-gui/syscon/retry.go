@@ -0,0 +1,66 @@
+// Package syscon provides the retry policy for garbled command answers.
+package syscon
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RetryPolicy decides how often a Session re-sends a command whose answer
+// was garbled. Only commands IsIdempotent accepts are re-sent, and only
+// after a framing or checksum error; a missing answer, a syscon status
+// code or a transport failure is returned at once. The zero value never
+// retries.
+type RetryPolicy struct {
+	Attempts   int           // re-sends after the first try
+	Backoff    time.Duration // wait before the first re-send
+	MaxBackoff time.Duration // cap for the doubling wait; zero means no cap
+}
+
+// DefaultRetryPolicy suits long wires and cheap USB adapters.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   2,
+	Backoff:    100 * time.Millisecond,
+	MaxBackoff: time.Second,
+}
+
+// delay returns the wait before re-send n, counting from zero.
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.Backoff
+	for i := 0; i < n; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return d
+}
+
+// Retryable reports whether err is a garbled answer worth re-sending for.
+func Retryable(err error) bool {
+	return errors.Is(err, ErrChecksumMismatch) || errors.Is(err, ErrInvalidResponse)
+}
+
+// RetryEvent describes one re-send.
+type RetryEvent struct {
+	Command string        // command line being re-sent
+	Attempt int           // re-send number, starting at 1
+	Err     error         // error from the previous try
+	Delay   time.Duration // wait before this re-send
+}
+
+type retryNotifyKey struct{}
+
+// WithRetryNotify returns a context that makes Session report every
+// re-send of a command issued with it to fn, before the backoff wait.
+func WithRetryNotify(ctx context.Context, fn func(RetryEvent)) context.Context {
+	return context.WithValue(ctx, retryNotifyKey{}, fn)
+}
+
+// notifyRetry calls the function registered with WithRetryNotify, if any.
+func notifyRetry(ctx context.Context, ev RetryEvent) {
+	if fn, ok := ctx.Value(retryNotifyKey{}).(func(RetryEvent)); ok && fn != nil {
+		fn(ev)
+	}
+}
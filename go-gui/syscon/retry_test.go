@@ -0,0 +1,146 @@
+package syscon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{Attempts: 5, Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for n, w := range want {
+		if got := p.delay(n); got != w {
+			t.Errorf("delay(%d) = %v, want %v", n, got, w)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&ResponseError{Err: ErrChecksumMismatch}, true},
+		{&ResponseError{Err: ErrInvalidResponse}, true},
+		{&ResponseError{Err: ErrNoResponse}, false},
+		{ErrConnectionLost, false},
+		{context.Canceled, false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.want {
+			t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// scriptPort answers each written line with the next scripted answer.
+type scriptPort struct {
+	MockSerialPort
+	answers []string
+	pending []byte
+	lines   int
+}
+
+func (p *scriptPort) Write(data []byte) (int, error) {
+	p.WriteData = append(p.WriteData, data...)
+	if strings.HasSuffix(string(data), "\n") && p.lines < len(p.answers) {
+		p.pending = append(p.pending, p.answers[p.lines]...)
+		p.lines++
+	}
+	return len(data), nil
+}
+
+func (p *scriptPort) Read(buf []byte) (int, error) {
+	if len(p.pending) == 0 {
+		time.Sleep(time.Millisecond)
+		return 0, nil
+	}
+	n := copy(buf, p.pending)
+	p.pending = p.pending[n:]
+	return n, nil
+}
+
+// retrySession connects a session in scType mode that answers with answers.
+func retrySession(t *testing.T, scType string, answers ...string) (*Session, *scriptPort) {
+	t.Helper()
+	port := &scriptPort{answers: answers}
+	s := NewSession(func(string, *serial.Mode) (SerialPort, error) { return port, nil })
+	if err := s.Connect("/dev/ttyUSB0", scType, 57600); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	s.SetRetryPolicy(RetryPolicy{Attempts: 2, Backoff: time.Millisecond})
+	return s, port
+}
+
+func TestSessionRetriesGarbledRead(t *testing.T) {
+	s, _ := retrySession(t, "CXR", "R:00:OK 00000000\r\n", "R:3A:OK 00000000\r\n")
+
+	var events []RetryEvent
+	ctx := WithRetryNotify(context.Background(), func(ev RetryEvent) {
+		events = append(events, ev)
+	})
+	result, err := s.CommandContext(ctx, "VER")
+	if err != nil || result.Code != 0 {
+		t.Fatalf("VER = %+v, %v; want success on the re-send", result, err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d retry events, want 1", len(events))
+	}
+	if ev := events[0]; ev.Attempt != 1 || ev.Command != "VER" || !errors.Is(ev.Err, ErrChecksumMismatch) {
+		t.Errorf("retry event = %+v", ev)
+	}
+}
+
+func TestSessionRetryGivesUp(t *testing.T) {
+	bad := "R:00:OK 00000000\r\n"
+	s, port := retrySession(t, "CXR", bad, bad, bad, "R:3A:OK 00000000\r\n")
+
+	if _, err := s.Command("VER", 50*time.Millisecond); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("error = %v, want %v", err, ErrChecksumMismatch)
+	}
+	if port.lines != 3 {
+		t.Errorf("VER sent %d times, want 3", port.lines)
+	}
+}
+
+func TestSessionNeverRetriesWrites(t *testing.T) {
+	tests := []struct {
+		scType string
+		cmd    string
+		answer string
+	}{
+		{"CXR", "EEP SET 3961 01 00", "R:00:OK 00000000\r\n"},
+		{"CXR", "W8 00003961 00", "R:00:OK 00000000\r\n"},
+		{"SW", "w 3961 00", "OK 00000000:00\n"},
+		{"SW", "eeprominit", "OK 00000000:00\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			s, port := retrySession(t, tt.scType, tt.answer, tt.answer)
+
+			if _, err := s.Command(tt.cmd, 50*time.Millisecond); !errors.Is(err, ErrChecksumMismatch) {
+				t.Errorf("error = %v, want %v", err, ErrChecksumMismatch)
+			}
+			if port.lines != 1 {
+				t.Errorf("command sent %d times, want 1", port.lines)
+			}
+		})
+	}
+}
+
+func TestSessionRetryDisabledByDefault(t *testing.T) {
+	s, port := retrySession(t, "CXR", "R:00:OK 00000000\r\n", "R:3A:OK 00000000\r\n")
+	s.SetRetryPolicy(RetryPolicy{})
+
+	if _, err := s.Command("VER", 50*time.Millisecond); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("error = %v, want %v without a retry policy", err, ErrChecksumMismatch)
+	}
+	if port.lines != 1 {
+		t.Errorf("VER sent %d times, want 1", port.lines)
+	}
+}
@@ -0,0 +1,373 @@
+// Package syscon provides a persistent syscon session over one serial port.
+package syscon
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// ConnectionState describes whether a Session holds an open port.
+type ConnectionState int
+
+// Connection states reported by Session.State.
+const (
+	StateDisconnected ConnectionState = iota
+	StateConnected
+	StateFailed
+)
+
+// String returns the label shown in the CONNECTION card.
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnected:
+		return "CONNECTED"
+	case StateFailed:
+		return "CONNECTION LOST"
+	default:
+		return "DISCONNECTED"
+	}
+}
+
+// Session keeps a single PS3UART open between commands.
+// If the port fails during a command it is closed and reopened with the
+// same settings so the next command can go through.
+type Session struct {
+	mu       sync.Mutex
+	opener   SerialPortOpener
+	uart     *PS3UART
+	portName string
+	scType   string
+	profile  ConnectionProfile
+	retry    RetryPolicy
+
+	// recMu guards recorder and recPort, the open port's recording
+	// wrapper. It is never held during I/O, so SetRecorder does not wait
+	// for a command.
+	recMu    sync.Mutex
+	recorder *Recorder
+	recPort  *recordingPort
+
+	// status, auth and readOnly are used without mu, which a command
+	// holds for its whole exchange, so the UI never waits on the port.
+	// status is nil until the first connection; auth holds an AuthState.
+	status   atomic.Pointer[sessionStatus]
+	auth     atomic.Int32
+	readOnly atomic.Bool
+}
+
+// sessionStatus is a connection state with the error that caused it,
+// swapped as one so State and Err always agree.
+type sessionStatus struct {
+	state ConnectionState
+	err   error
+}
+
+// NewSession creates a disconnected session that opens ports with opener.
+func NewSession(opener SerialPortOpener) *Session {
+	return &Session{opener: opener}
+}
+
+// Connect opens the port with 8N1 framing at speed, closing any
+// connection that is already open.
+func (s *Session) Connect(portName, scType string, speed int) error {
+	return s.ConnectProfile(portName, scType, DefaultProfile(speed))
+}
+
+// ConnectProfile is like Connect with the line settings in profile, which
+// must have a baud rate. Lines mapped in profile.Hardware open at the
+// levels that keep the power on with DIAG grounded only for CXRF; the
+// others keep the profile's levels.
+func (s *Session) ConnectProfile(portName, scType string, profile ConnectionProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profile.DTR, profile.RTS = profile.Hardware.Levels(profile.DTR, profile.RTS, scType == "CXRF", true)
+
+	s.closeLocked()
+	s.portName = portName
+	s.scType = scType
+	s.profile = profile
+	s.auth.Store(int32(AuthNone))
+
+	return s.openLocked()
+}
+
+// Disconnect closes the port. It is safe to call when not connected.
+func (s *Session) Disconnect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.closeLocked()
+	s.setStatus(StateDisconnected, nil)
+	s.auth.Store(int32(AuthNone))
+	return err
+}
+
+// State returns the current connection state. It does not wait for a
+// command in progress.
+func (s *Session) State() ConnectionState {
+	if st := s.status.Load(); st != nil {
+		return st.state
+	}
+	return StateDisconnected
+}
+
+// Err returns the error that caused the last connection failure, if any.
+// It does not wait for a command in progress.
+func (s *Session) Err() error {
+	if st := s.status.Load(); st != nil {
+		return st.err
+	}
+	return nil
+}
+
+// setStatus records the connection state and the error that caused it.
+func (s *Session) setStatus(state ConnectionState, err error) {
+	s.status.Store(&sessionStatus{state: state, err: err})
+}
+
+// AuthState returns whether the syscon has been authenticated since the
+// session connected. Connect, Disconnect and SwitchMode reset it; a reopen
+// after a lost connection keeps it, as the console stays powered. It does
+// not wait for a command in progress.
+func (s *Session) AuthState() AuthState {
+	return AuthState(s.auth.Load())
+}
+
+// SetRecorder starts copying the session's traffic to rec, including the
+// port that is already open. A nil rec stops recording; the caller closes
+// the old recorder. It does not wait for a command in progress, which
+// goes on in the new recorder from its next read or write.
+func (s *Session) SetRecorder(rec *Recorder) {
+	s.recMu.Lock()
+	defer s.recMu.Unlock()
+
+	s.recorder = rec
+	if s.recPort != nil {
+		s.recPort.setRecorder(rec)
+	}
+}
+
+// SetRetryPolicy sets how commands with garbled answers are re-sent.
+// A new Session does not retry.
+func (s *Session) SetRetryPolicy(policy RetryPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retry = policy
+}
+
+// Command sends cmd over the open port.
+// A transport failure reopens the port and returns ErrConnectionLost; the
+// command is not re-sent because the syscon may already have executed it.
+// A garbled answer to an idempotent command is re-sent as the retry policy
+// allows. Other errors are as for PS3UART.Command.
+func (s *Session) Command(cmd string, timeout time.Duration) (CommandResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.commandLocked(context.Background(), cmd, timeout)
+}
+
+// CommandContext is like Command with the catalog timeout, but returns
+// ctx.Err() as soon as ctx is cancelled.
+func (s *Session) CommandContext(ctx context.Context, cmd string) (CommandResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.commandLocked(ctx, cmd, 0)
+}
+
+// SetReadOnly turns read-only mode on or off. In read-only mode only
+// commands the catalog classifies as safe are sent; the others fail with a
+// *ReadOnlyError. Authentication is still allowed. It does not wait for a
+// command in progress; the change applies from the next command.
+func (s *Session) SetReadOnly(on bool) {
+	s.readOnly.Store(on)
+}
+
+// ReadOnly reports whether read-only mode is on.
+func (s *Session) ReadOnly() bool {
+	return s.readOnly.Load()
+}
+
+// commandLocked sends cmd, re-sending it under the retry policy.
+func (s *Session) commandLocked(ctx context.Context, cmd string, timeout time.Duration) (CommandResult, error) {
+	if err := s.readyLocked(); err != nil {
+		return CommandResult{}, err
+	}
+	if s.readOnly.Load() {
+		if err := CheckReadOnly(s.scType, cmd); err != nil {
+			return CommandResult{}, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		result, cmdErr := s.uart.command(ctx, cmd, timeout)
+		if err := s.recoverLocked(); err != nil {
+			return CommandResult{}, err
+		}
+		if err := ctx.Err(); err != nil {
+			return CommandResult{}, err
+		}
+		if cmdErr == nil {
+			s.auth.Store(int32(authAfter(s.AuthState(), s.scType, cmd, result)))
+		}
+		if cmdErr == nil || attempt >= s.retry.Attempts || !Retryable(cmdErr) || !IsIdempotent(s.scType, cmd) {
+			return result, cmdErr
+		}
+
+		delay := s.retry.delay(attempt)
+		notifyRetry(ctx, RetryEvent{Command: cmd, Attempt: attempt + 1, Err: cmdErr, Delay: delay})
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return CommandResult{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// SwitchMode power-cycles the console into scType through the hardware
+// control lines of the session's profile: DIAG is grounded for CXRF and
+// released otherwise. The port is reopened in scType at speed while the
+// power is off, so the brief line change on open cannot reach a running
+// console.
+func (s *Session) SwitchMode(ctx context.Context, scType string, speed int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.readyLocked(); err != nil {
+		return err
+	}
+	hw := s.profile.Hardware
+	diag := scType == "CXRF"
+	if hw.Power == LineNone {
+		return fmt.Errorf("%w: power", ErrLineNotMapped)
+	}
+	if diag && hw.DIAG == LineNone {
+		return fmt.Errorf("%w: DIAG", ErrLineNotMapped)
+	}
+
+	if err := hw.SetPower(s.uart.port, false); err != nil {
+		return err
+	}
+	s.auth.Store(int32(AuthNone))
+	if err := sleepContext(ctx, hw.powerOffTime()); err != nil {
+		return err
+	}
+
+	profile := s.profile
+	profile.BaudRate = speed
+	profile.DTR, profile.RTS = hw.Levels(profile.DTR, profile.RTS, diag, false)
+	s.closeLocked()
+	s.scType = scType
+	s.profile = profile
+	if err := s.openLocked(); err != nil {
+		return err
+	}
+
+	if hw.DIAG != LineNone {
+		if err := hw.SetDIAG(s.uart.port, diag); err != nil {
+			return err
+		}
+	}
+	if err := hw.SetPower(s.uart.port, true); err != nil {
+		return err
+	}
+	// A reopen after a lost connection keeps the console powered
+	s.profile.DTR, s.profile.RTS = hw.Levels(s.profile.DTR, s.profile.RTS, diag, true)
+	return sleepContext(ctx, hw.settleTime())
+}
+
+// Auth authenticates over the open port.
+func (s *Session) Auth() error {
+	return s.AuthContext(context.Background())
+}
+
+// AuthContext is like Auth but gives up as soon as ctx is done.
+func (s *Session) AuthContext(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.readyLocked(); err != nil {
+		return err
+	}
+
+	s.auth.Store(int32(AuthNone))
+	authErr := s.uart.AuthContext(ctx)
+	if err := s.recoverLocked(); err != nil {
+		return err
+	}
+	if authErr == nil {
+		s.auth.Store(int32(AuthAuthenticated))
+	}
+	return authErr
+}
+
+// openLocked opens the port with the stored settings.
+func (s *Session) openLocked() error {
+	// Every port is wrapped, so a capture can start or stop at any time
+	opener := func(portName string, mode *serial.Mode) (SerialPort, error) {
+		port, err := s.opener(portName, mode)
+		if err != nil {
+			return nil, err
+		}
+		s.recMu.Lock()
+		defer s.recMu.Unlock()
+		s.recPort = &recordingPort{SerialPort: port, portName: portName, baudRate: mode.BaudRate}
+		s.recPort.setRecorder(s.recorder)
+		return s.recPort, nil
+	}
+	uart, err := NewPS3UARTWithProfile(s.portName, s.scType, s.profile, opener)
+	if err != nil {
+		s.setStatus(StateFailed, err)
+		return err
+	}
+	s.uart = uart
+	s.setStatus(StateConnected, nil)
+	return nil
+}
+
+// closeLocked closes the port if one is open.
+func (s *Session) closeLocked() error {
+	if s.uart == nil {
+		return nil
+	}
+	err := s.uart.Close()
+	s.uart = nil
+	s.recMu.Lock()
+	s.recPort = nil
+	s.recMu.Unlock()
+	return err
+}
+
+// readyLocked makes sure a port is open, retrying a lost connection once.
+func (s *Session) readyLocked() error {
+	if s.uart != nil {
+		return nil
+	}
+	if s.State() != StateFailed {
+		return ErrNotConnected
+	}
+	return s.openLocked()
+}
+
+// recoverLocked reopens the port after a transport error.
+func (s *Session) recoverLocked() error {
+	ioErr := s.uart.takeIOError()
+	if ioErr == nil {
+		return nil
+	}
+
+	s.closeLocked()
+	if err := s.openLocked(); err != nil {
+		return fmt.Errorf("%w: %v (reconnect failed: %v)", ErrConnectionLost, ioErr, err)
+	}
+	return fmt.Errorf("%w: %v (reconnected, please retry)", ErrConnectionLost, ioErr)
+}
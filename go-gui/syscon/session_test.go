@@ -0,0 +1,285 @@
+package syscon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// sessionOpener returns an opener that hands out the given ports in order.
+func sessionOpener(ports ...*MockSerialPort) (SerialPortOpener, *int) {
+	opened := 0
+	return func(portName string, mode *serial.Mode) (SerialPort, error) {
+		if opened >= len(ports) {
+			return nil, errors.New("port not found")
+		}
+		port := ports[opened]
+		opened++
+		return port, nil
+	}, &opened
+}
+
+func TestConnectionStateString(t *testing.T) {
+	tests := []struct {
+		state ConnectionState
+		want  string
+	}{
+		{StateDisconnected, "DISCONNECTED"},
+		{StateConnected, "CONNECTED"},
+		{StateFailed, "CONNECTION LOST"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("%d.String() = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestSessionCommandWithoutConnect(t *testing.T) {
+	s := NewSession(DefaultSerialPortOpener)
+
+	if _, err := s.Command("VER", time.Millisecond); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Command error = %v, want %v", err, ErrNotConnected)
+	}
+	if err := s.Auth(); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Auth error = %v, want %v", err, ErrNotConnected)
+	}
+}
+
+func TestSessionKeepsPortOpen(t *testing.T) {
+	mock := &MockSerialPort{
+		Responses: []string{
+			"R:3A:OK 00000000\r\n",
+			"R:3A:OK 00000000\r\n",
+		},
+	}
+	opener, opened := sessionOpener(mock)
+	s := NewSession(opener)
+
+	if err := s.Connect("/dev/test", "CXR", 57600); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if s.State() != StateConnected {
+		t.Errorf("State = %v, want %v", s.State(), StateConnected)
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := s.Command("VER", time.Millisecond)
+		if err != nil {
+			t.Fatalf("Command %d failed: %v", i, err)
+		}
+		if result.Code != 0 {
+			t.Errorf("Command %d code = %d, want 0", i, result.Code)
+		}
+	}
+
+	if *opened != 1 {
+		t.Errorf("port opened %d times, want 1", *opened)
+	}
+	if mock.Closed {
+		t.Error("port closed between commands")
+	}
+
+	if err := s.Disconnect(); err != nil {
+		t.Errorf("Disconnect failed: %v", err)
+	}
+	if !mock.Closed {
+		t.Error("port not closed by Disconnect")
+	}
+	if s.State() != StateDisconnected {
+		t.Errorf("State = %v, want %v", s.State(), StateDisconnected)
+	}
+}
+
+func TestSessionConnectFails(t *testing.T) {
+	opener, _ := sessionOpener()
+	s := NewSession(opener)
+
+	err := s.Connect("/dev/invalid", "CXR", 57600)
+	if !errors.Is(err, ErrSerialOpenFailed) {
+		t.Errorf("Connect error = %v, want %v", err, ErrSerialOpenFailed)
+	}
+	if s.State() != StateFailed {
+		t.Errorf("State = %v, want %v", s.State(), StateFailed)
+	}
+	if s.Err() == nil {
+		t.Error("Err() = nil after failed connect")
+	}
+}
+
+func TestSessionReconnectsAfterWriteError(t *testing.T) {
+	broken := &MockSerialPort{WriteErr: errors.New("device unplugged")}
+	healthy := &MockSerialPort{ReadData: []byte("R:3A:OK 00000000\r\n")}
+	opener, opened := sessionOpener(broken, healthy)
+	s := NewSession(opener)
+
+	if err := s.Connect("/dev/test", "CXR", 57600); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	_, err := s.Command("VER", time.Millisecond)
+	if !errors.Is(err, ErrConnectionLost) {
+		t.Fatalf("Command error = %v, want %v", err, ErrConnectionLost)
+	}
+	if !broken.Closed {
+		t.Error("broken port was not closed")
+	}
+	if *opened != 2 {
+		t.Errorf("port opened %d times, want 2", *opened)
+	}
+	if s.State() != StateConnected {
+		t.Errorf("State = %v, want %v", s.State(), StateConnected)
+	}
+
+	result, err := s.Command("VER", time.Millisecond)
+	if err != nil {
+		t.Fatalf("Command after reconnect failed: %v", err)
+	}
+	if result.Code != 0 {
+		t.Errorf("Command code = %d, want 0", result.Code)
+	}
+}
+
+func TestSessionReconnectFails(t *testing.T) {
+	broken := &MockSerialPort{ReadErr: errors.New("device unplugged")}
+	opener, _ := sessionOpener(broken)
+	s := NewSession(opener)
+
+	if err := s.Connect("/dev/test", "CXR", 57600); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	if _, err := s.Command("VER", time.Millisecond); !errors.Is(err, ErrConnectionLost) {
+		t.Errorf("Command error = %v, want %v", err, ErrConnectionLost)
+	}
+	if s.State() != StateFailed {
+		t.Errorf("State = %v, want %v", s.State(), StateFailed)
+	}
+
+	// The next command retries the open and reports why it failed
+	if _, err := s.Command("VER", time.Millisecond); !errors.Is(err, ErrSerialOpenFailed) {
+		t.Errorf("Command error = %v, want %v", err, ErrSerialOpenFailed)
+	}
+}
+
+func TestSessionCommandContext(t *testing.T) {
+	mock := &MockSerialPort{ReadData: []byte("R:3A:OK 00000000\r\n")}
+	opener, _ := sessionOpener(mock)
+	s := NewSession(opener)
+
+	if _, err := s.CommandContext(context.Background(), "VER"); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("CommandContext error = %v, want %v", err, ErrNotConnected)
+	}
+
+	if err := s.Connect("/dev/test", "CXR", 57600); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	result, err := s.CommandContext(context.Background(), "VER")
+	if err != nil {
+		t.Fatalf("CommandContext failed: %v", err)
+	}
+	if result.Code != 0 {
+		t.Errorf("Code = %d, want 0", result.Code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.CommandContext(ctx, "VER"); !errors.Is(err, context.Canceled) {
+		t.Errorf("CommandContext error = %v, want %v", err, context.Canceled)
+	}
+	if err := s.AuthContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("AuthContext error = %v, want %v", err, context.Canceled)
+	}
+	if s.State() != StateConnected {
+		t.Errorf("State = %v after cancel, want %v", s.State(), StateConnected)
+	}
+}
+
+// stalledPort holds every Read until release is closed, like a console
+// that takes its time to answer. reading is signalled when a Read starts.
+type stalledPort struct {
+	*Emulator
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (p *stalledPort) Read(buf []byte) (int, error) {
+	select {
+	case p.reading <- struct{}{}:
+	default:
+	}
+	<-p.release
+	return p.Emulator.Read(buf)
+}
+
+// stalledSession connects a session to a stalledPort and starts a VER
+// that stays blocked in the port until the returned release is called.
+func stalledSession(t *testing.T) (s *Session, release func()) {
+	t.Helper()
+	port := &stalledPort{
+		Emulator: NewEmulator(EmulatorConfig{Type: "CXR"}),
+		reading:  make(chan struct{}, 1),
+		release:  make(chan struct{}),
+	}
+	s = NewSession(func(string, *serial.Mode) (SerialPort, error) { return port, nil })
+	if err := s.Connect("emu", "CXR", 57600); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Command("VER", time.Second)
+	}()
+	<-port.reading
+	return s, func() {
+		close(port.release)
+		<-done
+	}
+}
+
+// notBlocked fails the test if call waits for the command in progress.
+func notBlocked(t *testing.T, name string, call func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		call()
+	}()
+	select {
+	case <-done:
+	case <-time.After(200 * time.Millisecond):
+		t.Errorf("%s waited for the command in progress", name)
+	}
+}
+
+func TestSessionDoesNotBlockDuringCommand(t *testing.T) {
+	s, release := stalledSession(t)
+
+	notBlocked(t, "AuthState", func() { s.AuthState() })
+	notBlocked(t, "State", func() {
+		if state := s.State(); state != StateConnected {
+			t.Errorf("State during a command = %v", state)
+		}
+	})
+	notBlocked(t, "Err", func() { s.Err() })
+	notBlocked(t, "SetReadOnly", func() { s.SetReadOnly(true) })
+	if !s.ReadOnly() {
+		t.Error("read-only mode did not turn on during a command")
+	}
+
+	var buf bytes.Buffer
+	rec, _ := NewRecorder(&buf)
+	notBlocked(t, "SetRecorder", func() { s.SetRecorder(rec) })
+	release()
+	_, events := readCapture(t, buf.Bytes())
+	if len(events) < 2 || events[0].Dir != CaptureOpen || events[1].Dir != CaptureRX {
+		t.Errorf("events = %+v, want open then the answer of the command in progress", events)
+	}
+}
@@ -0,0 +1,495 @@
+// Package syscon provides serial communication with PS3 Syscon.
+package syscon
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// SerialPort abstracts serial port operations for testing.
+type SerialPort interface {
+	Read([]byte) (int, error)
+	Write([]byte) (int, error)
+	Close() error
+	SetReadTimeout(time.Duration) error
+}
+
+// SerialPortOpener is a function type for opening serial ports.
+type SerialPortOpener func(portName string, mode *serial.Mode) (SerialPort, error)
+
+// DefaultSerialPortOpener opens a real serial port, or the built-in
+// emulator when portName is DemoPortName.
+var DefaultSerialPortOpener SerialPortOpener = func(portName string, mode *serial.Mode) (SerialPort, error) {
+	if portName == DemoPortName {
+		return openDemo(mode.BaudRate), nil
+	}
+	return serial.Open(portName, mode)
+}
+
+// OpenPort opens a serial port at baudRate with 8N1 framing.
+// It is used by tools that read the port directly, such as a serial monitor.
+func OpenPort(portName string, baudRate int) (SerialPort, error) {
+	return DefaultSerialPortOpener(portName, defaultMode(baudRate))
+}
+
+// defaultMode returns the 8N1 line settings the syscon UART uses.
+func defaultMode(baudRate int) *serial.Mode {
+	return &serial.Mode{
+		BaudRate: baudRate,
+		DataBits: 8,
+		Parity:   serial.NoParity,
+		StopBits: serial.OneStopBit,
+	}
+}
+
+// PS3UART handles serial communication with PS3 Syscon.
+type PS3UART struct {
+	port        SerialPort
+	scType      string
+	serialSpeed int
+	writeDelay  time.Duration // pause between the chunks of a long CXR command
+	ioErr       error         // first transport error since the last takeIOError
+}
+
+// CommandResult holds the result of a command execution.
+type CommandResult struct {
+	Code uint32
+	Data []string
+}
+
+// NewPS3UART creates a new PS3UART connection.
+func NewPS3UART(portName, scType string, serialSpeed int) (*PS3UART, error) {
+	return NewPS3UARTWithOpener(portName, scType, serialSpeed, DefaultSerialPortOpener)
+}
+
+// NewPS3UARTWithOpener creates a new PS3UART connection with a custom port opener.
+func NewPS3UARTWithOpener(portName, scType string, serialSpeed int, opener SerialPortOpener) (*PS3UART, error) {
+	return NewPS3UARTWithProfile(portName, scType, DefaultProfile(serialSpeed), opener)
+}
+
+// NewPS3UARTWithProfile creates a new PS3UART connection with the line
+// settings in profile, which must have a baud rate.
+func NewPS3UARTWithProfile(portName, scType string, profile ConnectionProfile, opener SerialPortOpener) (*PS3UART, error) {
+	mode, err := profile.Mode()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSerialOpenFailed, err)
+	}
+	port, err := opener(portName, mode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSerialOpenFailed, err)
+	}
+
+	if err := port.SetReadTimeout(100 * time.Millisecond); err != nil {
+		port.Close()
+		return nil, fmt.Errorf("failed to set read timeout: %w", err)
+	}
+
+	return &PS3UART{
+		port:        port,
+		scType:      scType,
+		serialSpeed: profile.BaudRate,
+		writeDelay:  profile.WriteDelay,
+	}, nil
+}
+
+// NewPS3UARTWithPort creates a new PS3UART with an existing port (for testing).
+func NewPS3UARTWithPort(port SerialPort, scType string, serialSpeed int) *PS3UART {
+	return &PS3UART{
+		port:        port,
+		scType:      scType,
+		serialSpeed: serialSpeed,
+	}
+}
+
+// Close closes the serial connection.
+func (p *PS3UART) Close() error {
+	if p.port != nil {
+		return p.port.Close()
+	}
+	return nil
+}
+
+// pause waits out the write delay between command chunks.
+func (p *PS3UART) pause(ctx context.Context) {
+	if p.writeDelay <= 0 {
+		return
+	}
+	timer := time.NewTimer(p.writeDelay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// send writes ASCII data to serial port unless ctx is already done.
+func (p *PS3UART) send(ctx context.Context, data string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := p.port.Write([]byte(data))
+	if err != nil && p.ioErr == nil {
+		p.ioErr = err
+	}
+	return err
+}
+
+// receiveUntil reads until complete reports a whole response, timeout
+// elapses or ctx is done, and returns everything read. Empty reads are port
+// read timeouts and simply mean the syscon has not finished answering yet.
+func (p *PS3UART) receiveUntil(ctx context.Context, timeout time.Duration, complete func(string) bool) string {
+	return p.receiveUntilIdle(ctx, timeout, complete, nil, 0)
+}
+
+// receiveUntilIdle is like receiveUntil, but also stops once started
+// reports that the answer has begun and nothing more has arrived for idle.
+func (p *PS3UART) receiveUntilIdle(ctx context.Context, timeout time.Duration, complete, started func(string) bool, idle time.Duration) string {
+	deadline := time.Now().Add(timeout)
+	buf := make([]byte, 4096)
+	var result []byte
+	lastData := time.Now()
+
+	for {
+		n, err := p.port.Read(buf)
+		if n > 0 {
+			result = append(result, buf[:n]...)
+			lastData = time.Now()
+			if complete(string(result)) {
+				break
+			}
+		} else if started != nil && time.Since(lastData) >= idle && started(string(result)) {
+			break
+		}
+		if err != nil {
+			if err != io.EOF && p.ioErr == nil {
+				// Remember the failure so a Session can reopen the port
+				p.ioErr = err
+			}
+			break
+		}
+		if ctx.Err() != nil || !time.Now().Before(deadline) {
+			break
+		}
+	}
+
+	return string(result)
+}
+
+// takeIOError returns and clears the first transport error seen by send or
+// receive since the previous call.
+func (p *PS3UART) takeIOError() error {
+	err := p.ioErr
+	p.ioErr = nil
+	return err
+}
+
+// Command sends a command and returns the result.
+// It returns as soon as the response is complete, or after timeout with
+// whatever arrived. A zero timeout uses the command's catalog timeout.
+//
+// A missing, malformed or corrupted answer is reported as a *ResponseError
+// wrapping ErrNoResponse, ErrInvalidResponse or ErrChecksumMismatch. A
+// well-formed answer carrying a syscon status code returns a nil error with
+// the status in CommandResult.Code.
+func (p *PS3UART) Command(cmd string, timeout time.Duration) (CommandResult, error) {
+	return p.command(context.Background(), cmd, timeout)
+}
+
+// CommandContext is like Command with the catalog timeout, but stops
+// writing and reading as soon as ctx is cancelled or its deadline passes.
+func (p *PS3UART) CommandContext(ctx context.Context, cmd string) (CommandResult, error) {
+	result, err := p.command(ctx, cmd, 0)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return CommandResult{}, ctxErr
+	}
+	return result, err
+}
+
+func (p *PS3UART) command(ctx context.Context, cmd string, timeout time.Duration) (CommandResult, error) {
+	if timeout <= 0 {
+		timeout = CommandTimeout(p.scType, cmd)
+	}
+	discardBacklog(p.port)
+	switch p.scType {
+	case "CXR":
+		return p.commandCXR(ctx, cmd, timeout)
+	case "SW":
+		return p.commandSW(ctx, cmd, timeout)
+	default:
+		return p.commandCXRF(ctx, cmd, timeout)
+	}
+}
+
+func (p *PS3UART) commandCXR(ctx context.Context, cmd string, timeout time.Duration) (CommandResult, error) {
+	length := len(cmd)
+	sum := checksum(cmd)
+
+	if length <= 10 {
+		p.send(ctx, fmt.Sprintf("C:%s:%s\r\n", sum, cmd))
+	} else {
+		j := 10
+		p.send(ctx, fmt.Sprintf("C:%s:%s", sum, cmd[0:j]))
+		for i := length - j; i > 15; i -= 15 {
+			p.pause(ctx)
+			p.send(ctx, cmd[j:j+15])
+			j += 15
+		}
+		p.pause(ctx)
+		p.send(ctx, cmd[j:]+"\r\n")
+	}
+
+	raw := p.receiveUntil(ctx, timeout, cxrComplete)
+	answer := strings.TrimSpace(raw)
+	if answer == "" {
+		return CommandResult{}, &ResponseError{Err: ErrNoResponse}
+	}
+	// Only the last line is the answer; anything before it is line noise
+	if i := strings.LastIndex(answer, "\n"); i >= 0 {
+		answer = strings.TrimSpace(answer[i+1:])
+	}
+
+	parts := strings.Split(answer, ":")
+	if len(parts) != 3 {
+		return CommandResult{}, &ResponseError{Err: ErrInvalidResponse, Reason: "answer length", Answer: raw}
+	}
+
+	if parts[0] != "R" && parts[0] != "E" {
+		return CommandResult{}, &ResponseError{Err: ErrInvalidResponse, Reason: "magic", Answer: raw}
+	}
+	if want := checksum(parts[2]); parts[1] != want {
+		return CommandResult{}, &ResponseError{Err: ErrChecksumMismatch, Reason: "answer checksum", Want: want, Got: parts[1], Answer: raw}
+	}
+
+	data := strings.Split(parts[2], " ")
+	if (parts[0] == "R" && len(data) < 2) || (parts[0] == "E" && len(data) != 2) {
+		return CommandResult{}, &ResponseError{Err: ErrInvalidResponse, Reason: "data length", Answer: raw}
+	}
+
+	code := parseHexUint32(data[1])
+	if data[0] != "OK" || len(data) < 2 {
+		return CommandResult{Code: code, Data: []string{}}, nil
+	}
+	return CommandResult{Code: code, Data: data[2:]}, nil
+}
+
+func (p *PS3UART) commandSW(ctx context.Context, cmd string, timeout time.Duration) (CommandResult, error) {
+	if len(cmd) >= swLongLimit {
+		result, err := p.command(ctx, swLongCommand, 0)
+		if err != nil {
+			return CommandResult{}, fmt.Errorf("SETCMDLONG: %w", err)
+		}
+		if result.Code != 0 {
+			return CommandResult{}, fmt.Errorf("%w: SETCMDLONG returned status %08X", ErrCommandFailed, result.Code)
+		}
+	}
+
+	p.send(ctx, fmt.Sprintf("%s:%s\r\n", cmd, checksum(cmd)))
+
+	raw := p.receiveUntil(ctx, timeout, swComplete)
+	answer := strings.TrimSpace(raw)
+	if answer == "" {
+		return CommandResult{}, &ResponseError{Err: ErrNoResponse}
+	}
+
+	lines := strings.Split(answer, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		// Output such as memory dumps may contain colons; the checksum is last
+		sep := strings.LastIndex(line, ":")
+		if sep < 0 {
+			return CommandResult{}, &ResponseError{Err: ErrInvalidResponse, Reason: fmt.Sprintf("line %d has no checksum", i+1), Answer: raw}
+		}
+
+		if want := checksum(line[:sep]); line[sep+1:] != want {
+			return CommandResult{}, &ResponseError{Err: ErrChecksumMismatch, Reason: fmt.Sprintf("line %d checksum", i+1), Want: want, Got: line[sep+1:], Answer: raw}
+		}
+		lines[i] = line[:sep] + "\n"
+	}
+
+	ret := strings.Split(strings.ReplaceAll(lines[len(lines)-1], "\n", ""), " ")
+	if len(ret) < 2 || len(ret[1]) != 8 {
+		return CommandResult{Code: 0, Data: lines}, nil
+	} else if len(lines) == 1 {
+		return CommandResult{Code: parseHexUint32(ret[1]), Data: ret[2:]}, nil
+	}
+	return CommandResult{Code: parseHexUint32(ret[1]), Data: lines[:len(lines)-1]}, nil
+}
+
+func (p *PS3UART) commandCXRF(ctx context.Context, cmd string, timeout time.Duration) (CommandResult, error) {
+	p.send(ctx, cmd+"\r\n")
+	raw := p.receiveUntilIdle(ctx, timeout, cxrfComplete, cxrfStarted(cmd), CXRFIdleGap)
+	if strings.TrimSpace(raw) == "" {
+		return CommandResult{}, &ResponseError{Err: ErrNoResponse}
+	}
+	answer := strings.TrimSpace(trimPrompt(raw))
+	return CommandResult{Code: 0, Data: []string{answer}}, nil
+}
+
+func parseHexUint32(s string) uint32 {
+	var val uint32
+	fmt.Sscanf(s, "%x", &val)
+	return val
+}
+
+// Auth performs authentication with the Syscon.
+// Returns nil on success, or an error describing the failure.
+func (p *PS3UART) Auth() error {
+	return p.AuthContext(context.Background())
+}
+
+// AuthContext is like Auth but gives up as soon as ctx is done.
+func (p *PS3UART) AuthContext(ctx context.Context) error {
+	if p.scType == "CXR" || p.scType == "SW" {
+		return p.authCXR(ctx)
+	}
+	return p.authCXRF(ctx)
+}
+
+func (p *PS3UART) authCXR(ctx context.Context) error {
+	auth1r, err := p.command(ctx, "AUTH1 10000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000", 0)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: AUTH1: %w", ErrInvalidAuthResponse, err)
+	}
+	if auth1r.Code != 0 || len(auth1r.Data) == 0 {
+		return fmt.Errorf("%w: AUTH1 command failed", ErrInvalidAuthResponse)
+	}
+
+	auth1rBytes, err := hex.DecodeString(auth1r.Data[0])
+	if err != nil {
+		return fmt.Errorf("%w: failed to decode AUTH1 response", ErrInvalidAuthResponse)
+	}
+
+	if len(auth1rBytes) < 0x40 || !bytesEqual(auth1rBytes[0:0x10], auth1rHdr) {
+		return fmt.Errorf("%w: invalid AUTH1 header", ErrInvalidAuthResponse)
+	}
+
+	data, err := aesDecryptCBC(sc2tb, zeroIV, auth1rBytes[0x10:0x40])
+	if err != nil {
+		return err
+	}
+
+	if !bytesEqual(data[0x8:0x10], zeroIV[0:0x8]) ||
+		!bytesEqual(data[0x10:0x20], authValue) ||
+		!bytesEqual(data[0x20:0x30], zeroIV) {
+		return fmt.Errorf("%w: invalid AUTH1 body", ErrInvalidAuthResponse)
+	}
+
+	newData := make([]byte, 0x30)
+	copy(newData[0:0x8], data[0x8:0x10])
+	copy(newData[0x8:0x10], data[0x0:0x8])
+
+	auth2Body, err := aesEncryptCBC(tb2sc, zeroIV, newData)
+	if err != nil {
+		return err
+	}
+
+	auth2Cmd := "AUTH2 " + strings.ToUpper(hex.EncodeToString(append(auth2Header, auth2Body...)))
+	auth2r, err := p.command(ctx, auth2Cmd, 0)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: AUTH2: %w", ErrAuthFailed, err)
+	}
+	if auth2r.Code != 0 {
+		return ErrAuthFailed
+	}
+
+	return nil
+}
+
+func (p *PS3UART) authCXRF(ctx context.Context) error {
+	scopen, err := p.command(ctx, "scopen", 0)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: scopen: %w", ErrInvalidAuthResponse, err)
+	}
+	if len(scopen.Data) == 0 || !strings.Contains(scopen.Data[0], "SC_READY") {
+		return fmt.Errorf("%w: scopen failed", ErrInvalidAuthResponse)
+	}
+
+	auth1r, err := p.command(ctx, "10000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000", 0)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: AUTH1: %w", ErrInvalidAuthResponse, err)
+	}
+	if len(auth1r.Data) == 0 {
+		return fmt.Errorf("%w: AUTH1 command failed", ErrInvalidAuthResponse)
+	}
+
+	parts := strings.Split(auth1r.Data[0], "\r")
+	if len(parts) <= 1 || len(parts[1]) <= 1 {
+		return fmt.Errorf("%w: invalid AUTH1 response format", ErrInvalidAuthResponse)
+	}
+
+	auth1rHex := parts[1][1:]
+	if len(auth1rHex) != 128 {
+		return fmt.Errorf("%w: invalid AUTH1 response length", ErrInvalidAuthResponse)
+	}
+
+	auth1rBytes, err := hex.DecodeString(auth1rHex)
+	if err != nil {
+		return fmt.Errorf("%w: failed to decode AUTH1 response", ErrInvalidAuthResponse)
+	}
+
+	if !bytesEqual(auth1rBytes[0:0x10], auth1rHdr) {
+		return fmt.Errorf("%w: invalid AUTH1 header", ErrInvalidAuthResponse)
+	}
+
+	data, err := aesDecryptCBC(sc2tb, zeroIV, auth1rBytes[0x10:0x40])
+	if err != nil {
+		return err
+	}
+
+	if !bytesEqual(data[0x8:0x10], zeroIV[0:0x8]) ||
+		!bytesEqual(data[0x10:0x20], authValue) ||
+		!bytesEqual(data[0x20:0x30], zeroIV) {
+		return fmt.Errorf("%w: invalid AUTH1 body", ErrInvalidAuthResponse)
+	}
+
+	newData := make([]byte, 0x30)
+	copy(newData[0:0x8], data[0x8:0x10])
+	copy(newData[0x8:0x10], data[0x0:0x8])
+
+	auth2Body, err := aesEncryptCBC(tb2sc, zeroIV, newData)
+	if err != nil {
+		return err
+	}
+
+	auth2Cmd := strings.ToUpper(hex.EncodeToString(append(auth2Header, auth2Body...)))
+	auth2r, err := p.command(ctx, auth2Cmd, 0)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: AUTH2: %w", ErrAuthFailed, err)
+	}
+	if len(auth2r.Data) == 0 || !strings.Contains(auth2r.Data[0], "SC_SUCCESS") {
+		return ErrAuthFailed
+	}
+
+	return nil
+}
+
+// ListPorts returns the names of the serial ports present on the system,
+// followed by DemoPortName.
+func ListPorts() []string {
+	ports, err := serial.GetPortsList()
+	if err != nil {
+		ports = nil
+	}
+	return append(ports, DemoPortName)
+}
@@ -1,14 +1,26 @@
-package main
+package syscon
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"go.bug.st/serial"
 )
 
+// mustCommand runs cmd and fails the test on a protocol error.
+func mustCommand(t *testing.T, uart *PS3UART, cmd string, timeout time.Duration) CommandResult {
+	t.Helper()
+	result, err := uart.Command(cmd, timeout)
+	if err != nil {
+		t.Fatalf("%s: %v", cmd, err)
+	}
+	return result
+}
+
 // MockSerialPort implements SerialPort for testing.
 type MockSerialPort struct {
 	ReadData    []byte
@@ -167,7 +179,7 @@ func TestPS3UARTSend(t *testing.T) {
 	mock := &MockSerialPort{}
 	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
 
-	err := uart.send("TEST")
+	err := uart.send(context.Background(), "TEST")
 	if err != nil {
 		t.Fatalf("send failed: %v", err)
 	}
@@ -178,36 +190,37 @@ func TestPS3UARTSend(t *testing.T) {
 	// Test write error
 	mock = &MockSerialPort{WriteErr: errors.New("write error")}
 	uart = NewPS3UARTWithPort(mock, "CXR", 57600)
-	err = uart.send("TEST")
+	err = uart.send(context.Background(), "TEST")
 	if err == nil {
 		t.Error("Expected write error, got nil")
 	}
 }
 
-func TestPS3UARTReceive(t *testing.T) {
-	// Test successful receive
+func TestPS3UARTReceiveUntil(t *testing.T) {
+	// Test successful receive stops at the end of the answer
 	mock := &MockSerialPort{ReadData: []byte("R:3A:OK 00000000\r\n")}
 	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
 
-	data, err := uart.receive()
-	if err != nil {
-		t.Fatalf("receive failed: %v", err)
-	}
+	data := uart.receiveUntil(context.Background(), time.Second, cxrComplete)
 	if data != "R:3A:OK 00000000\r\n" {
 		t.Errorf("received = %q, want %q", data, "R:3A:OK 00000000\r\n")
 	}
+	if mock.ReadCalls != 1 {
+		t.Errorf("ReadCalls = %d, want 1", mock.ReadCalls)
+	}
 
-	// Test receive with read error (should break loop)
+	// Test receive with read error (should break loop and record the error)
 	mock = &MockSerialPort{ReadErr: errors.New("read error")}
 	uart = NewPS3UARTWithPort(mock, "CXR", 57600)
-	_, err = uart.receive()
-	// Error breaks loop but returns empty string and nil error
-	if err != nil {
-		t.Errorf("receive with error should return nil error, got: %v", err)
+	if data := uart.receiveUntil(context.Background(), time.Second, cxrComplete); data != "" {
+		t.Errorf("received = %q, want empty", data)
+	}
+	if err := uart.takeIOError(); err == nil {
+		t.Error("read error was not recorded")
 	}
 }
 
-func TestPS3UARTReceiveMultipleChunks(t *testing.T) {
+func TestPS3UARTReceiveUntilMultipleChunks(t *testing.T) {
 	mock := &MockSerialPort{
 		ReadChunks: [][]byte{
 			[]byte("R:3A:"),
@@ -217,13 +230,38 @@ func TestPS3UARTReceiveMultipleChunks(t *testing.T) {
 	}
 	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
 
-	data, _ := uart.receive()
+	data := uart.receiveUntil(context.Background(), time.Second, cxrComplete)
 	expected := "R:3A:OK 00000000\r\n"
 	if data != expected {
 		t.Errorf("received = %q, want %q", data, expected)
 	}
 }
 
+func TestPS3UARTReceiveUntilDeadline(t *testing.T) {
+	// An incomplete answer is returned once the deadline passes
+	mock := &MockSerialPort{ReadData: []byte("R:3A:OK 000")}
+	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
+
+	start := time.Now()
+	data := uart.receiveUntil(context.Background(), 20*time.Millisecond, cxrComplete)
+	if data != "R:3A:OK 000" {
+		t.Errorf("received = %q, want %q", data, "R:3A:OK 000")
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("returned after %v, before the deadline", elapsed)
+	}
+}
+
+func TestCommandCXRSkipsLineNoise(t *testing.T) {
+	mock := &MockSerialPort{ReadData: []byte("\x00\xff\r\nR:3A:OK 00000000\r\n")}
+	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
+
+	result, _ := uart.commandCXR(context.Background(), "VER", time.Second)
+	if result.Code != 0 {
+		t.Errorf("Command failed with code: %d, data: %v", result.Code, result.Data)
+	}
+}
+
 func TestPS3UARTCommandRouting(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -241,7 +279,7 @@ func TestPS3UARTCommandRouting(t *testing.T) {
 			mock := &MockSerialPort{ReadData: []byte("R:3A:OK 00000000\r\n")}
 			uart := NewPS3UARTWithPort(mock, tt.scType, 57600)
 			// Just verify it doesn't panic
-			_ = uart.Command("VER", 0.001)
+			_, _ = uart.Command("VER", time.Millisecond)
 		})
 	}
 }
@@ -253,7 +291,7 @@ func TestCommandCXRShortCommand(t *testing.T) {
 	mock := &MockSerialPort{ReadData: []byte("R:3A:OK 00000000\r\n")}
 	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
 
-	result := uart.commandCXR("VER", 0.001)
+	result, _ := uart.commandCXR(context.Background(), "VER", time.Millisecond)
 	if result.Code != 0 {
 		t.Errorf("Command failed with code: %d, data: %v", result.Code, result.Data)
 	}
@@ -266,7 +304,7 @@ func TestCommandCXRLongCommand(t *testing.T) {
 	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
 
 	// Command longer than 10 chars to trigger multipart send
-	_ = uart.commandCXR("ERRLOG GET 00", 0.001)
+	_, _ = uart.commandCXR(context.Background(), "ERRLOG GET 00", time.Millisecond)
 	// Should have sent data in chunks
 	if mock.WriteCalls == 0 {
 		t.Error("Expected write calls for long command")
@@ -281,7 +319,7 @@ func TestCommandCXRVeryLongCommand(t *testing.T) {
 
 	// Command longer than 25 chars to trigger multiple chunks in loop
 	longCmd := "AUTH1 10000000000000000000000000000000000000"
-	_ = uart.commandCXR(longCmd, 0.001)
+	_, _ = uart.commandCXR(context.Background(), longCmd, time.Millisecond)
 	if mock.WriteCalls < 2 {
 		t.Errorf("Expected multiple write calls for very long command, got %d", mock.WriteCalls)
 	}
@@ -291,32 +329,43 @@ func TestCommandCXRInvalidResponse(t *testing.T) {
 	tests := []struct {
 		name     string
 		response string
-		errData  string
+		err      error
+		reason   string
 	}{
+		{
+			name:     "no answer",
+			response: "",
+			err:      ErrNoResponse,
+		},
 		{
 			name:     "too few parts",
 			response: "R:5D\r\n",
-			errData:  "Answer length",
+			err:      ErrInvalidResponse,
+			reason:   "answer length",
 		},
 		{
 			name:     "invalid magic",
 			response: "X:3A:OK 00000000\r\n",
-			errData:  "Magic",
+			err:      ErrInvalidResponse,
+			reason:   "magic",
 		},
 		{
 			name:     "invalid checksum",
 			response: "R:00:OK 00000000\r\n",
-			errData:  "Checksum",
+			err:      ErrChecksumMismatch,
+			reason:   "answer checksum",
 		},
 		{
 			name:     "R with insufficient data",
 			response: "R:9A:OK\r\n", // Checksum of "OK" is 0x9A
-			errData:  "Data length",
+			err:      ErrInvalidResponse,
+			reason:   "data length",
 		},
 		{
 			name:     "E with wrong data length",
 			response: "E:9A:OK\r\n", // Checksum of "OK" is 0x9A
-			errData:  "Data length",
+			err:      ErrInvalidResponse,
+			reason:   "data length",
 		},
 	}
 
@@ -325,24 +374,59 @@ func TestCommandCXRInvalidResponse(t *testing.T) {
 			mock := &MockSerialPort{ReadData: []byte(tt.response)}
 			uart := NewPS3UARTWithPort(mock, "CXR", 57600)
 
-			result := uart.commandCXR("VER", 0.001)
-			if result.Code != 0xFFFFFFFF {
-				t.Errorf("Expected error code 0xFFFFFFFF, got %d", result.Code)
+			_, err := uart.commandCXR(context.Background(), "VER", time.Millisecond)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
 			}
-			if len(result.Data) == 0 || result.Data[0] != tt.errData {
-				t.Errorf("Expected error data %q, got %v", tt.errData, result.Data)
+			var re *ResponseError
+			if !errors.As(err, &re) {
+				t.Fatalf("error %v is not a *ResponseError", err)
+			}
+			if re.Reason != tt.reason {
+				t.Errorf("Reason = %q, want %q", re.Reason, tt.reason)
+			}
+			if re.Answer != tt.response {
+				t.Errorf("Answer = %q, want %q", re.Answer, tt.response)
 			}
 		})
 	}
 }
 
+func TestCommandCXRChecksumMismatchDetail(t *testing.T) {
+	mock := &MockSerialPort{ReadData: []byte("R:00:OK 00000000\r\n")}
+	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
+
+	_, err := uart.Command("VER", time.Millisecond)
+	var re *ResponseError
+	if !errors.As(err, &re) {
+		t.Fatalf("error = %v, want a *ResponseError", err)
+	}
+	if re.Want != "3A" || re.Got != "00" {
+		t.Errorf("checksum want/got = %s/%s, want 3A/00", re.Want, re.Got)
+	}
+}
+
+func TestCommandStatusCodeIsNotAnError(t *testing.T) {
+	// Checksum of "NG F0000002" = 0x4D
+	mock := &MockSerialPort{ReadData: []byte("E:4D:NG F0000002\r\n")}
+	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
+
+	result, err := uart.Command("BOGUS", time.Millisecond)
+	if err != nil {
+		t.Fatalf("Command error = %v, want nil for a syscon status", err)
+	}
+	if result.Code != 0xF0000002 {
+		t.Errorf("Code = %08X, want F0000002", result.Code)
+	}
+}
+
 func TestCommandCXRErrorResponse(t *testing.T) {
 	// Test error response (E: prefix) with proper format
 	// Checksum of "ERR 00000001" = 0x3E3
 	mock := &MockSerialPort{ReadData: []byte("E:E3:ERR 00000001\r\n")}
 	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
 
-	result := uart.commandCXR("VER", 0.001)
+	result, _ := uart.commandCXR(context.Background(), "VER", time.Millisecond)
 	// E response with proper data
 	if result.Code != 1 {
 		t.Logf("Result: Code=%d, Data=%v", result.Code, result.Data)
@@ -361,7 +445,7 @@ func TestCommandCXRNotOKResponse(t *testing.T) {
 	mock := &MockSerialPort{ReadData: []byte(fmt.Sprintf("R:%02X:%s\r\n", checksum, resp))}
 	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
 
-	result := uart.commandCXR("VER", 0.001)
+	result, _ := uart.commandCXR(context.Background(), "VER", time.Millisecond)
 	if result.Code != 1 {
 		t.Logf("Not OK result: Code=%d, Data=%v", result.Code, result.Data)
 	}
@@ -372,7 +456,7 @@ func TestCommandSW(t *testing.T) {
 	mock := &MockSerialPort{ReadData: []byte("OK 00000000:56\n")}
 	uart := NewPS3UARTWithPort(mock, "SW", 57600)
 
-	result := uart.commandSW("VER", 0.001)
+	result, _ := uart.commandSW(context.Background(), "VER", time.Millisecond)
 	// Check we got some result
 	_ = result
 }
@@ -389,20 +473,20 @@ func TestCommandSWLongCommand(t *testing.T) {
 
 	// Create a command >= 64 chars
 	longCmd := "AUTH1 100000000000000000000000000000000000000000000000000000000000"
-	result := uart.commandSW(longCmd, 0.001)
+	result, _ := uart.commandSW(context.Background(), longCmd, time.Millisecond)
 	_ = result
 }
 
 func TestCommandSWSetcmdlongFails(t *testing.T) {
 	// Test SW mode with long command where SETCMDLONG fails
-	mock := &MockSerialPort{ReadData: []byte("ERR 00000001:56\n")}
+	mock := &MockSerialPort{ReadData: []byte("ERR 00000001:8A\n")}
 	uart := NewPS3UARTWithPort(mock, "SW", 57600)
 
 	// Create a command >= 64 chars
 	longCmd := "AUTH1 100000000000000000000000000000000000000000000000000000000000"
-	result := uart.commandSW(longCmd, 0.001)
-	if result.Code != 0xFFFFFFFF {
-		t.Errorf("Expected error code, got %d", result.Code)
+	_, err := uart.commandSW(context.Background(), longCmd, time.Millisecond)
+	if !errors.Is(err, ErrCommandFailed) {
+		t.Errorf("error = %v, want %v", err, ErrCommandFailed)
 	}
 }
 
@@ -410,17 +494,22 @@ func TestCommandSWInvalidResponse(t *testing.T) {
 	tests := []struct {
 		name     string
 		response string
-		errData  string
+		err      error
 	}{
+		{
+			name:     "no answer",
+			response: "",
+			err:      ErrNoResponse,
+		},
 		{
 			name:     "no colon separator",
 			response: "OK 00000000\n",
-			errData:  "Answer length",
+			err:      ErrInvalidResponse,
 		},
 		{
 			name:     "invalid checksum",
 			response: "OK 00000000:00\n",
-			errData:  "Checksum",
+			err:      ErrChecksumMismatch,
 		},
 	}
 
@@ -429,9 +518,9 @@ func TestCommandSWInvalidResponse(t *testing.T) {
 			mock := &MockSerialPort{ReadData: []byte(tt.response)}
 			uart := NewPS3UARTWithPort(mock, "SW", 57600)
 
-			result := uart.commandSW("VER", 0.001)
-			if result.Code != 0xFFFFFFFF {
-				t.Errorf("Expected error code 0xFFFFFFFF, got %d", result.Code)
+			_, err := uart.commandSW(context.Background(), "VER", time.Millisecond)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v", err, tt.err)
 			}
 		})
 	}
@@ -457,7 +546,7 @@ func TestCommandSWMultilineResponse(t *testing.T) {
 	mock := &MockSerialPort{ReadData: []byte(response)}
 	uart := NewPS3UARTWithPort(mock, "SW", 57600)
 
-	result := uart.commandSW("ERRLOG GET 00", 0.001)
+	result, _ := uart.commandSW(context.Background(), "ERRLOG GET 00", time.Millisecond)
 	_ = result
 }
 
@@ -473,7 +562,7 @@ func TestCommandSWShortLastLine(t *testing.T) {
 	mock := &MockSerialPort{ReadData: []byte(response)}
 	uart := NewPS3UARTWithPort(mock, "SW", 57600)
 
-	result := uart.commandSW("VER", 0.001)
+	result, _ := uart.commandSW(context.Background(), "VER", time.Millisecond)
 	// Should return with code 0 and the lines as data
 	if result.Code != 0 {
 		t.Logf("Short line result: Code=%d", result.Code)
@@ -481,15 +570,48 @@ func TestCommandSWShortLastLine(t *testing.T) {
 }
 
 func TestCommandCXRF(t *testing.T) {
-	mock := &MockSerialPort{ReadData: []byte("SC_READY\r\n")}
+	mock := &MockSerialPort{ReadData: []byte("scopen\r\nSC_READY\r\n$ ")}
 	uart := NewPS3UARTWithPort(mock, "CXRF", 115200)
 
-	result := uart.commandCXRF("scopen", 0.001)
+	result, _ := uart.commandCXRF(context.Background(), "scopen", time.Millisecond)
 	if result.Code != 0 {
 		t.Errorf("Expected code 0, got %d", result.Code)
 	}
-	if len(result.Data) == 0 || result.Data[0] != "SC_READY" {
-		t.Errorf("Expected data [SC_READY], got %v", result.Data)
+	if len(result.Data) == 0 || result.Data[0] != "scopen\r\nSC_READY" {
+		t.Errorf("Expected data [scopen\\r\\nSC_READY], got %q", result.Data)
+	}
+}
+
+// promptlessPort hides the emulator's shell prompt, like firmware that
+// prints none.
+type promptlessPort struct{ *Emulator }
+
+func (p promptlessPort) Read(buf []byte) (int, error) {
+	n, err := p.Emulator.Read(buf)
+	out := strings.ReplaceAll(string(buf[:n]), CXRFPrompt, "")
+	return copy(buf, out), err
+}
+
+func TestCommandCXRFWithoutPrompt(t *testing.T) {
+	defer func(gap time.Duration) { CXRFIdleGap = gap }(CXRFIdleGap)
+	CXRFIdleGap = 50 * time.Millisecond
+
+	emu := NewEmulator(EmulatorConfig{Type: "CXRF"})
+	if err := emu.SetReadTimeout(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	uart := NewPS3UARTWithPort(promptlessPort{emu}, "CXRF", 115200)
+	if err := uart.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+
+	start := time.Now()
+	result := mustCommand(t, uart, "r 3961 1", 0)
+	if !strings.Contains(result.Data[0], "00003961: FF") {
+		t.Errorf("r 3961 = %q, want a dump line with FF", result.Data[0])
+	}
+	if elapsed := time.Since(start); elapsed >= DefaultCommandTimeout {
+		t.Errorf("answer without a prompt took %v, want it to end after the idle gap", elapsed)
 	}
 }
 
@@ -693,8 +815,8 @@ func TestAuthCXRFScopenSuccess(t *testing.T) {
 	// Test CXRF auth flow - scopen succeeds but auth1 has no valid data
 	mock := &MockSerialPort{
 		Responses: []string{
-			"SC_READY\r\n", // scopen response
-			"INVALID\r\n",  // AUTH1 response - no \r to split
+			"SC_READY\r\n$ ", // scopen response
+			"INVALID\r\n$ ",  // AUTH1 response - no \r to split
 		},
 	}
 	uart := NewPS3UARTWithPort(mock, "CXRF", 115200)
@@ -710,8 +832,8 @@ func TestAuthCXRFEmptyAuth1Data(t *testing.T) {
 	// Test CXRF auth where auth1 response has parts[1] too short
 	mock := &MockSerialPort{
 		Responses: []string{
-			"SC_READY\r\n",
-			"OK\r\r\n", // parts[1] = "" after [1:]
+			"SC_READY\r\n$ ",
+			"OK\r\r\n$ ", // parts[1] = "" after [1:]
 		},
 	}
 	uart := NewPS3UARTWithPort(mock, "CXRF", 115200)
@@ -740,9 +862,9 @@ func TestAuthCXRFValidAuth1(t *testing.T) {
 
 	mock := &MockSerialPort{
 		Responses: []string{
-			"SC_READY\r\n",
-			fmt.Sprintf("OK\r %s\r\n", auth1Hex),
-			"SC_SUCCESS\r\n",
+			"SC_READY\r\n$ ",
+			fmt.Sprintf("OK\r %s\r\n$ ", auth1Hex),
+			"SC_SUCCESS\r\n$ ",
 		},
 	}
 	uart := NewPS3UARTWithPort(mock, "CXRF", 115200)
@@ -756,8 +878,8 @@ func TestAuthCXRFValidAuth1(t *testing.T) {
 func TestAuthCXRFInvalidAuth1Hex(t *testing.T) {
 	mock := &MockSerialPort{
 		Responses: []string{
-			"SC_READY\r\n",
-			"OK\r 00112233\r\n", // Too short hex (not 128 chars)
+			"SC_READY\r\n$ ",
+			"OK\r 00112233\r\n$ ", // Too short hex (not 128 chars)
 		},
 	}
 	uart := NewPS3UARTWithPort(mock, "CXRF", 115200)
@@ -773,8 +895,8 @@ func TestAuthCXRFInvalidAuth1Header(t *testing.T) {
 	invalidData := make([]byte, 64)
 	mock := &MockSerialPort{
 		Responses: []string{
-			"SC_READY\r\n",
-			fmt.Sprintf("OK\r %X\r\n", invalidData),
+			"SC_READY\r\n$ ",
+			fmt.Sprintf("OK\r %X\r\n$ ", invalidData),
 		},
 	}
 	uart := NewPS3UARTWithPort(mock, "CXRF", 115200)
@@ -792,8 +914,8 @@ func TestAuthCXRFInvalidAuth1Body(t *testing.T) {
 
 	mock := &MockSerialPort{
 		Responses: []string{
-			"SC_READY\r\n",
-			fmt.Sprintf("OK\r %X\r\n", auth1Response),
+			"SC_READY\r\n$ ",
+			fmt.Sprintf("OK\r %X\r\n$ ", auth1Response),
 		},
 	}
 	uart := NewPS3UARTWithPort(mock, "CXRF", 115200)
@@ -818,9 +940,9 @@ func TestAuthCXRFAuth2Fails(t *testing.T) {
 
 	mock := &MockSerialPort{
 		Responses: []string{
-			"SC_READY\r\n",
-			fmt.Sprintf("OK\r %X\r\n", auth1Response),
-			"SC_FAIL\r\n", // AUTH2 fails - doesn't contain SC_SUCCESS
+			"SC_READY\r\n$ ",
+			fmt.Sprintf("OK\r %X\r\n$ ", auth1Response),
+			"SC_FAIL\r\n$ ", // AUTH2 fails - doesn't contain SC_SUCCESS
 		},
 	}
 	uart := NewPS3UARTWithPort(mock, "CXRF", 115200)
@@ -851,13 +973,13 @@ func TestAuthSWMode(t *testing.T) {
 	}
 }
 
-func TestGetSerialPorts(t *testing.T) {
+func TestListPorts(t *testing.T) {
 	// This tests the actual function which queries system ports
 	// We can't mock the serial library, but we can verify it doesn't panic
-	ports := getSerialPorts()
+	ports := ListPorts()
 	// Just verify it returns a slice (may be empty on systems without serial ports)
 	if ports == nil {
-		t.Error("getSerialPorts returned nil, expected empty slice")
+		t.Error("ListPorts returned nil, expected empty slice")
 	}
 }
 
@@ -940,3 +1062,67 @@ func TestAuthCXRAuth2Fails(t *testing.T) {
 		t.Error("Expected error for AUTH2 failure")
 	}
 }
+
+func TestCommandContextCancelled(t *testing.T) {
+	mock := &MockSerialPort{ReadData: []byte("R:3A:OK 00000000\r\n")}
+	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := uart.CommandContext(ctx, "VER")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("CommandContext error = %v, want %v", err, context.Canceled)
+	}
+	if mock.WriteCalls != 0 {
+		t.Errorf("WriteCalls = %d, want 0 after cancellation", mock.WriteCalls)
+	}
+}
+
+func TestCommandContextDeadline(t *testing.T) {
+	// No answer arrives, so the context deadline ends the read long before
+	// the catalog timeout would
+	mock := &MockSerialPort{}
+	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := uart.CommandContext(ctx, "VER")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CommandContext error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed >= DefaultCommandTimeout {
+		t.Errorf("CommandContext took %v, deadline was ignored", elapsed)
+	}
+}
+
+func TestCommandContextSuccess(t *testing.T) {
+	mock := &MockSerialPort{ReadData: []byte("R:3A:OK 00000000\r\n")}
+	uart := NewPS3UARTWithPort(mock, "CXR", 57600)
+
+	result, err := uart.CommandContext(context.Background(), "VER")
+	if err != nil {
+		t.Fatalf("CommandContext failed: %v", err)
+	}
+	if result.Code != 0 {
+		t.Errorf("Code = %d, want 0", result.Code)
+	}
+}
+
+func TestAuthContextCancelled(t *testing.T) {
+	for _, scType := range []string{"CXR", "CXRF"} {
+		t.Run(scType, func(t *testing.T) {
+			mock := &MockSerialPort{}
+			uart := NewPS3UARTWithPort(mock, scType, 57600)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if err := uart.AuthContext(ctx); !errors.Is(err, context.Canceled) {
+				t.Errorf("AuthContext error = %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Package syscon provides a wiring check for the UART connection.
+package syscon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WiringFault is the most likely wiring problem found by CheckWiring.
+type WiringFault int
+
+// Faults reported by CheckWiring.
+const (
+	WiringOK         WiringFault = iota // a clean, checksummed answer came back
+	WiringWrongMode                     // wiring is fine but the syscon speaks another protocol
+	WiringSilent                        // nothing came back at all
+	WiringEcho                          // the probe came straight back unchanged
+	WiringGarbage                       // unreadable bytes
+	WiringNoise                         // framed answers with bad checksums
+	WiringUnexpected                    // readable text that is not a syscon answer
+)
+
+// String returns a short name for the fault.
+func (f WiringFault) String() string {
+	switch f {
+	case WiringOK:
+		return "OK"
+	case WiringWrongMode:
+		return "wrong mode"
+	case WiringSilent:
+		return "no response"
+	case WiringEcho:
+		return "echo"
+	case WiringGarbage:
+		return "garbage"
+	case WiringNoise:
+		return "line noise"
+	default:
+		return "unexpected reply"
+	}
+}
+
+// WiringReport is the outcome of CheckWiring.
+type WiringReport struct {
+	Fault    WiringFault
+	SCType   string // protocol that answered, if any
+	Idle     string // bytes received before anything was sent
+	Probe    string // last probe sent
+	Reply    string // reply to that probe
+	Summary  string // one-line finding in plain language
+	Advice   []string
+	BaudRate int
+}
+
+// String formats the report for the terminal.
+func (r WiringReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Wiring check at %d baud: %s\n", r.BaudRate, r.Fault)
+	b.WriteString(r.Summary + "\n")
+	for _, a := range r.Advice {
+		b.WriteString("  - " + a + "\n")
+	}
+	if r.Probe != "" {
+		fmt.Fprintf(&b, "Sent:     %q\n", r.Probe)
+		fmt.Fprintf(&b, "Received: %q\n", r.Reply)
+	}
+	if r.Idle != "" {
+		fmt.Fprintf(&b, "Idle:     %q\n", r.Idle)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// wiringIdleWindow is how long CheckWiring listens before sending.
+// A connected, idle syscon is quiet.
+const wiringIdleWindow = 200 * time.Millisecond
+
+// CheckWiring listens to port, sends harmless probes and reports the most
+// likely wiring fault in terms of the guide's wiring table. scType is the
+// mode the user selected; its probe is sent first. baudRate is only used
+// in the report.
+func CheckWiring(ctx context.Context, port SerialPort, scType string, baudRate int) (WiringReport, error) {
+	if err := port.SetReadTimeout(50 * time.Millisecond); err != nil {
+		return WiringReport{}, err
+	}
+	uart := NewPS3UARTWithPort(port, scType, baudRate)
+	report := WiringReport{BaudRate: baudRate}
+
+	report.Idle = uart.receiveUntil(ctx, wiringIdleWindow, func(string) bool { return false })
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	for _, probe := range wiringProbes(scType) {
+		if err := uart.send(ctx, probe.line); err != nil {
+			return report, err
+		}
+		report.Probe = probe.line
+		report.Reply = uart.receiveUntil(ctx, probeTimeout, probe.complete)
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if err := uart.takeIOError(); err != nil {
+			return report, fmt.Errorf("%w: %v", ErrConnectionLost, err)
+		}
+		if report.Reply != "" {
+			break
+		}
+	}
+
+	diagnoseWiring(&report, scType)
+	return report, nil
+}
+
+// wiringProbes returns the detection probes with scType's probe first.
+func wiringProbes(scType string) []detectProbe {
+	types := []string{"CXR", "SW", "CXRF"}
+	probes := detectProbes()
+	ordered := make([]detectProbe, 0, len(probes))
+	for i, t := range types {
+		if t == scType {
+			ordered = append(ordered, probes[i])
+		}
+	}
+	for i, t := range types {
+		if t != scType {
+			ordered = append(ordered, probes[i])
+		}
+	}
+	return ordered
+}
+
+// diagnoseWiring fills in the fault, summary and advice from the replies.
+func diagnoseWiring(r *WiringReport, scType string) {
+	answered, confidence := classifyReply(r.Reply)
+	probe := strings.TrimSpace(r.Probe)
+
+	switch {
+	case confidence == ConfidenceHigh && answered == scType:
+		r.Fault = WiringOK
+		r.SCType = answered
+		r.Summary = "The syscon answered cleanly. RX, TX and GND are connected correctly."
+	case confidence == ConfidenceHigh:
+		r.Fault = WiringWrongMode
+		r.SCType = answered
+		r.Summary = fmt.Sprintf("The wiring works, but the syscon answers in %s mode, not %s.", answered, scType)
+		r.Advice = []string{fmt.Sprintf("Select %s in the Mode list, or use Detect.", answered)}
+	case r.Reply == "" && r.Idle == "":
+		r.Fault = WiringSilent
+		r.Summary = "Nothing came back. The syscon is not hearing us or we are not hearing it."
+		r.Advice = []string{
+			"Check the crossover: cable TX goes to motherboard RxD and cable RX goes to motherboard TxD. If in doubt, swap RX and TX.",
+			"Check the GND wire goes to a motherboard ground.",
+			"Make sure the PS3 is plugged in so the syscon has standby power.",
+		}
+	case probe != "" && strings.TrimSpace(r.Reply) == probe:
+		r.Fault = WiringEcho
+		r.Summary = "Everything we sent came straight back. Cable TX and RX are shorted together."
+		r.Advice = []string{
+			"Look for a solder bridge between the RxD and TxD pads, or bare wire ends touching.",
+			"Check cable TX and RX are not both soldered to the same pad.",
+		}
+	case !printable(r.Reply) || !printable(r.Idle):
+		r.Fault = WiringGarbage
+		r.Summary = "Unreadable bytes came back. The signal is there but cannot be decoded."
+		r.Advice = []string{
+			"Check the GND wire first: without a common ground the line floats and produces garbage.",
+			"Use a 3.3V USB TTL cable. A 5V cable gives noise and can damage the syscon.",
+			"Try the other baud rate, or use Detect: CXR and SW use 57600, CXRF uses 115200.",
+		}
+	case confidence == ConfidenceMedium:
+		r.Fault = WiringNoise
+		r.SCType = answered
+		r.Summary = fmt.Sprintf("The syscon answers in %s mode but the replies fail their checksum. The line is noisy.", answered)
+		r.Advice = []string{
+			"Keep the wires short, under 15cm.",
+			"Re-solder RxD, TxD and GND; a cold joint on GND often causes this.",
+			"Use a 3.3V USB TTL cable, not 5V.",
+		}
+	default:
+		r.Fault = WiringUnexpected
+		r.Summary = "Readable text came back, but it is not a syscon answer."
+		r.Advice = []string{
+			"Check the selected serial port is the USB TTL cable.",
+			"Check DIAG: it must be grounded for CXRF and left disconnected for CXR.",
+		}
+	}
+}
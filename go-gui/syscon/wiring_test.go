@@ -0,0 +1,102 @@
+package syscon
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loopbackPort returns every written byte, like a cable with TX and RX shorted.
+type loopbackPort struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (l *loopbackPort) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := copy(p, l.buf)
+	l.buf = l.buf[n:]
+	return n, nil
+}
+
+func (l *loopbackPort) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf = append(l.buf, p...)
+	return len(p), nil
+}
+
+func (l *loopbackPort) Close() error                       { return nil }
+func (l *loopbackPort) SetReadTimeout(time.Duration) error { return nil }
+
+func TestCheckWiring(t *testing.T) {
+	wrongBaud := NewEmulator(EmulatorConfig{Type: "CXR", BaudRate: 115200})
+	wrongBaud.SetBaudRate(57600)
+
+	tests := []struct {
+		name   string
+		port   SerialPort
+		scType string
+		want   WiringFault
+	}{
+		{"clean", NewEmulator(EmulatorConfig{Type: "CXR"}), "CXR", WiringOK},
+		{"clean shell", NewEmulator(EmulatorConfig{Type: "CXRF"}), "CXRF", WiringOK},
+		{"wrong mode", NewEmulator(EmulatorConfig{Type: "SW"}), "CXR", WiringWrongMode},
+		{"silent", &MockSerialPort{}, "CXR", WiringSilent},
+		{"echo", &loopbackPort{}, "CXR", WiringEcho},
+		{"garbage", wrongBaud, "CXR", WiringGarbage},
+		{"noise", NewEmulator(EmulatorConfig{Type: "CXR", Faults: EmulatorFaults{CorruptEvery: 1}}), "CXR", WiringNoise},
+		{"unexpected", &MockSerialPort{ReadData: []byte("login: ")}, "CXR", WiringUnexpected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := CheckWiring(context.Background(), tt.port, tt.scType, 57600)
+			if err != nil {
+				t.Fatalf("CheckWiring: %v", err)
+			}
+			if report.Fault != tt.want {
+				t.Errorf("Fault = %v, want %v\n%s", report.Fault, tt.want, report)
+			}
+			if report.Summary == "" {
+				t.Error("Summary is empty")
+			}
+		})
+	}
+}
+
+func TestCheckWiringEchoAdvice(t *testing.T) {
+	report, err := CheckWiring(context.Background(), &loopbackPort{}, "CXR", 57600)
+	if err != nil {
+		t.Fatalf("CheckWiring: %v", err)
+	}
+
+	text := report.String()
+	for _, want := range []string{"57600 baud", "shorted", "RxD and TxD"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestCheckWiringCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := CheckWiring(ctx, &MockSerialPort{}, "CXR", 57600); err != context.Canceled {
+		t.Errorf("CheckWiring error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestWiringProbesOrder(t *testing.T) {
+	probes := wiringProbes("CXRF")
+	if probes[0].line != "\r\n" {
+		t.Errorf("first probe for CXRF = %q, want the shell newline", probes[0].line)
+	}
+	if len(probes) != 3 {
+		t.Errorf("len(probes) = %d, want 3", len(probes))
+	}
+}
@@ -11,13 +11,17 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-// SetupMainMenu configures the application menu with About dialog.
+// SetupMainMenu configures the application menu with the error code
+// lookup and About dialog.
 func SetupMainMenu(myApp fyne.App, myWindow fyne.Window) {
+	lookupItem := fyne.NewMenuItem("Error Code Lookup", func() {
+		ShowErrorLookupWindow(myApp)
+	})
 	aboutItem := fyne.NewMenuItem("About", func() {
 		ShowAboutDialog(myWindow)
 	})
 
-	helpMenu := fyne.NewMenu("Help", aboutItem)
+	helpMenu := fyne.NewMenu("Help", lookupItem, aboutItem)
 	mainMenu := fyne.NewMainMenu(helpMenu)
 	myWindow.SetMainMenu(mainMenu)
 }
@@ -0,0 +1,163 @@
+// Package ui provides the argument inputs of the command card.
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// argFields shows the arguments of the selected command: one labelled
+// input per argument when the catalog defines them, otherwise a single
+// free-text entry.
+type argFields struct {
+	box    *fyne.Container
+	free   *widget.Entry
+	schema []syscon.Arg
+	known  bool
+
+	entries   []*widget.Entry
+	errLabels []*widget.Label
+
+	// onSubmit runs when Enter is pressed in any input
+	onSubmit func()
+}
+
+// argSchema returns the argument definitions of cmd or of its subcommand
+// sub, and whether they are known.
+func argSchema(cmd *syscon.Command, sub string) ([]syscon.Arg, bool) {
+	if cmd == nil {
+		return nil, false
+	}
+	return cmd.ArgSchema(sub)
+}
+
+// newArgFields returns argument inputs showing the free-text entry.
+func newArgFields() *argFields {
+	f := &argFields{box: container.NewVBox()}
+	f.free = widget.NewEntry()
+	f.free.SetPlaceHolder("Arguments")
+	f.free.OnSubmitted = func(string) { f.submit() }
+	f.setSchema(nil, false)
+	return f
+}
+
+func (f *argFields) submit() {
+	if f.onSubmit != nil {
+		f.onSubmit()
+	}
+}
+
+// setSchema replaces the inputs with those for schema. Without a known
+// schema the free-text entry is shown.
+func (f *argFields) setSchema(schema []syscon.Arg, known bool) {
+	f.schema, f.known = schema, known
+	f.entries, f.errLabels = nil, nil
+	f.free.SetText("")
+
+	switch {
+	case !known:
+		f.box.Objects = []fyne.CanvasObject{widget.NewLabel("Arguments"), f.free}
+	case len(schema) == 0:
+		f.box.Objects = []fyne.CanvasObject{widget.NewLabel("Arguments"), widget.NewLabel("None")}
+	default:
+		columns := make([]fyne.CanvasObject, len(schema))
+		for i, arg := range schema {
+			entry := widget.NewEntry()
+			entry.SetPlaceHolder(arg.Hint())
+			errLabel := widget.NewLabel("")
+			errLabel.Importance = widget.DangerImportance
+			errLabel.Wrapping = fyne.TextWrapWord
+			errLabel.Hide()
+
+			// Re-check as the user types, but only flag what has been entered
+			entry.OnChanged = func(string) { f.checkField(i, false) }
+			entry.OnSubmitted = func(string) { f.submit() }
+
+			label := arg.Name
+			if arg.Optional {
+				label += " (optional)"
+			}
+			columns[i] = container.NewVBox(widget.NewLabel(label), entry, errLabel)
+			f.entries = append(f.entries, entry)
+			f.errLabels = append(f.errLabels, errLabel)
+		}
+		f.box.Objects = []fyne.CanvasObject{container.NewGridWithColumns(len(columns), columns...)}
+	}
+	f.box.Refresh()
+}
+
+// fieldValues splits the text of input i into values; a repeating
+// argument takes several values separated by spaces.
+func (f *argFields) fieldValues(i int) []string {
+	if f.schema[i].Repeat {
+		return strings.Fields(f.entries[i].Text)
+	}
+	if text := strings.TrimSpace(f.entries[i].Text); text != "" {
+		return []string{text}
+	}
+	return nil
+}
+
+// checkField validates input i and shows or clears its inline error. An
+// empty input is only an error when required is set.
+func (f *argFields) checkField(i int, required bool) bool {
+	arg := f.schema[i]
+	values := f.fieldValues(i)
+	var err error
+	if len(values) == 0 && required && !arg.Optional {
+		err = &syscon.ArgError{Arg: arg.Name, Reason: "missing"}
+	}
+	for _, v := range values {
+		if err = arg.Check(v); err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		f.errLabels[i].Hide()
+		return true
+	}
+	msg := err.Error()
+	if argErr := (*syscon.ArgError)(nil); errors.As(err, &argErr) {
+		msg = argErr.Reason
+	}
+	f.errLabels[i].SetText(msg)
+	f.errLabels[i].Show()
+	return false
+}
+
+// text validates every input, showing the errors inline, and returns the
+// arguments joined for the command line. ok is false if any input is
+// invalid.
+func (f *argFields) text() (args string, ok bool) {
+	if !f.known {
+		return strings.TrimSpace(f.free.Text), true
+	}
+
+	ok = true
+	var values []string
+	for i := range f.entries {
+		if !f.checkField(i, true) {
+			ok = false
+		}
+		values = append(values, f.fieldValues(i)...)
+	}
+	return strings.Join(values, " "), ok
+}
+
+// first returns the input that should take focus.
+func (f *argFields) first() fyne.Focusable {
+	if !f.known {
+		return f.free
+	}
+	if len(f.entries) > 0 {
+		return f.entries[0]
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"testing"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestArgFieldsFreeText(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	f := newArgFields()
+	f.free.SetText("  3961 01 ")
+	if args, ok := f.text(); !ok || args != "3961 01" {
+		t.Errorf("text() = %q, %v; want the typed arguments", args, ok)
+	}
+	if f.first() != f.free {
+		t.Error("free-text entry does not take focus")
+	}
+}
+
+func TestArgFieldsSchema(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	f := newArgFields()
+	f.setSchema([]syscon.Arg{
+		{Name: "offset", Type: syscon.ArgHex, Width: 4},
+		{Name: "value", Type: syscon.ArgHexByte, Repeat: true},
+	}, true)
+	if len(f.entries) != 2 {
+		t.Fatalf("%d inputs, want 2", len(f.entries))
+	}
+	if f.entries[0].PlaceHolder != "4 hex digits" {
+		t.Errorf("offset placeholder = %q", f.entries[0].PlaceHolder)
+	}
+
+	// Errors show as the user types, except for inputs left empty
+	f.entries[0].SetText("396")
+	if !f.errLabels[0].Visible() || f.errLabels[0].Text != "want 4 hex digits" {
+		t.Errorf("offset error = %q, visible %v", f.errLabels[0].Text, f.errLabels[0].Visible())
+	}
+	if f.errLabels[1].Visible() {
+		t.Error("empty value flagged before sending")
+	}
+
+	if _, ok := f.text(); ok {
+		t.Error("text() accepted a 3-digit offset and a missing value")
+	}
+	if !f.errLabels[1].Visible() || f.errLabels[1].Text != "missing" {
+		t.Errorf("value error = %q, want missing", f.errLabels[1].Text)
+	}
+
+	f.entries[0].SetText("39fe")
+	f.entries[1].SetText("38 00")
+	args, ok := f.text()
+	if !ok || args != "39fe 38 00" {
+		t.Errorf("text() = %q, %v", args, ok)
+	}
+	if f.errLabels[0].Visible() || f.errLabels[1].Visible() {
+		t.Error("errors still shown for valid arguments")
+	}
+
+	f.entries[1].SetText("38 255")
+	if _, ok := f.text(); ok {
+		t.Error("text() accepted a decimal byte")
+	}
+}
+
+func TestArgFieldsNoArguments(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	f := newArgFields()
+	f.setSchema([]syscon.Arg{}, true)
+	if args, ok := f.text(); !ok || args != "" {
+		t.Errorf("text() = %q, %v; want no arguments", args, ok)
+	}
+	if f.first() != nil {
+		t.Error("command without arguments has an input to focus")
+	}
+}
@@ -0,0 +1,59 @@
+// Package ui provides the traffic capture toggle.
+package ui
+
+import (
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// RecorderFactory creates a capture file. kind names what is recorded,
+// such as "session" or "monitor", and is used in the file name.
+type RecorderFactory func(kind string) (*syscon.Recorder, error)
+
+// CreateCaptureToggle returns a "Record traffic" check box with a label
+// showing the capture file. Ticking it creates a recorder and passes it to
+// apply; unticking passes nil and closes the file. The returned stop
+// function does the same and is meant for when the window closes.
+func CreateCaptureToggle(parent fyne.Window, kind string, newRecorder RecorderFactory, apply func(*syscon.Recorder)) (fyne.CanvasObject, func()) {
+	pathLabel := widget.NewLabel("")
+	pathLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	pathLabel.Truncation = fyne.TextTruncateEllipsis
+	pathLabel.Hide()
+
+	var recorder *syscon.Recorder
+	stop := func() {
+		if recorder == nil {
+			return
+		}
+		apply(nil)
+		if err := recorder.Close(); err != nil {
+			dialog.ShowError(err, parent)
+		}
+		recorder = nil
+		pathLabel.Hide()
+	}
+
+	var check *widget.Check
+	check = widget.NewCheck("Record traffic", func(on bool) {
+		if !on {
+			stop()
+			return
+		}
+		rec, err := newRecorder(kind)
+		if err != nil {
+			dialog.ShowError(err, parent)
+			check.SetChecked(false)
+			return
+		}
+		recorder = rec
+		apply(rec)
+		pathLabel.SetText(rec.Path())
+		pathLabel.Show()
+	})
+
+	return container.NewBorder(nil, nil, check, nil, pathLabel), stop
+}
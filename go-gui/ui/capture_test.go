@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+// findCheck returns the first check box under obj.
+func findCheck(obj fyne.CanvasObject) *widget.Check {
+	found := findObject(obj, func(o fyne.CanvasObject) bool {
+		_, ok := o.(*widget.Check)
+		return ok
+	})
+	if found == nil {
+		return nil
+	}
+	return found.(*widget.Check)
+}
+
+func TestCreateCaptureToggle(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	window := app.NewWindow("Test")
+
+	var applied []*syscon.Recorder
+	var gotKind string
+	newRecorder := func(kind string) (*syscon.Recorder, error) {
+		gotKind = kind
+		return syscon.NewRecorder(io.Discard)
+	}
+
+	toggle, stop := CreateCaptureToggle(window, "session", newRecorder, func(rec *syscon.Recorder) {
+		applied = append(applied, rec)
+	})
+
+	check := findCheck(toggle)
+	test.Tap(check)
+	if gotKind != "session" {
+		t.Errorf("recorder kind = %q, want session", gotKind)
+	}
+	if len(applied) != 1 || applied[0] == nil {
+		t.Fatalf("applied = %v, want one recorder", applied)
+	}
+
+	test.Tap(check)
+	if len(applied) != 2 || applied[1] != nil {
+		t.Errorf("applied = %v, want recording stopped", applied)
+	}
+
+	// stop is a no-op once recording has ended
+	stop()
+	if len(applied) != 2 {
+		t.Errorf("stop applied again: %v", applied)
+	}
+}
+
+func TestCreateCaptureToggleError(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	window := app.NewWindow("Test")
+
+	applied := false
+	newRecorder := func(kind string) (*syscon.Recorder, error) {
+		return nil, errors.New("read-only file system")
+	}
+
+	toggle, _ := CreateCaptureToggle(window, "monitor", newRecorder, func(*syscon.Recorder) { applied = true })
+
+	check := findCheck(toggle)
+	test.Tap(check)
+	if check.Checked {
+		t.Error("check box stayed ticked after the recorder failed")
+	}
+	if applied {
+		t.Error("apply called although no recorder was created")
+	}
+}
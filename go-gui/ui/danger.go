@@ -0,0 +1,79 @@
+// Package ui provides the confirmation for destructive commands and the
+// read-only mode report.
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// wireBytesLabel returns a monospace label with the bytes sent for cmdText.
+func wireBytesLabel(scType, cmdText string) *widget.Label {
+	label := widget.NewLabel(FormatWireBytes(syscon.FrameCommand(scType, cmdText)))
+	label.TextStyle = fyne.TextStyle{Monospace: true}
+	label.Wrapping = fyne.TextWrapBreak
+	return label
+}
+
+// confirmLine returns what the user types to confirm cmdText: the whole
+// command line with its arguments, so a wrong offset or value is seen
+// before it is written. Runs of spaces count as one.
+func confirmLine(cmdText string) string {
+	return strings.Join(strings.Fields(cmdText), " ")
+}
+
+// destructiveForm returns the confirmation form for cmdText and the entry
+// where the command line must be typed.
+func destructiveForm(scType, cmdText string) ([]*widget.FormItem, *widget.Entry) {
+	line := confirmLine(cmdText)
+	entry := widget.NewEntry()
+	entry.Validator = func(s string) error {
+		if confirmLine(s) != line {
+			return errors.New("type the whole command line to confirm")
+		}
+		return nil
+	}
+
+	return []*widget.FormItem{
+		widget.NewFormItem("Command", widget.NewLabel(cmdText+" can brick the board.")),
+		widget.NewFormItem("Bytes", wireBytesLabel(scType, cmdText)),
+		widget.NewFormItem(fmt.Sprintf("Type %q", line), entry),
+	}, entry
+}
+
+// confirmDestructive runs send at once for commands that are not
+// destructive. A destructive command is shown with the exact bytes that
+// will go out, and send only runs once the whole command line has been
+// typed.
+func confirmDestructive(parent fyne.Window, scType, cmdText string, send func()) {
+	if syscon.CommandDanger(scType, cmdText) != syscon.DangerDestructive {
+		send()
+		return
+	}
+
+	items, _ := destructiveForm(scType, cmdText)
+	dialog.ShowForm("Destructive Command", "Send", "Cancel", items, func(ok bool) {
+		if ok {
+			send()
+		}
+	}, parent)
+}
+
+// showReadOnlyRefusal explains why cmdText was not sent in read-only mode,
+// with the bytes it would have sent.
+func showReadOnlyRefusal(parent fyne.Window, scType, cmdText string, err error) {
+	content := container.NewVBox(
+		widget.NewLabel(err.Error()),
+		widget.NewLabel("Not sent:"),
+		wireBytesLabel(scType, cmdText),
+	)
+	dialog.ShowCustom("Read-Only Mode", "Close", content, parent)
+}
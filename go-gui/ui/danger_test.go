@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestConfirmDestructiveSendsSafeCommands(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	window := app.NewWindow("Test")
+
+	for _, line := range []string{"errlog", "clear_err last"} {
+		sent := false
+		confirmDestructive(window, "CXRF", line, func() { sent = true })
+		if !sent {
+			t.Errorf("%q was not sent without confirmation", line)
+		}
+	}
+
+	sent := false
+	confirmDestructive(window, "CXRF", "eeprominit", func() { sent = true })
+	if sent {
+		t.Error("eeprominit was sent without confirmation")
+	}
+}
+
+func TestDestructiveForm(t *testing.T) {
+	items, entry := destructiveForm("CXR", "EEP SET 3961 01 FF")
+	if len(items) != 3 {
+		t.Fatalf("form has %d items, want 3", len(items))
+	}
+
+	bytes := items[1].Widget.(*widget.Label).Text
+	if !strings.Contains(bytes, `"C:`) || !strings.Contains(bytes, ":EEP SET 3961 01 FF\\r\\n\"") {
+		t.Errorf("bytes = %q, want the framed CXR line", bytes)
+	}
+
+	for text, ok := range map[string]bool{
+		"":                     false,
+		"EEP":                  false,
+		"EEP SET 3961 01":      false,
+		"EEP SET 3962 01 FF":   false,
+		"eep set 3961 01 ff":   false,
+		"EEP SET 3961 01 FF":   true,
+		" EEP SET  3961 01 FF": true,
+	} {
+		if err := entry.Validator(text); (err == nil) != ok {
+			t.Errorf("typing %q: %v, want ok %v", text, err, ok)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Package ui provides the eepcsum table and the checksum repair preview.
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// FormatEepcsum lays out the regions reported by eepcsum as a table with
+// the command that repairs each bad one.
+func FormatEepcsum(regions []syscon.ChecksumRegion) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ADDR\tEXPECTED\tSTATUS\tFIX")
+	for _, r := range regions {
+		status, fix := "ok", "-"
+		if r.Bad {
+			status, fix = "bad", r.FixCommand()
+		}
+		fmt.Fprintf(tw, "%04X\t%04X\t%s\t%s\n", r.Addr, r.Expected, status, fix)
+	}
+	tw.Flush()
+
+	if n := len(syscon.BadChecksums(regions)); n > 0 {
+		fmt.Fprintf(&b, "\n%d bad checksum(s)", n)
+	} else {
+		b.WriteString("\nAll checksums OK")
+	}
+	return b.String()
+}
+
+// checksumRepairContent previews the w commands that repair the bad
+// regions, with the exact bytes each one sends.
+func checksumRepairContent(scType string, regions []syscon.ChecksumRegion) fyne.CanvasObject {
+	bad := syscon.BadChecksums(regions)
+	intro := widget.NewLabel(fmt.Sprintf(
+		"eepcsum reports %d bad checksum(s). These writes store the expected values, low byte first, then eepcsum runs again to verify them.",
+		len(bad)))
+	intro.Wrapping = fyne.TextWrapWord
+
+	form := widget.NewForm()
+	for _, r := range bad {
+		form.Append(fmt.Sprintf("0x%04X = 0x%04X", r.Addr, r.Expected), wireBytesLabel(scType, r.FixCommand()))
+	}
+	return container.NewVBox(intro, form)
+}
+
+// confirmChecksumRepair shows the repair preview and runs repair once the
+// writes are confirmed.
+func confirmChecksumRepair(parent fyne.Window, scType string, regions []syscon.ChecksumRegion, repair func()) {
+	content := checksumRepairContent(scType, regions)
+	d := dialog.NewCustomConfirm("Repair EEPROM Checksums", "Write", "Cancel", content, func(ok bool) {
+		if ok {
+			repair()
+		}
+	}, parent)
+	d.Resize(fyne.NewSize(560, 0))
+	d.Show()
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+const testEepcsum = "Addr:0x000032fe should be 0x528c\nsum:0x0100\nAddr:0x000039fe should be 0x0038\n"
+
+func TestFormatEepcsum(t *testing.T) {
+	got := FormatEepcsum(syscon.ParseEepcsum(testEepcsum))
+	for _, want := range []string{
+		"ADDR  EXPECTED  STATUS  FIX",
+		"32FE  528C      ok      -",
+		"39FE  0038      bad     w 39FE 38 00",
+		"1 bad checksum(s)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("table lacks %q:\n%s", want, got)
+		}
+	}
+
+	if got := FormatEepcsum(syscon.ParseEepcsum("Addr:0x000039fe should be 0x0038")); !strings.HasSuffix(got, "All checksums OK") {
+		t.Errorf("good eepcsum:\n%s", got)
+	}
+}
+
+func TestChecksumRepairContent(t *testing.T) {
+	content := checksumRepairContent("CXRF", syscon.ParseEepcsum(testEepcsum)).(*fyne.Container)
+	form := content.Objects[1].(*widget.Form)
+	if len(form.Items) != 1 {
+		t.Fatalf("preview lists %d writes, want 1", len(form.Items))
+	}
+	if form.Items[0].Text != "0x39FE = 0x0038" {
+		t.Errorf("write label = %q", form.Items[0].Text)
+	}
+	if bytes := form.Items[0].Widget.(*widget.Label).Text; !strings.Contains(bytes, `"w 39FE 38 00\r\n"`) {
+		t.Errorf("bytes = %q, want the CXRF line", bytes)
+	}
+}
@@ -0,0 +1,120 @@
+// Package ui provides the EEPROM dump dialog.
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// DumpPathFactory returns the path of a new .bin dump file for scType;
+// the JSON sidecar is written next to it.
+type DumpPathFactory func(scType string) (string, error)
+
+// eepromDump reads the EEPROM in the background with a progress bar and
+// offers to resume from the chunk that failed.
+type eepromDump struct {
+	dumper *syscon.EEPROMDumper
+
+	run          func(work func(ctx context.Context) error, done func(err error)) // runs work in the background
+	send         func(ctx context.Context, cmd string) (syscon.CommandResult, error)
+	authenticate func(ctx context.Context) error // authenticates unless already done
+	newPath      func() (string, error)          // path of the .bin file
+	logResult    func(text string)               // reports the outcome of a run
+
+	content fyne.CanvasObject
+	bar     *widget.ProgressBar
+	status  *widget.Label
+	runBtn  *widget.Button
+}
+
+// newEEPROMDump builds the dialog content for dumper.
+func newEEPROMDump(dumper *syscon.EEPROMDumper) *eepromDump {
+	v := &eepromDump{dumper: dumper}
+
+	intro := widget.NewLabel(fmt.Sprintf(
+		"Reads the whole EEPROM (0x%04X bytes) in %s mode and writes it to a .bin file with a .json sidecar holding the board ID, firmware version and a CRC-32 of every chunk.",
+		syscon.EEPROMSize, dumper.Info().Mode))
+	intro.Wrapping = fyne.TextWrapWord
+
+	v.bar = widget.NewProgressBar()
+	v.status = widget.NewLabel("")
+	v.status.Wrapping = fyne.TextWrapWord
+	v.runBtn = widget.NewButton("Start", v.start)
+	v.runBtn.Importance = widget.HighImportance
+
+	v.content = container.NewVBox(intro, v.bar, v.status, container.NewHBox(v.runBtn))
+	v.showProgress()
+	return v
+}
+
+// showProgress reflects the bytes read so far.
+func (v *eepromDump) showProgress() {
+	done, total := v.dumper.Progress()
+	v.bar.SetValue(float64(done) / float64(total))
+	v.status.SetText(fmt.Sprintf("0x%04X of 0x%04X bytes read", done, total))
+}
+
+// start reads the remaining chunks, or saves a dump that is already
+// complete but could not be written.
+func (v *eepromDump) start() {
+	v.runBtn.Disable()
+	if v.dumper.Done() {
+		v.save()
+		return
+	}
+
+	v.run(func(ctx context.Context) error {
+		if err := v.authenticate(ctx); err != nil {
+			return err
+		}
+		return v.dumper.Run(ctx, v.send, func(done, total int) {
+			fyne.Do(v.showProgress)
+		})
+	}, func(err error) {
+		if err == nil {
+			v.save()
+			return
+		}
+		done, _ := v.dumper.Progress()
+		msg := fmt.Sprintf("Failed: %v", err)
+		if errors.Is(err, context.Canceled) {
+			msg = "Cancelled"
+		}
+		msg += fmt.Sprintf("\nResume continues at 0x%04X.", done)
+		v.status.SetText(msg)
+		v.logResult(msg)
+		v.runBtn.SetText("Resume")
+		v.runBtn.Enable()
+	})
+}
+
+// save writes the finished dump and its sidecar.
+func (v *eepromDump) save() {
+	binPath, err := v.newPath()
+	var jsonPath string
+	if err == nil {
+		jsonPath, err = v.dumper.Save(binPath)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Saving the dump failed: %v", err)
+		v.status.SetText(msg)
+		v.logResult(msg)
+		v.runBtn.SetText("Save")
+		v.runBtn.Enable()
+		return
+	}
+
+	info := v.dumper.Info()
+	msg := fmt.Sprintf("Saved 0x%04X bytes to %s\nSidecar: %s\nSHA-256 %s", info.Size, binPath, jsonPath, info.SHA256)
+	v.bar.SetValue(1)
+	v.status.SetText(msg)
+	v.logResult(msg)
+	v.runBtn.SetText("Done")
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2/test"
+)
+
+// emulatorSend authenticates an emulated console in scType and returns
+// its command function.
+func emulatorSend(t *testing.T, scType string) func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+	t.Helper()
+	emu := syscon.NewEmulator(syscon.EmulatorConfig{Type: scType})
+	if err := emu.SetReadTimeout(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	uart := syscon.NewPS3UARTWithPort(emu, scType, 57600)
+	if err := uart.Auth(); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+	return uart.CommandContext
+}
+
+func TestEEPROMDumpResumes(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	send := emulatorSend(t, "CXRF")
+	fail := true
+	dumper, err := syscon.NewEEPROMDumper("CXRF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	binPath := filepath.Join(t.TempDir(), "eeprom.bin")
+
+	var log []string
+	v := newEEPROMDump(dumper)
+	v.run = func(work func(ctx context.Context) error, done func(err error)) {
+		done(work(context.Background()))
+	}
+	v.send = func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+		if fail && cmd == "r 0200 100" {
+			fail = false
+			return syscon.CommandResult{}, &syscon.ResponseError{Err: syscon.ErrNoResponse}
+		}
+		return send(ctx, cmd)
+	}
+	v.authenticate = func(ctx context.Context) error { return nil }
+	v.newPath = func() (string, error) { return binPath, nil }
+	v.logResult = func(text string) { log = append(log, text) }
+
+	test.Tap(v.runBtn)
+	if v.runBtn.Text != "Resume" || !strings.Contains(v.status.Text, "Resume continues at 0x0200") {
+		t.Fatalf("failed chunk: button %q, status %q", v.runBtn.Text, v.status.Text)
+	}
+	if v.bar.Value != 0x200/float64(syscon.EEPROMSize) {
+		t.Errorf("progress = %v after two chunks", v.bar.Value)
+	}
+
+	test.Tap(v.runBtn)
+	if v.runBtn.Text != "Done" || !strings.Contains(v.status.Text, "Saved 0x4000 bytes to "+binPath) {
+		t.Fatalf("resumed dump: button %q, status %q", v.runBtn.Text, v.status.Text)
+	}
+	if len(log) != 2 {
+		t.Errorf("log = %q, want the failure and the save", log)
+	}
+	for _, path := range []string{binPath, strings.TrimSuffix(binPath, ".bin") + ".json"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Error(err)
+		}
+	}
+}
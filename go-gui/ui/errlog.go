@@ -0,0 +1,67 @@
+// Package ui provides the decoded error log table of the output pane and
+// the error code descriptions of the lookup window.
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"ps3syscon-gui/syscon"
+)
+
+// FormatErrorLog lays out decoded error log entries as a table, one row
+// per entry, followed by the repair hints of the entries that have one.
+func FormatErrorLog(entries []syscon.ErrorLogEntry) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tCODE\tPHASE\tCATEGORY\tDETAIL\tTIME\tDESCRIPTION")
+	var hints []string
+	for _, e := range entries {
+		index, time := "-", "-"
+		if e.Index >= 0 {
+			index = fmt.Sprintf("%02X", e.Index)
+		}
+		if e.HasTime {
+			time = fmt.Sprintf("%08X", e.Time)
+		}
+		desc := "Unknown error code"
+		if e.Known {
+			desc = e.Info.Description
+			if !e.Exact {
+				desc += " (as " + e.Info.Code + ")"
+			}
+			if e.Info.Hint != "" {
+				hints = append(hints, fmt.Sprintf("%s: %s", e.Code, e.Info.Hint))
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%03X\t%s\t%s\n",
+			index, e.Code, e.Code.Phase(), e.Code.Category, e.Code.Detail, time, desc)
+	}
+	tw.Flush()
+
+	if len(hints) > 0 {
+		b.WriteString("\nHints:\n")
+		for _, h := range hints {
+			b.WriteString("  " + h + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// FormatErrorInfo describes a known error code for the lookup window: its
+// fields, description and the checks suggested by its hint.
+func FormatErrorInfo(info syscon.ErrorInfo) string {
+	var b strings.Builder
+	b.WriteString(info.Code + " - " + info.Description + "\n")
+	if code, err := syscon.ParseErrorCode(info.Code); err == nil {
+		fmt.Fprintf(&b, "\nCategory: %s\nPhase: %s\nDetail: %03X\n", code.Category, code.Phase(), code.Detail)
+	}
+	if info.Hint != "" {
+		b.WriteString("\nSuggested checks:\n")
+		for _, check := range strings.Split(info.Hint, "; ") {
+			b.WriteString("  - " + check + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"ps3syscon-gui/syscon"
+)
+
+func TestFormatErrorLog(t *testing.T) {
+	entries := syscon.ParseErrorLog("00: A0801200 00003E80\n01: A0A01200 00005DC0\nA0409999")
+	got := FormatErrorLog(entries)
+	lines := strings.Split(got, "\n")
+
+	if !strings.HasPrefix(lines[0], "#") || !strings.Contains(lines[0], "DESCRIPTION") {
+		t.Errorf("header = %q", lines[0])
+	}
+	for _, want := range []string{
+		"00  A0801200  power on",
+		"system error",
+		"CELL overheating",
+		"(as A0801200)",
+		"-   A0409999",
+		"Unknown error code",
+		"Hints:",
+		"A0801200: Poor thermal paste",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("table lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "A0801200: Poor thermal paste") != 1 || strings.Count(got, "A0A01200: Poor thermal paste") != 1 {
+		t.Errorf("want one hint per entry:\n%s", got)
+	}
+}
@@ -0,0 +1,109 @@
+// Package ui provides the error code lookup window.
+package ui
+
+import (
+	"fmt"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// errorLookup is the content of the lookup window: a search box over the
+// built-in error codes, the matching codes and the selected one's details
+// with buttons for its related codes.
+type errorLookup struct {
+	content fyne.CanvasObject
+	search  *widget.Entry
+	list    *widget.List
+	detail  *widget.Label
+	related *fyne.Container
+	results []syscon.ErrorInfo
+}
+
+// newErrorLookup builds the lookup content listing every known code.
+func newErrorLookup() *errorLookup {
+	l := &errorLookup{}
+
+	l.search = widget.NewEntry()
+	l.search.SetPlaceHolder("Code, partial code or keyword (VRAM, tokin)...")
+
+	l.list = widget.NewList(
+		func() int { return len(l.results) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			info := l.results[id]
+			o.(*widget.Label).SetText(fmt.Sprintf("%s  %s", info.Code, info.Description))
+		},
+	)
+	l.list.OnSelected = func(id widget.ListItemID) {
+		l.show(l.results[id])
+	}
+
+	l.detail = widget.NewLabel("")
+	l.detail.Wrapping = fyne.TextWrapWord
+	l.related = container.NewHBox()
+
+	l.search.OnChanged = l.setQuery
+	l.setQuery("")
+
+	split := container.NewHSplit(
+		container.NewBorder(l.search, nil, nil, nil, l.list),
+		container.NewBorder(nil, l.related, nil, nil, container.NewVScroll(l.detail)),
+	)
+	split.Offset = 0.45
+	l.content = split
+	return l
+}
+
+// setQuery lists the codes matching query and shows the first of them.
+func (l *errorLookup) setQuery(query string) {
+	l.results = syscon.SearchErrorCodes(query)
+	l.list.UnselectAll()
+	l.list.Refresh()
+	if len(l.results) == 0 {
+		l.detail.SetText(fmt.Sprintf("No error code matches %q.", query))
+		l.related.Objects = nil
+		l.related.Refresh()
+		return
+	}
+	l.list.Select(0)
+}
+
+// show describes info and offers its related codes; tapping one searches
+// for it.
+func (l *errorLookup) show(info syscon.ErrorInfo) {
+	l.detail.SetText(FormatErrorInfo(info))
+
+	l.related.Objects = nil
+	if len(info.Related) > 0 {
+		l.related.Add(widget.NewLabel("Related:"))
+	}
+	for _, code := range info.Related {
+		l.related.Add(widget.NewButton(code, func() {
+			l.search.SetText(code)
+		}))
+	}
+	l.related.Refresh()
+}
+
+// ShowErrorLookupWindow opens a window to look error codes up by code or
+// keyword.
+func ShowErrorLookupWindow(myApp fyne.App) {
+	lookupWindow := myApp.NewWindow("Error Code Lookup")
+	lookupWindow.Resize(fyne.NewSize(820, 520))
+
+	l := newErrorLookup()
+	bg := canvas.NewRectangle(theme.Color(FTBackground))
+	lookupWindow.SetContent(container.NewStack(bg, container.NewPadded(l.content)))
+	lookupWindow.Canvas().Focus(l.search)
+	lookupWindow.Show()
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestShowErrorLookupWindow(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	ShowErrorLookupWindow(app)
+	if !slices.ContainsFunc(app.Driver().AllWindows(), func(w fyne.Window) bool {
+		return w.Title() == "Error Code Lookup"
+	}) {
+		t.Error("no Error Code Lookup window was opened")
+	}
+}
+
+func TestErrorLookupSearch(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	l := newErrorLookup()
+	if len(l.results) < 20 {
+		t.Errorf("empty search lists %d codes", len(l.results))
+	}
+
+	for _, query := range []string{"VRAM", "tokin", "A0801"} {
+		l.search.SetText(query)
+		if len(l.results) == 0 {
+			t.Errorf("%q found nothing", query)
+		}
+	}
+
+	l.search.SetText("A0403034")
+	if l.results[0].Code != "A0403034" {
+		t.Fatalf("first result %s, want A0403034", l.results[0].Code)
+	}
+	for _, want := range []string{"Category: fatal booting error", "Phase: power-on step 40", "Suggested checks:", "  - Reflow or reball"} {
+		if !strings.Contains(l.detail.Text, want) {
+			t.Errorf("details lack %q:\n%s", want, l.detail.Text)
+		}
+	}
+
+	l.search.SetText("no such thing")
+	if !strings.HasPrefix(l.detail.Text, "No error code matches") || len(l.related.Objects) != 0 {
+		t.Errorf("no match shows %q with %d related", l.detail.Text, len(l.related.Objects))
+	}
+}
+
+func TestErrorLookupRelated(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	l := newErrorLookup()
+	l.search.SetText("A0403034")
+
+	var pair *widget.Button
+	var codes []string
+	for _, o := range l.related.Objects {
+		if b, ok := o.(*widget.Button); ok {
+			codes = append(codes, b.Text)
+			if b.Text == "A0404402" {
+				pair = b
+			}
+		}
+	}
+	if pair == nil || !slices.Contains(codes, "A0404401") {
+		t.Fatalf("related codes %v, want the BitTraining pairs", codes)
+	}
+
+	test.Tap(pair)
+	if l.search.Text != "A0404402" || !strings.HasPrefix(l.detail.Text, "A0404402 - ") {
+		t.Errorf("tapping A0404402 shows %q for %q", l.detail.Text, l.search.Text)
+	}
+}
@@ -0,0 +1,16 @@
+// Package ui provides sentinel errors for input validation.
+package ui
+
+import "errors"
+
+// Sentinel errors for invalid user input.
+var (
+	// ErrPortNotSelected indicates no serial port was selected.
+	ErrPortNotSelected = errors.New("serial port not selected")
+
+	// ErrModeNotSelected indicates no SC mode was selected.
+	ErrModeNotSelected = errors.New("mode not selected")
+
+	// ErrCommandEmpty indicates an empty command was provided.
+	ErrCommandEmpty = errors.New("command is empty")
+)
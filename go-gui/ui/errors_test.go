@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		msg  string
+	}{
+		{"ErrPortNotSelected", ErrPortNotSelected, "serial port not selected"},
+		{"ErrModeNotSelected", ErrModeNotSelected, "mode not selected"},
+		{"ErrCommandEmpty", ErrCommandEmpty, "command is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Error() != tt.msg {
+				t.Errorf("%s.Error() = %q, want %q", tt.name, tt.err.Error(), tt.msg)
+			}
+		})
+	}
+}
+
+func TestErrorsAreDistinct(t *testing.T) {
+	allErrors := []error{ErrPortNotSelected, ErrModeNotSelected, ErrCommandEmpty}
+
+	for i, err1 := range allErrors {
+		for j, err2 := range allErrors {
+			if i != j && errors.Is(err1, err2) {
+				t.Errorf("Error %d and %d should be distinct but are equal", i, j)
+			}
+		}
+	}
+}
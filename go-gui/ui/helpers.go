@@ -3,7 +3,10 @@ package ui
 
 import (
 	"fmt"
+	"image/color"
 	"strings"
+
+	"ps3syscon-gui/syscon"
 )
 
 // FilterOptions filters a list of options based on a search string.
@@ -38,6 +41,65 @@ func BuildCXRCommand(cmd, subCmd, args string) string {
 	return strings.Join(parts, " ")
 }
 
+// CommandHint returns the description of cmd followed by its examples,
+// for the line under the command selection.
+func CommandHint(cmd *syscon.Command) string {
+	if cmd == nil {
+		return ""
+	}
+	hint := cmd.Description
+	if len(cmd.Examples) > 0 {
+		if hint != "" {
+			hint += " - "
+		}
+		hint += "e.g. " + strings.Join(cmd.Examples, ", ")
+	}
+	return hint
+}
+
+// PermissionHint explains the permission mask of cmd and reports whether
+// cmd may run in state. Commands without a mask give an empty hint.
+func PermissionHint(cmd *syscon.Command, state syscon.AuthState) (hint string, allowed bool) {
+	if cmd == nil || cmd.Permission == 0 {
+		return "", true
+	}
+	hint = fmt.Sprintf("Permission 0x%08X: runs %s", cmd.Permission, syscon.DescribePermission(cmd.Permission))
+	switch {
+	case cmd.Allowed(state):
+		return hint, true
+	case state == syscon.AuthAuthenticated:
+		return hint + " - not allowed once authenticated", false
+	default:
+		return hint + " - authenticate first", false
+	}
+}
+
+// DangerHint describes the danger level of the selected command, or
+// returns an empty hint for safe commands.
+func DangerHint(d syscon.Danger) string {
+	switch d {
+	case syscon.DangerDestructive:
+		return "Destructive: can brick the board, asks for a typed confirmation"
+	case syscon.DangerModifying:
+		return "Modifying: changes syscon state, refused in read-only mode"
+	default:
+		return ""
+	}
+}
+
+// FormatWireBytes shows the lines of a framed command as quoted text, with
+// the hex bytes of each line below it.
+func FormatWireBytes(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%q\n% X", line, line)
+	}
+	return b.String()
+}
+
 // GetSerialSpeed returns the appropriate baud rate for the SC type.
 func GetSerialSpeed(scType string) int {
 	if scType == "CXRF" {
@@ -46,15 +108,20 @@ func GetSerialSpeed(scType string) int {
 	return 57600
 }
 
-// CommandResult holds the result of a command execution.
-// This is duplicated here to avoid circular imports with serial package.
-type CommandResult struct {
-	Code uint32
-	Data []string
+// detectionColor returns the colour for a detection of the given confidence.
+func detectionColor(c syscon.Confidence) color.Color {
+	switch c {
+	case syscon.ConfidenceHigh:
+		return ColorSuccess
+	case syscon.ConfidenceNone:
+		return ColorError
+	default:
+		return ColorWarning
+	}
 }
 
 // FormatCommandOutput formats the command result for display.
-func FormatCommandOutput(scType string, result CommandResult) string {
+func FormatCommandOutput(scType string, result syscon.CommandResult) string {
 	switch scType {
 	case "CXR":
 		return fmt.Sprintf("%08X %s", result.Code, strings.Join(result.Data, " "))
@@ -70,3 +137,8 @@ func FormatCommandOutput(scType string, result CommandResult) string {
 		return ""
 	}
 }
+
+// FormatRetry formats a re-send for the output pane.
+func FormatRetry(ev syscon.RetryEvent) string {
+	return fmt.Sprintf("Retry %d of %q in %v: %v", ev.Attempt, ev.Command, ev.Delay, ev.Err)
+}
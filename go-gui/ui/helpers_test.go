@@ -1,7 +1,11 @@
 package ui
 
 import (
+	"strings"
 	"testing"
+	"time"
+
+	"ps3syscon-gui/syscon"
 )
 
 func TestFilterOptions(t *testing.T) {
@@ -192,67 +196,67 @@ func TestFormatCommandOutput(t *testing.T) {
 	tests := []struct {
 		name     string
 		scType   string
-		result   CommandResult
+		result   syscon.CommandResult
 		expected string
 	}{
 		{
 			name:     "CXR mode",
 			scType:   "CXR",
-			result:   CommandResult{Code: 0, Data: []string{"DATA1", "DATA2"}},
+			result:   syscon.CommandResult{Code: 0, Data: []string{"DATA1", "DATA2"}},
 			expected: "00000000 DATA1 DATA2",
 		},
 		{
 			name:     "CXR mode with code",
 			scType:   "CXR",
-			result:   CommandResult{Code: 0x12345678, Data: []string{"VALUE"}},
+			result:   syscon.CommandResult{Code: 0x12345678, Data: []string{"VALUE"}},
 			expected: "12345678 VALUE",
 		},
 		{
 			name:     "CXR mode empty data",
 			scType:   "CXR",
-			result:   CommandResult{Code: 0, Data: []string{}},
+			result:   syscon.CommandResult{Code: 0, Data: []string{}},
 			expected: "00000000 ",
 		},
 		{
 			name:     "SW mode single line",
 			scType:   "SW",
-			result:   CommandResult{Code: 0, Data: []string{"DATA"}},
+			result:   syscon.CommandResult{Code: 0, Data: []string{"DATA"}},
 			expected: "00000000 DATA",
 		},
 		{
 			name:     "SW mode multiline",
 			scType:   "SW",
-			result:   CommandResult{Code: 0, Data: []string{"LINE1\nLINE2"}},
+			result:   syscon.CommandResult{Code: 0, Data: []string{"LINE1\nLINE2"}},
 			expected: "00000000\nLINE1\nLINE2",
 		},
 		{
 			name:     "SW mode empty data",
 			scType:   "SW",
-			result:   CommandResult{Code: 0, Data: []string{}},
+			result:   syscon.CommandResult{Code: 0, Data: []string{}},
 			expected: "00000000\n",
 		},
 		{
 			name:     "CXRF mode with data",
 			scType:   "CXRF",
-			result:   CommandResult{Code: 0, Data: []string{"SC_READY"}},
+			result:   syscon.CommandResult{Code: 0, Data: []string{"SC_READY"}},
 			expected: "SC_READY",
 		},
 		{
 			name:     "CXRF mode empty data",
 			scType:   "CXRF",
-			result:   CommandResult{Code: 0, Data: []string{}},
+			result:   syscon.CommandResult{Code: 0, Data: []string{}},
 			expected: "",
 		},
 		{
 			name:     "Unknown mode with data",
 			scType:   "UNKNOWN",
-			result:   CommandResult{Code: 0, Data: []string{"RESPONSE"}},
+			result:   syscon.CommandResult{Code: 0, Data: []string{"RESPONSE"}},
 			expected: "RESPONSE",
 		},
 		{
 			name:     "Unknown mode empty data",
 			scType:   "UNKNOWN",
-			result:   CommandResult{Code: 0, Data: []string{}},
+			result:   syscon.CommandResult{Code: 0, Data: []string{}},
 			expected: "",
 		},
 	}
@@ -269,21 +273,97 @@ func TestFormatCommandOutput(t *testing.T) {
 }
 
 func TestCommandResultStruct(t *testing.T) {
-	// Test that CommandResult can be created and accessed
-	result := CommandResult{
+	// Test that syscon.CommandResult can be created and accessed
+	result := syscon.CommandResult{
 		Code: 0x12345678,
 		Data: []string{"data1", "data2"},
 	}
 
 	if result.Code != 0x12345678 {
-		t.Errorf("CommandResult.Code = %x, want 0x12345678", result.Code)
+		t.Errorf("syscon.CommandResult.Code = %x, want 0x12345678", result.Code)
 	}
 
 	if len(result.Data) != 2 {
-		t.Errorf("CommandResult.Data length = %d, want 2", len(result.Data))
+		t.Errorf("syscon.CommandResult.Data length = %d, want 2", len(result.Data))
 	}
 
 	if result.Data[0] != "data1" || result.Data[1] != "data2" {
-		t.Errorf("CommandResult.Data = %v, want [data1 data2]", result.Data)
+		t.Errorf("syscon.CommandResult.Data = %v, want [data1 data2]", result.Data)
+	}
+}
+
+func TestFormatRetry(t *testing.T) {
+	ev := syscon.RetryEvent{
+		Command: "VER",
+		Attempt: 1,
+		Err:     syscon.ErrChecksumMismatch,
+		Delay:   100 * time.Millisecond,
+	}
+	want := `Retry 1 of "VER" in 100ms: checksum mismatch`
+	if got := FormatRetry(ev); got != want {
+		t.Errorf("FormatRetry = %q, want %q", got, want)
+	}
+}
+
+func TestCommandHint(t *testing.T) {
+	tests := []struct {
+		cmd  *syscon.Command
+		want string
+	}{
+		{nil, ""},
+		{&syscon.Command{Description: "Get version info"}, "Get version info"},
+		{&syscon.Command{Examples: []string{"tmp 0"}}, "e.g. tmp 0"},
+		{
+			&syscon.Command{Description: "EEPROM access", Examples: []string{"EEP GET 3961 01", "EEP SET 3961 01 00"}},
+			"EEPROM access - e.g. EEP GET 3961 01, EEP SET 3961 01 00",
+		},
+	}
+	for _, tt := range tests {
+		if got := CommandHint(tt.cmd); got != tt.want {
+			t.Errorf("CommandHint(%+v) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionHint(t *testing.T) {
+	eep := &syscon.Command{Name: "EEP", Permission: 0x0000C0DF}
+	tests := []struct {
+		cmd     *syscon.Command
+		state   syscon.AuthState
+		want    string
+		allowed bool
+	}{
+		{nil, syscon.AuthNone, "", true},
+		{&syscon.Command{Name: "eepcsum"}, syscon.AuthNone, "", true},
+		{eep, syscon.AuthAuthenticated, "Permission 0x0000C0DF: runs after authentication (unknown bits 0x1, 0x2, 0x4, 0x8, 0x40, 0x80, 0x4000, 0x8000)", true},
+		{eep, syscon.AuthNone, "Permission 0x0000C0DF: runs after authentication (unknown bits 0x1, 0x2, 0x4, 0x8, 0x40, 0x80, 0x4000, 0x8000) - authenticate first", false},
+		{&syscon.Command{Name: "AUTH1", Permission: 0x0000C0EF}, syscon.AuthAuthenticated,
+			"Permission 0x0000C0EF: runs before authentication (unknown bits 0x1, 0x2, 0x4, 0x8, 0x40, 0x80, 0x4000, 0x8000) - not allowed once authenticated", false},
+	}
+	for _, tt := range tests {
+		hint, allowed := PermissionHint(tt.cmd, tt.state)
+		if hint != tt.want || allowed != tt.allowed {
+			t.Errorf("PermissionHint(%+v, %v) = %q, %v; want %q, %v", tt.cmd, tt.state, hint, allowed, tt.want, tt.allowed)
+		}
+	}
+}
+
+func TestDangerHint(t *testing.T) {
+	if got := DangerHint(syscon.DangerSafe); got != "" {
+		t.Errorf("safe hint = %q, want none", got)
+	}
+	if got := DangerHint(syscon.DangerDestructive); !strings.HasPrefix(got, "Destructive") {
+		t.Errorf("destructive hint = %q", got)
+	}
+	if got := DangerHint(syscon.DangerModifying); !strings.Contains(got, "read-only mode") {
+		t.Errorf("modifying hint = %q", got)
+	}
+}
+
+func TestFormatWireBytes(t *testing.T) {
+	got := FormatWireBytes([]string{"VER\r\n", "ok\r\n"})
+	want := "\"VER\\r\\n\"\n56 45 52 0D 0A\n\"ok\\r\\n\"\n6F 6B 0D 0A"
+	if got != want {
+		t.Errorf("FormatWireBytes = %q, want %q", got, want)
 	}
 }
@@ -3,32 +3,32 @@ package ui
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
+	"ps3syscon-gui/syscon"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/widget"
 )
 
-// SerialPort abstracts serial port operations.
-type SerialPort interface {
-	Read([]byte) (int, error)
-	Write([]byte) (int, error)
-	Close() error
-	SetReadTimeout(time.Duration) error
-}
-
 // PortOpener is a function that opens a serial port.
-type PortOpener func(portName string, baudRate int) (SerialPort, error)
+type PortOpener func(portName string, baudRate int) (syscon.SerialPort, error)
 
 // SerialMonitor manages serial port monitoring with proper lifecycle control.
 type SerialMonitor struct {
 	mu         sync.Mutex
-	port       SerialPort
+	port       syscon.SerialPort
+	rawPort    syscon.SerialPort // port without the recording wrapper
+	portName   string
+	baudRate   int
+	recorder   *syscon.Recorder
 	cancel     context.CancelFunc
 	running    bool
 	outputText *widget.Entry
 	openPort   PortOpener
+	stopped    func() // called on the UI thread when a read error stops the monitor
 }
 
 // NewSerialMonitor creates a new serial monitor instance.
@@ -59,7 +59,10 @@ func (m *SerialMonitor) Start(ctx context.Context, portName string, baudRate int
 		return err
 	}
 
-	m.port = port
+	m.rawPort = port
+	m.portName = portName
+	m.baudRate = baudRate
+	m.port = m.wrapLocked(port)
 	m.running = true
 
 	// Create cancellable context for this monitoring session
@@ -88,11 +91,33 @@ func (m *SerialMonitor) Stop() {
 	if m.port != nil {
 		m.port.Close()
 		m.port = nil
+		m.rawPort = nil
 	}
 
 	m.running = false
 }
 
+// SetRecorder starts copying received bytes to rec, including on a port
+// that is already open. A nil rec stops recording; the caller closes the
+// old recorder.
+func (m *SerialMonitor) SetRecorder(rec *syscon.Recorder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.recorder = rec
+	if m.rawPort != nil {
+		m.port = m.wrapLocked(m.rawPort)
+	}
+}
+
+// wrapLocked wraps port in the recorder, if there is one.
+func (m *SerialMonitor) wrapLocked(port syscon.SerialPort) syscon.SerialPort {
+	if m.recorder == nil {
+		return port
+	}
+	return syscon.RecordingPort(port, m.recorder, m.portName, m.baudRate)
+}
+
 // IsRunning returns whether the monitor is actively reading.
 func (m *SerialMonitor) IsRunning() bool {
 	m.mu.Lock()
@@ -117,12 +142,41 @@ func (m *SerialMonitor) readLoop(ctx context.Context) {
 			}
 
 			n, err := port.Read(buf)
-			if err == nil && n > 0 {
+			if n > 0 {
 				text := string(buf[:n])
 				fyne.Do(func() {
 					m.outputText.SetText(m.outputText.Text + text)
 				})
 			}
+			if err != nil {
+				m.fail(ctx, err)
+				return
+			}
 		}
 	}
 }
+
+// fail stops a monitor whose port failed, unless Stop has already ended
+// this read loop, and reports why in the output.
+func (m *SerialMonitor) fail(ctx context.Context, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+	m.cancel()
+	m.cancel = nil
+	m.port.Close()
+	m.port = nil
+	m.rawPort = nil
+	m.running = false
+
+	stopped := m.stopped
+	fyne.Do(func() {
+		m.outputText.SetText(m.outputText.Text + fmt.Sprintf("\n[Monitor stopped: %v]\n", err))
+		if stopped != nil {
+			stopped()
+		}
+	})
+}
@@ -1,12 +1,16 @@
 package ui
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"ps3syscon-gui/syscon"
+
 	"fyne.io/fyne/v2/test"
 	"fyne.io/fyne/v2/widget"
 )
@@ -64,7 +68,7 @@ func TestNewSerialMonitor(t *testing.T) {
 	defer app.Quit()
 
 	outputText := widget.NewMultiLineEntry()
-	openPort := func(portName string, baudRate int) (SerialPort, error) {
+	openPort := func(portName string, baudRate int) (syscon.SerialPort, error) {
 		return &mockSerialPort{}, nil
 	}
 
@@ -89,7 +93,7 @@ func TestSerialMonitorStart(t *testing.T) {
 
 	outputText := widget.NewMultiLineEntry()
 	mockPort := &mockSerialPort{}
-	openPort := func(portName string, baudRate int) (SerialPort, error) {
+	openPort := func(portName string, baudRate int) (syscon.SerialPort, error) {
 		return mockPort, nil
 	}
 
@@ -113,7 +117,7 @@ func TestSerialMonitorStartError(t *testing.T) {
 
 	outputText := widget.NewMultiLineEntry()
 	expectedErr := errors.New("port open failed")
-	openPort := func(portName string, baudRate int) (SerialPort, error) {
+	openPort := func(portName string, baudRate int) (syscon.SerialPort, error) {
 		return nil, expectedErr
 	}
 
@@ -135,7 +139,7 @@ func TestSerialMonitorStop(t *testing.T) {
 
 	outputText := widget.NewMultiLineEntry()
 	mockPort := &mockSerialPort{}
-	openPort := func(portName string, baudRate int) (SerialPort, error) {
+	openPort := func(portName string, baudRate int) (syscon.SerialPort, error) {
 		return mockPort, nil
 	}
 
@@ -158,7 +162,7 @@ func TestSerialMonitorStopWhenNotRunning(t *testing.T) {
 	defer app.Quit()
 
 	outputText := widget.NewMultiLineEntry()
-	openPort := func(portName string, baudRate int) (SerialPort, error) {
+	openPort := func(portName string, baudRate int) (syscon.SerialPort, error) {
 		return &mockSerialPort{}, nil
 	}
 
@@ -177,7 +181,7 @@ func TestSerialMonitorIsRunning(t *testing.T) {
 	defer app.Quit()
 
 	outputText := widget.NewMultiLineEntry()
-	openPort := func(portName string, baudRate int) (SerialPort, error) {
+	openPort := func(portName string, baudRate int) (syscon.SerialPort, error) {
 		return &mockSerialPort{}, nil
 	}
 
@@ -205,7 +209,7 @@ func TestSerialMonitorStartWhileRunning(t *testing.T) {
 	defer app.Quit()
 
 	outputText := widget.NewMultiLineEntry()
-	openPort := func(portName string, baudRate int) (SerialPort, error) {
+	openPort := func(portName string, baudRate int) (syscon.SerialPort, error) {
 		return &mockSerialPort{}, nil
 	}
 
@@ -227,7 +231,7 @@ func TestSerialMonitorMultipleStartStop(t *testing.T) {
 	defer app.Quit()
 
 	outputText := widget.NewMultiLineEntry()
-	openPort := func(portName string, baudRate int) (SerialPort, error) {
+	openPort := func(portName string, baudRate int) (syscon.SerialPort, error) {
 		return &mockSerialPort{}, nil
 	}
 
@@ -251,7 +255,7 @@ func TestSerialMonitorSetReadTimeoutError(t *testing.T) {
 
 	// Create a mock port that returns error on SetReadTimeout
 	mockPort := &mockSerialPortWithTimeoutError{}
-	openPort := func(portName string, baudRate int) (SerialPort, error) {
+	openPort := func(portName string, baudRate int) (syscon.SerialPort, error) {
 		return mockPort, nil
 	}
 
@@ -272,3 +276,65 @@ type mockSerialPortWithTimeoutError struct {
 func (m *mockSerialPortWithTimeoutError) SetReadTimeout(d time.Duration) error {
 	return errors.New("timeout error")
 }
+
+func TestSerialMonitorSetRecorder(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	var buf bytes.Buffer
+	rec, err := syscon.NewRecorder(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monitor := NewSerialMonitor(widget.NewMultiLineEntry(), func(portName string, baudRate int) (syscon.SerialPort, error) {
+		return &mockSerialPort{}, nil
+	})
+	monitor.SetRecorder(rec)
+	if err := monitor.Start(context.Background(), "/dev/ttyUSB0", 57600); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	monitor.SetRecorder(nil)
+	monitor.Stop()
+
+	if !strings.Contains(buf.String(), `"dir":"open"`) {
+		t.Errorf("capture has no open event:\n%s", buf.String())
+	}
+}
+
+func TestSerialMonitorStopsOnReadError(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	output := widget.NewMultiLineEntry()
+	port := &mockSerialPort{readErr: errors.New("device unplugged")}
+	monitor := NewSerialMonitor(output, func(portName string, baudRate int) (syscon.SerialPort, error) {
+		return port, nil
+	})
+	if err := monitor.Start(context.Background(), "/dev/ttyUSB0", 57600); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for monitor.IsRunning() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if monitor.IsRunning() || !port.IsClosed() {
+		t.Fatalf("monitor still running after a read error: running %v, port closed %v", monitor.IsRunning(), port.IsClosed())
+	}
+	for !strings.Contains(output.Text, "device unplugged") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(output.Text, "Monitor stopped: device unplugged") {
+		t.Errorf("output = %q, want the read error", output.Text)
+	}
+
+	// A new Start opens the port again
+	port.mu.Lock()
+	port.readErr = nil
+	port.mu.Unlock()
+	if err := monitor.Start(context.Background(), "/dev/ttyUSB0", 57600); err != nil || !monitor.IsRunning() {
+		t.Fatalf("Start after failure: %v", err)
+	}
+	monitor.Stop()
+}
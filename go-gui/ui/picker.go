@@ -0,0 +1,164 @@
+// Package ui provides the command selection of the COMMAND card.
+package ui
+
+import (
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// commandPicker holds the command, subcommand and argument inputs for one
+// syscon mode, with the selected command's description, danger level and
+// permission.
+type commandPicker struct {
+	content     *fyne.Container
+	cmdEntry    *widget.SelectEntry
+	subSelect   *widget.Select
+	args        *argFields
+	descLabel   *widget.Label
+	dangerLabel *widget.Label
+	permLabel   *widget.Label
+
+	lookup func(name string) *syscon.Command
+
+	// authState reports the session's authentication state, or is nil
+	// when it is not tracked
+	authState func() syscon.AuthState
+}
+
+// newCommandPicker returns a picker offering names, described by lookup.
+func newCommandPicker(names []string, lookup func(name string) *syscon.Command) *commandPicker {
+	p := &commandPicker{lookup: lookup}
+
+	p.cmdEntry = widget.NewSelectEntry(names)
+	p.cmdEntry.PlaceHolder = "Select command..."
+
+	p.subSelect = widget.NewSelect([]string{}, nil)
+	p.subSelect.PlaceHolder = "Subcommand"
+	p.subSelect.Disable()
+
+	p.args = newArgFields()
+
+	p.descLabel = widget.NewLabel("")
+	p.descLabel.Wrapping = fyne.TextWrapWord
+	p.descLabel.TextStyle = fyne.TextStyle{Italic: true}
+
+	p.dangerLabel = widget.NewLabel("")
+	p.dangerLabel.Wrapping = fyne.TextWrapWord
+	p.dangerLabel.Hide()
+
+	// Permission mask of the selected command, flagged when the current
+	// authentication state does not allow it
+	p.permLabel = widget.NewLabel("")
+	p.permLabel.Wrapping = fyne.TextWrapWord
+	p.permLabel.Hide()
+
+	p.content = container.NewVBox(
+		container.NewGridWithColumns(2,
+			container.NewVBox(widget.NewLabel("Command"), p.cmdEntry),
+			container.NewVBox(widget.NewLabel("Subcommand"), p.subSelect),
+		),
+		p.args.box,
+		p.descLabel,
+		p.dangerLabel,
+		p.permLabel,
+	)
+
+	// Update subcommand dropdown, arguments and description when command changes
+	p.cmdEntry.OnChanged = func(cmdName string) {
+		p.subSelect.ClearSelected()
+
+		cmd := p.lookup(cmdName)
+		p.descLabel.SetText(CommandHint(cmd))
+		p.args.setSchema(argSchema(cmd, ""))
+		p.updatePermission()
+		p.updateDanger()
+		if cmd != nil && cmd.HasSubcommands() {
+			p.subSelect.Options = cmd.Subcommands
+			p.subSelect.PlaceHolder = "Select..."
+			p.subSelect.Enable()
+		} else {
+			p.subSelect.Options = []string{}
+			p.subSelect.PlaceHolder = "N/A"
+			p.subSelect.Disable()
+		}
+		p.subSelect.Refresh()
+	}
+	return p
+}
+
+// command returns the selected command, or nil for text not in the catalog.
+func (p *commandPicker) command() *syscon.Command {
+	return p.lookup(p.cmdEntry.Text)
+}
+
+// build returns the command line; ok is false when an argument input
+// shows an error.
+func (p *commandPicker) build() (cmdText string, ok bool) {
+	args, ok := p.args.text()
+	return BuildCXRCommand(p.cmdEntry.Text, p.subSelect.Selected, args), ok
+}
+
+// updatePermission refreshes the permission line for the selected command.
+func (p *commandPicker) updatePermission() {
+	if p.authState == nil {
+		return
+	}
+	hint, allowed := PermissionHint(p.command(), p.authState())
+	p.permLabel.SetText(hint)
+	p.permLabel.Importance = widget.MediumImportance
+	if !allowed {
+		p.permLabel.Importance = widget.WarningImportance
+	}
+	p.permLabel.Refresh()
+	if hint == "" {
+		p.permLabel.Hide()
+	} else {
+		p.permLabel.Show()
+	}
+}
+
+// updateDanger shows the danger level of the selected command or
+// subcommand; safe commands show nothing.
+func (p *commandPicker) updateDanger() {
+	hint := ""
+	if cmd := p.command(); cmd != nil {
+		d := cmd.DangerOf(p.subSelect.Selected)
+		hint = DangerHint(d)
+		p.dangerLabel.Importance = widget.WarningImportance
+		if d == syscon.DangerDestructive {
+			p.dangerLabel.Importance = widget.DangerImportance
+		}
+	}
+	p.dangerLabel.SetText(hint)
+	if hint == "" {
+		p.dangerLabel.Hide()
+	} else {
+		p.dangerLabel.Show()
+	}
+}
+
+// setSubmit wires the Enter key to send, and moves focus with focus to the
+// first argument once a subcommand is picked.
+func (p *commandPicker) setSubmit(send func(), focus func(fyne.Focusable)) {
+	p.cmdEntry.OnSubmitted = func(s string) {
+		cmd := p.lookup(s)
+		if cmd == nil || !cmd.HasSubcommands() {
+			send()
+		}
+	}
+	p.args.onSubmit = send
+
+	p.subSelect.OnChanged = func(s string) {
+		if s == "" {
+			return
+		}
+		p.args.setSchema(argSchema(p.command(), s))
+		p.updateDanger()
+		if first := p.args.first(); first != nil {
+			focus(first)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"testing"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+)
+
+func TestCommandPicker(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	p := newCommandPicker(syscon.GetCommandNames(), syscon.GetCommand)
+	p.cmdEntry.SetText("EEP")
+	if p.subSelect.Disabled() || len(p.subSelect.Options) != 3 {
+		t.Fatalf("EEP subcommands = %v, disabled %v", p.subSelect.Options, p.subSelect.Disabled())
+	}
+	if p.descLabel.Text == "" {
+		t.Error("no description for EEP")
+	}
+
+	p.setSubmit(func() {}, func(f fyne.Focusable) {})
+	p.subSelect.SetSelected("GET")
+	if p.dangerLabel.Visible() {
+		t.Errorf("EEP GET shows danger %q", p.dangerLabel.Text)
+	}
+	p.subSelect.SetSelected("INIT")
+	if !p.dangerLabel.Visible() || p.dangerLabel.Text != DangerHint(syscon.DangerDestructive) {
+		t.Errorf("EEP INIT danger = %q, want destructive", p.dangerLabel.Text)
+	}
+
+	p.subSelect.SetSelected("GET")
+	p.args.entries[0].SetText("3961")
+	p.args.entries[1].SetText("01")
+	if cmd, ok := p.build(); !ok || cmd != "EEP GET 3961 01" {
+		t.Errorf("build() = %q, %v", cmd, ok)
+	}
+
+	p.cmdEntry.SetText("VER")
+	if !p.subSelect.Disabled() || p.dangerLabel.Visible() {
+		t.Error("VER kept the EEP subcommands or danger")
+	}
+}
@@ -0,0 +1,183 @@
+// Package ui provides the Advanced panel for serial line settings.
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// baudAuto is the baud rate choice that follows the selected mode.
+const baudAuto = "Auto (by mode)"
+
+// baudOptions returns the common baud rates as strings.
+func baudOptions() []string {
+	options := make([]string, len(syscon.BaudRates))
+	for i, rate := range syscon.BaudRates {
+		options[i] = strconv.Itoa(rate)
+	}
+	return options
+}
+
+// controlLineNames are the choices shown for a hardware control line, in
+// the order of syscon.ControlLines.
+var controlLineNames = []string{"None", "DTR", "RTS"}
+
+// controlLineName returns the display name of a syscon control line.
+func controlLineName(line string) string {
+	for i, l := range syscon.ControlLines {
+		if l == line {
+			return controlLineNames[i]
+		}
+	}
+	return controlLineNames[0]
+}
+
+// controlLine returns the syscon control line for a display name.
+func controlLine(name string) string {
+	for i, n := range controlLineNames {
+		if n == name {
+			return syscon.ControlLines[i]
+		}
+	}
+	return syscon.LineNone
+}
+
+// parseMillis parses a non-negative number of milliseconds.
+func parseMillis(field, s string) (time.Duration, error) {
+	ms, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || ms < 0 {
+		return 0, fmt.Errorf("invalid %s %q", field, s)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// parseBaud parses a baud rate typed or picked by the user.
+func parseBaud(s string) (int, error) {
+	rate, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || rate <= 0 {
+		return 0, fmt.Errorf("invalid baud rate %q", s)
+	}
+	return rate, nil
+}
+
+// newProfileForm returns a form editing p and a function that reads the
+// edited profile back, reporting the first invalid field.
+func newProfileForm(p syscon.ConnectionProfile) (*widget.Form, func() (syscon.ConnectionProfile, error)) {
+	baudEntry := widget.NewSelectEntry(append([]string{baudAuto}, baudOptions()...))
+	if p.BaudRate > 0 {
+		baudEntry.SetText(strconv.Itoa(p.BaudRate))
+	} else {
+		baudEntry.SetText(baudAuto)
+	}
+
+	dataBitsSelect := widget.NewSelect([]string{"5", "6", "7", "8"}, nil)
+	dataBitsSelect.SetSelected(strconv.Itoa(p.DataBits))
+
+	paritySelect := widget.NewSelect(syscon.Parities, nil)
+	paritySelect.SetSelected(p.Parity)
+
+	stopBitsSelect := widget.NewSelect(syscon.StopBits, nil)
+	stopBitsSelect.SetSelected(p.StopBits)
+
+	delayEntry := widget.NewEntry()
+	delayEntry.SetText(strconv.FormatInt(p.WriteDelay.Milliseconds(), 10))
+
+	dtrCheck := widget.NewCheck("Raise DTR on open", nil)
+	dtrCheck.SetChecked(p.DTR)
+	rtsCheck := widget.NewCheck("Raise RTS on open", nil)
+	rtsCheck.SetChecked(p.RTS)
+
+	// Hardware control: adapter lines wired to DIAG and a power relay
+	hw := p.Hardware
+	diagSelect := widget.NewSelect(controlLineNames, nil)
+	diagSelect.SetSelected(controlLineName(hw.DIAG))
+	diagInvert := widget.NewCheck("Active low", nil)
+	diagInvert.SetChecked(hw.DIAGInvert)
+	powerSelect := widget.NewSelect(controlLineNames, nil)
+	powerSelect.SetSelected(controlLineName(hw.Power))
+	powerInvert := widget.NewCheck("Active low", nil)
+	powerInvert.SetChecked(hw.PowerInvert)
+	offEntry := widget.NewEntry()
+	if hw.PowerOffTime > 0 {
+		offEntry.SetText(strconv.FormatInt(hw.PowerOffTime.Milliseconds(), 10))
+	}
+	offEntry.SetPlaceHolder(strconv.FormatInt(syscon.DefaultPowerOffTime.Milliseconds(), 10))
+
+	form := widget.NewForm(
+		widget.NewFormItem("Baud rate", baudEntry),
+		widget.NewFormItem("Data bits", dataBitsSelect),
+		widget.NewFormItem("Parity", paritySelect),
+		widget.NewFormItem("Stop bits", stopBitsSelect),
+		widget.NewFormItem("Write delay (ms)", delayEntry),
+		widget.NewFormItem("DTR", dtrCheck),
+		widget.NewFormItem("RTS", rtsCheck),
+		widget.NewFormItem("DIAG line", container.NewHBox(diagSelect, diagInvert)),
+		widget.NewFormItem("Power line", container.NewHBox(powerSelect, powerInvert)),
+		widget.NewFormItem("Power off (ms)", offEntry),
+	)
+
+	read := func() (syscon.ConnectionProfile, error) {
+		var out syscon.ConnectionProfile
+		if baudEntry.Text != baudAuto {
+			rate, err := parseBaud(baudEntry.Text)
+			if err != nil {
+				return out, err
+			}
+			out.BaudRate = rate
+		}
+		out.DataBits, _ = strconv.Atoi(dataBitsSelect.Selected)
+		out.Parity = paritySelect.Selected
+		out.StopBits = stopBitsSelect.Selected
+
+		var err error
+		if out.WriteDelay, err = parseMillis("write delay", delayEntry.Text); err != nil {
+			return out, err
+		}
+		out.DTR = dtrCheck.Checked
+		out.RTS = rtsCheck.Checked
+
+		out.Hardware = syscon.HardwareControl{
+			DIAG:        controlLine(diagSelect.Selected),
+			DIAGInvert:  diagInvert.Checked,
+			Power:       controlLine(powerSelect.Selected),
+			PowerInvert: powerInvert.Checked,
+			SettleTime:  hw.SettleTime,
+		}
+		if strings.TrimSpace(offEntry.Text) != "" {
+			if out.Hardware.PowerOffTime, err = parseMillis("power off time", offEntry.Text); err != nil {
+				return out, err
+			}
+		}
+		return out, out.Validate()
+	}
+	return form, read
+}
+
+// ShowProfileDialog opens the Advanced panel for port's line settings and
+// hands the edited profile to save when the user confirms.
+func ShowProfileDialog(parent fyne.Window, port string, p syscon.ConnectionProfile, save func(syscon.ConnectionProfile) error) {
+	form, read := newProfileForm(p)
+	d := dialog.NewCustomConfirm("Advanced: "+port, "Save", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+		edited, err := read()
+		if err == nil {
+			err = save(edited)
+		}
+		if err != nil {
+			dialog.ShowError(err, parent)
+		}
+	}, parent)
+	d.Resize(fyne.NewSize(380, 0))
+	d.Show()
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+// profileWidgets returns the form's input widgets by label.
+func profileWidgets(form *widget.Form) map[string]fyne.CanvasObject {
+	widgets := make(map[string]fyne.CanvasObject)
+	for _, item := range form.Items {
+		widgets[item.Text] = item.Widget
+	}
+	return widgets
+}
+
+func TestProfileFormRoundTrip(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	p := syscon.DefaultProfile(38400)
+	p.Parity = "even"
+	p.RTS = false
+	p.WriteDelay = 5 * time.Millisecond
+	p.Hardware = syscon.HardwareControl{
+		DIAG:         syscon.LineRTS,
+		Power:        syscon.LineDTR,
+		PowerInvert:  true,
+		PowerOffTime: 3 * time.Second,
+	}
+
+	_, read := newProfileForm(p)
+	got, err := read()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if got != p {
+		t.Errorf("read = %+v, want %+v", got, p)
+	}
+}
+
+func TestProfileFormAutoBaud(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	form, read := newProfileForm(syscon.DefaultProfile(0))
+	widgets := profileWidgets(form)
+	if text := widgets["Baud rate"].(*widget.SelectEntry).Text; text != baudAuto {
+		t.Errorf("baud rate shows %q, want %q", text, baudAuto)
+	}
+
+	got, err := read()
+	if err != nil || got.BaudRate != 0 {
+		t.Errorf("read = %+v, %v; want the baud rate left to the mode", got, err)
+	}
+
+	widgets["Baud rate"].(*widget.SelectEntry).SetText("9600")
+	widgets["Data bits"].(*widget.Select).SetSelected("7")
+	if got, _ := read(); got.BaudRate != 9600 || got.DataBits != 7 {
+		t.Errorf("read after edit = %+v", got)
+	}
+}
+
+func TestProfileFormInvalid(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	tests := []struct {
+		label string
+		text  string
+	}{
+		{"Baud rate", "fast"},
+		{"Write delay (ms)", "-1"},
+		{"Write delay (ms)", "soon"},
+	}
+	for _, tt := range tests {
+		form, read := newProfileForm(syscon.DefaultProfile(57600))
+		switch w := profileWidgets(form)[tt.label].(type) {
+		case *widget.SelectEntry:
+			w.SetText(tt.text)
+		case *widget.Entry:
+			w.SetText(tt.text)
+		}
+		if _, err := read(); err == nil {
+			t.Errorf("%s %q accepted", tt.label, tt.text)
+		}
+	}
+}
+
+func TestProfileFormSameLineTwice(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	form, read := newProfileForm(syscon.DefaultProfile(57600))
+	widgets := profileWidgets(form)
+	widgets["DIAG line"].(*fyne.Container).Objects[0].(*widget.Select).SetSelected("DTR")
+	widgets["Power line"].(*fyne.Container).Objects[0].(*widget.Select).SetSelected("DTR")
+	if _, err := read(); err == nil {
+		t.Error("DIAG and power on the same line accepted")
+	}
+}
+
+func TestParseBaud(t *testing.T) {
+	if rate, err := parseBaud(" 38400 "); err != nil || rate != 38400 {
+		t.Errorf("parseBaud(38400) = %d, %v", rate, err)
+	}
+	for _, s := range []string{"", "0", "-9600", "abc"} {
+		if _, err := parseBaud(s); err == nil {
+			t.Errorf("parseBaud(%q) succeeded", s)
+		}
+	}
+}
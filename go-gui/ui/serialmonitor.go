@@ -4,6 +4,9 @@ package ui
 import (
 	"context"
 	"errors"
+	"strconv"
+
+	"ps3syscon-gui/syscon"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -17,6 +20,8 @@ import (
 type MonitorDeps struct {
 	GetSerialPorts func() []string
 	OpenPort       PortOpener
+	CheckWiring    func(ctx context.Context, port syscon.SerialPort, scType string, baudRate int) (syscon.WiringReport, error)
+	NewRecorder    RecorderFactory
 }
 
 // OpenSerialMonitor opens the serial monitor window.
@@ -35,12 +40,9 @@ func OpenSerialMonitor(myApp fyne.App, defaultPort, scType string, deps MonitorD
 		portSelect.SetSelected(defaultPort)
 	}
 
-	baudSelect := widget.NewSelect([]string{"57600", "115200"}, nil)
-	if scType == "CXRF" {
-		baudSelect.SetSelected("115200")
-	} else {
-		baudSelect.SetSelected("57600")
-	}
+	// Common rates to pick from; other rates can be typed in
+	baudSelect := widget.NewSelectEntry(baudOptions())
+	baudSelect.SetText(strconv.Itoa(GetSerialSpeed(scType)))
 
 	outputText := widget.NewMultiLineEntry()
 	outputText.SetMinRowsVisible(18)
@@ -58,6 +60,8 @@ func OpenSerialMonitor(myApp fyne.App, defaultPort, scType string, deps MonitorD
 	stopBtn := widget.NewButton("Stop", nil)
 	stopBtn.Disable()
 
+	wiringBtn := widget.NewButton("Check Wiring", nil)
+
 	clearBtn := widget.NewButton("Clear", func() {
 		outputText.SetText("")
 	})
@@ -69,6 +73,7 @@ func OpenSerialMonitor(myApp fyne.App, defaultPort, scType string, deps MonitorD
 			statusLabel.Color = ColorSuccess
 			startBtn.Disable()
 			stopBtn.Enable()
+			wiringBtn.Disable()
 			portSelect.Disable()
 			baudSelect.Disable()
 		} else {
@@ -76,21 +81,25 @@ func OpenSerialMonitor(myApp fyne.App, defaultPort, scType string, deps MonitorD
 			statusLabel.Color = ColorTextMuted
 			startBtn.Enable()
 			stopBtn.Disable()
+			wiringBtn.Enable()
 			portSelect.Enable()
 			baudSelect.Enable()
 		}
 		statusLabel.Refresh()
 	}
 
+	monitor.stopped = func() { updateStatus(false) }
+
 	startBtn.OnTapped = func() {
 		if portSelect.Selected == "" {
 			dialog.ShowError(errors.New("mode not selected"), monitorWindow)
 			return
 		}
 
-		baudRate := 57600
-		if baudSelect.Selected == "115200" {
-			baudRate = 115200
+		baudRate, err := parseBaud(baudSelect.Text)
+		if err != nil {
+			dialog.ShowError(err, monitorWindow)
+			return
 		}
 
 		if err := monitor.Start(context.Background(), portSelect.Selected, baudRate); err != nil {
@@ -101,13 +110,54 @@ func OpenSerialMonitor(myApp fyne.App, defaultPort, scType string, deps MonitorD
 		updateStatus(true)
 	}
 
+	// Check Wiring probes the port and prints a plain-language report
+	wiringBtn.OnTapped = func() {
+		if portSelect.Selected == "" {
+			dialog.ShowError(ErrPortNotSelected, monitorWindow)
+			return
+		}
+
+		portName := portSelect.Selected
+		baudRate, err := parseBaud(baudSelect.Text)
+		if err != nil {
+			dialog.ShowError(err, monitorWindow)
+			return
+		}
+		port, err := deps.OpenPort(portName, baudRate)
+		if err != nil {
+			dialog.ShowError(err, monitorWindow)
+			return
+		}
+
+		startBtn.Disable()
+		wiringBtn.Disable()
+		outputText.SetText(outputText.Text + "Checking wiring on " + portName + "...\n")
+
+		go func() {
+			report, err := deps.CheckWiring(context.Background(), port, scType, baudRate)
+			port.Close()
+			fyne.Do(func() {
+				startBtn.Enable()
+				wiringBtn.Enable()
+				if err != nil {
+					dialog.ShowError(err, monitorWindow)
+					return
+				}
+				outputText.SetText(outputText.Text + report.String() + "\n")
+			})
+		}()
+	}
+
 	stopBtn.OnTapped = func() {
 		monitor.Stop()
 		updateStatus(false)
 	}
 
+	captureToggle, stopCapture := CreateCaptureToggle(monitorWindow, "monitor", deps.NewRecorder, monitor.SetRecorder)
+
 	monitorWindow.SetOnClosed(func() {
 		monitor.Stop()
+		stopCapture()
 	})
 
 	// Layout
@@ -116,7 +166,7 @@ func OpenSerialMonitor(myApp fyne.App, defaultPort, scType string, deps MonitorD
 		container.NewVBox(widget.NewLabel("Baud Rate"), baudSelect),
 	)
 
-	buttonRow := container.NewHBox(startBtn, stopBtn, layout.NewSpacer(), statusLabel, layout.NewSpacer(), clearBtn)
+	buttonRow := container.NewHBox(startBtn, stopBtn, wiringBtn, layout.NewSpacer(), statusLabel, layout.NewSpacer(), clearBtn)
 
 	terminalBg := canvas.NewRectangle(ColorInputBg)
 	terminalBg.CornerRadius = 6
@@ -127,6 +177,7 @@ func OpenSerialMonitor(myApp fyne.App, defaultPort, scType string, deps MonitorD
 			widget.NewSeparator(),
 			container.NewPadded(configRow),
 			container.NewPadded(buttonRow),
+			container.NewPadded(captureToggle),
 		),
 		nil, nil, nil,
 		container.NewPadded(container.NewStack(terminalBg, container.NewPadded(outputText))),
@@ -1,7 +1,12 @@
 package ui
 
 import (
+	"context"
+	"io"
 	"testing"
+	"time"
+
+	"ps3syscon-gui/syscon"
 
 	"fyne.io/fyne/v2/test"
 )
@@ -11,9 +16,15 @@ func testMonitorDeps() MonitorDeps {
 		GetSerialPorts: func() []string {
 			return []string{"/dev/ttyUSB0", "/dev/ttyUSB1"}
 		},
-		OpenPort: func(portName string, baudRate int) (SerialPort, error) {
+		OpenPort: func(portName string, baudRate int) (syscon.SerialPort, error) {
 			return &mockSerialPort{}, nil
 		},
+		CheckWiring: func(ctx context.Context, port syscon.SerialPort, scType string, baudRate int) (syscon.WiringReport, error) {
+			return syscon.WiringReport{Fault: syscon.WiringOK, BaudRate: baudRate, Summary: "ok"}, nil
+		},
+		NewRecorder: func(kind string) (*syscon.Recorder, error) {
+			return syscon.NewRecorder(io.Discard)
+		},
 	}
 }
 
@@ -71,7 +82,7 @@ func TestOpenSerialMonitorWithEmptyPorts(t *testing.T) {
 
 	deps := MonitorDeps{
 		GetSerialPorts: func() []string { return []string{} },
-		OpenPort: func(portName string, baudRate int) (SerialPort, error) {
+		OpenPort: func(portName string, baudRate int) (syscon.SerialPort, error) {
 			return &mockSerialPort{}, nil
 		},
 	}
@@ -82,7 +93,7 @@ func TestOpenSerialMonitorWithEmptyPorts(t *testing.T) {
 func TestMonitorDepsStruct(t *testing.T) {
 	deps := MonitorDeps{
 		GetSerialPorts: func() []string { return []string{"port1"} },
-		OpenPort: func(portName string, baudRate int) (SerialPort, error) {
+		OpenPort: func(portName string, baudRate int) (syscon.SerialPort, error) {
 			return nil, nil
 		},
 	}
@@ -97,3 +108,30 @@ func TestMonitorDepsStruct(t *testing.T) {
 		t.Error("OpenPort should return nil, nil")
 	}
 }
+
+func TestOpenSerialMonitorCheckWiring(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	called := make(chan string, 1)
+	deps := testMonitorDeps()
+	deps.CheckWiring = func(ctx context.Context, port syscon.SerialPort, scType string, baudRate int) (syscon.WiringReport, error) {
+		called <- scType
+		return syscon.WiringReport{}, nil
+	}
+
+	OpenSerialMonitor(app, "/dev/ttyUSB0", "CXRF", deps)
+
+	windows := app.Driver().AllWindows()
+	content := windows[len(windows)-1].Content()
+	test.Tap(findButton(content, "Check Wiring"))
+
+	select {
+	case scType := <-called:
+		if scType != "CXRF" {
+			t.Errorf("CheckWiring scType = %q, want CXRF", scType)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("CheckWiring was not called")
+	}
+}
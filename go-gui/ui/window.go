@@ -2,40 +2,48 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"ps3syscon-gui/syscon"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 )
 
-// Command represents a PS3 Syscon command with optional subcommands.
-type Command struct {
-	Name        string
-	Subcommands []string
-	Description string
-}
-
-// HasSubcommands returns true if the command has subcommands.
-func (c *Command) HasSubcommands() bool {
-	return len(c.Subcommands) > 0
-}
-
 // WindowDeps contains dependencies for the main window.
 type WindowDeps struct {
-	LogoResource       fyne.Resource
-	GetSerialPorts     func() []string
-	GetCommandNames    func() []string
+	LogoResource        fyne.Resource
+	GetSerialPorts      func() []string
+	GetCommandNames     func() []string
 	GetCXRFCommandNames func() []string
-	GetCommand         func(name string) *Command
-	GetCXRFCommand     func(name string) *Command
-	SendCommand        func(port, scType, cmd string, speed int) (CommandResult, error)
-	Authenticate       func(port, scType string, speed int) error
-	OpenSerialMonitor  func(myApp fyne.App, port, scType string)
-	ShowGuideWindow    func(myApp fyne.App)
+	GetSWCommandNames   func() []string
+	GetCommand          func(name string) *syscon.Command
+	GetCXRFCommand      func(name string) *syscon.Command
+	GetSWCommand        func(name string) *syscon.Command
+	Connect             func(port, scType string, speed int) error
+	Disconnect          func() error
+	ConnectionState     func() (syscon.ConnectionState, error)
+	AuthState           func() syscon.AuthState
+	SendCommand         func(ctx context.Context, cmd string) (syscon.CommandResult, error)
+	Authenticate        func(ctx context.Context) error
+	DetectDevice        func(ctx context.Context, port string) (syscon.Detection, error)
+	LoadProfile         func(port string) syscon.ConnectionProfile
+	SaveProfile         func(port string, p syscon.ConnectionProfile) error
+	SwitchMode          func(ctx context.Context, scType string, speed int) error
+	SetReadOnly         func(on bool)
+	NewRecorder         RecorderFactory
+	NewDumpPath         DumpPathFactory
+	SetRecorder         func(rec *syscon.Recorder)
+	OpenSerialMonitor   func(myApp fyne.App, port, scType string)
+	ShowGuideWindow     func(myApp fyne.App)
 }
 
 // CreateMainWindow builds the main application window content.
@@ -59,6 +67,68 @@ func CreateMainWindow(myApp fyne.App, myWindow fyne.Window, deps WindowDeps) fyn
 	modeDesc := widget.NewLabel("External commands via UART")
 	modeDesc.TextStyle = fyne.TextStyle{Italic: true}
 
+	// Connection status
+	statusLabel := canvas.NewText("DISCONNECTED", ColorTextMuted)
+	statusLabel.TextSize = 10
+
+	connectBtn := widget.NewButton("Connect", nil)
+
+	detectBtn := widget.NewButton("Detect", nil)
+	detectBtn.Importance = widget.LowImportance
+
+	// Advanced edits the port's saved line settings, used on the next Connect
+	advancedBtn := widget.NewButton("Advanced", func() {
+		if portSelect.Selected == "" {
+			dialog.ShowError(ErrPortNotSelected, myWindow)
+			return
+		}
+		port := portSelect.Selected
+		ShowProfileDialog(myWindow, port, deps.LoadProfile(port), func(p syscon.ConnectionProfile) error {
+			return deps.SaveProfile(port, p)
+		})
+	})
+	advancedBtn.Importance = widget.LowImportance
+	if deps.LoadProfile == nil || deps.SaveProfile == nil {
+		advancedBtn.Hide()
+	}
+
+	// Power Cycle reboots the console into a mode through the DIAG and
+	// power lines set up in Advanced
+	powerCycleBtn := widget.NewButton("Power Cycle", nil)
+	powerCycleBtn.Importance = widget.LowImportance
+	powerCycleBtn.Disable()
+	if deps.SwitchMode == nil {
+		powerCycleBtn.Hide()
+	}
+
+	// Internal Mode steps through entering or leaving CXRF mode
+	internalModeBtn := widget.NewButton("Internal Mode", nil)
+	internalModeBtn.Importance = widget.LowImportance
+	internalModeBtn.Disable()
+
+	// Dump EEPROM backs the whole EEPROM up to a file
+	dumpBtn := widget.NewButton("Dump EEPROM", nil)
+	dumpBtn.Importance = widget.LowImportance
+	dumpBtn.Disable()
+
+	// Result of the last Detect, used for the baud rate on Connect
+	var detected syscon.Detection
+	var detectedPort string
+	detectLabel := canvas.NewText("", ColorTextMuted)
+	detectLabel.TextSize = 10
+
+	captureToggle, _ := CreateCaptureToggle(myWindow, "session", deps.NewRecorder, deps.SetRecorder)
+
+	// Read-only mode refuses every command that is not safe
+	readOnly := false
+	readOnlyCheck := widget.NewCheck("Read-only mode", func(on bool) {
+		readOnly = on
+		deps.SetReadOnly(on)
+	})
+	if deps.SetReadOnly == nil {
+		readOnlyCheck.Hide()
+	}
+
 	connectionContent := container.NewVBox(
 		container.NewGridWithColumns(2,
 			container.NewVBox(
@@ -71,66 +141,25 @@ func CreateMainWindow(myApp fyne.App, myWindow fyne.Window, deps WindowDeps) fyn
 			),
 		),
 		modeDesc,
+		detectLabel,
+		container.NewHBox(connectBtn, detectBtn, advancedBtn, powerCycleBtn, internalModeBtn, dumpBtn, layout.NewSpacer(), statusLabel),
+		container.NewBorder(nil, nil, nil, readOnlyCheck, captureToggle),
 	)
 
 	connectionCard := CreateCard("CONNECTION", connectionContent)
 
-	// CXR command selection widgets
-	cmdSelectEntry := widget.NewSelectEntry(deps.GetCommandNames())
-	cmdSelectEntry.PlaceHolder = "Select command..."
-
-	subCmdSelect := widget.NewSelect([]string{}, nil)
-	subCmdSelect.PlaceHolder = "Subcommand"
-	subCmdSelect.Disable()
-
-	argsEntry := widget.NewEntry()
-	argsEntry.SetPlaceHolder("Arguments")
-
-	cxrCommandContent := container.NewGridWithColumns(3,
-		container.NewVBox(widget.NewLabel("Command"), cmdSelectEntry),
-		container.NewVBox(widget.NewLabel("Subcommand"), subCmdSelect),
-		container.NewVBox(widget.NewLabel("Arguments"), argsEntry),
-	)
-
-	// CXRF command selection widgets
-	cxrfCmdSelectEntry := widget.NewSelectEntry(deps.GetCXRFCommandNames())
-	cxrfCmdSelectEntry.PlaceHolder = "Select command..."
-
-	cxrfSubCmdSelect := widget.NewSelect([]string{}, nil)
-	cxrfSubCmdSelect.PlaceHolder = "Subcommand"
-	cxrfSubCmdSelect.Disable()
-
-	cxrfArgsEntry := widget.NewEntry()
-	cxrfArgsEntry.SetPlaceHolder("Arguments")
-
-	cxrfDescLabel := widget.NewLabel("")
-	cxrfDescLabel.Wrapping = fyne.TextWrapWord
-	cxrfDescLabel.TextStyle = fyne.TextStyle{Italic: true}
-
-	cxrfCommandContent := container.NewVBox(
-		container.NewGridWithColumns(3,
-			container.NewVBox(widget.NewLabel("Command"), cxrfCmdSelectEntry),
-			container.NewVBox(widget.NewLabel("Subcommand"), cxrfSubCmdSelect),
-			container.NewVBox(widget.NewLabel("Arguments"), cxrfArgsEntry),
-		),
-		cxrfDescLabel,
-	)
-
-	// Legacy command entry for SW mode
-	commandEntry := widget.NewEntry()
-	commandEntry.SetPlaceHolder("Enter raw command...")
-
-	legacyCommandContent := container.NewVBox(
-		widget.NewLabel("Raw Command"),
-		commandEntry,
-	)
-
-	// Command sections
-	cxrSection := cxrCommandContent
-	cxrfSection := cxrfCommandContent
-	legacySection := legacyCommandContent
+	// Command selection for each mode; SW shares the CXRF internal commands
+	// plus its own entries
+	pickers := map[string]*commandPicker{
+		"CXR":  newCommandPicker(deps.GetCommandNames(), deps.GetCommand),
+		"CXRF": newCommandPicker(deps.GetCXRFCommandNames(), deps.GetCXRFCommand),
+		"SW":   newCommandPicker(deps.GetSWCommandNames(), deps.GetSWCommand),
+	}
+	for _, p := range pickers {
+		p.authState = deps.AuthState
+	}
 
-	commandSection := container.NewStack(cxrSection)
+	commandSection := container.NewStack(pickers["CXR"].content)
 	commandCard := CreateCard("COMMAND", commandSection)
 
 	// Output terminal
@@ -169,165 +198,474 @@ func CreateMainWindow(myApp fyne.App, myWindow fyne.Window, deps WindowDeps) fyn
 		case "CXRF":
 			modeDesc.SetText("Internal commands - DIAG mode (115200 baud)")
 		case "SW":
-			modeDesc.SetText("Legacy raw command mode")
+			modeDesc.SetText("Sherwood internal commands, no DIAG needed (57600 baud)")
 		}
 	}
 
-	// Update subcommand dropdown when command changes
-	cmdSelectEntry.OnChanged = func(cmdName string) {
-		argsEntry.SetText("")
-		subCmdSelect.ClearSelected()
-
-		cmd := deps.GetCommand(cmdName)
-		if cmd != nil && cmd.HasSubcommands() {
-			subCmdSelect.Options = cmd.Subcommands
-			subCmdSelect.PlaceHolder = "Select..."
-			subCmdSelect.Enable()
-		} else {
-			subCmdSelect.Options = []string{}
-			subCmdSelect.PlaceHolder = "N/A"
-			subCmdSelect.Disable()
-		}
-		subCmdSelect.Refresh()
+	// Build command string based on mode; ok is false when an argument
+	// input shows an error
+	buildCommand := func() (cmdText string, ok bool) {
+		return pickers[scTypeSelect.Selected].build()
 	}
 
-	// Update CXRF subcommand dropdown and description when command changes
-	cxrfCmdSelectEntry.OnChanged = func(cmdName string) {
-		cxrfArgsEntry.SetText("")
-		cxrfSubCmdSelect.ClearSelected()
-
-		cmd := deps.GetCXRFCommand(cmdName)
-		if cmd != nil {
-			cxrfDescLabel.SetText(cmd.Description)
-			if cmd.HasSubcommands() {
-				cxrfSubCmdSelect.Options = cmd.Subcommands
-				cxrfSubCmdSelect.PlaceHolder = "Select..."
-				cxrfSubCmdSelect.Enable()
-			} else {
-				cxrfSubCmdSelect.Options = []string{}
-				cxrfSubCmdSelect.PlaceHolder = "N/A"
-				cxrfSubCmdSelect.Disable()
+	// Reflect the session state in the CONNECTION card
+	refreshStatus := func() {
+		state, err := deps.ConnectionState()
+		statusLabel.Text = state.String()
+		switch {
+		case state == syscon.StateConnected:
+			if deps.AuthState != nil {
+				statusLabel.Text += " / " + deps.AuthState().String()
 			}
-		} else {
-			cxrfDescLabel.SetText("")
-			cxrfSubCmdSelect.Options = []string{}
-			cxrfSubCmdSelect.PlaceHolder = "N/A"
-			cxrfSubCmdSelect.Disable()
-		}
-		cxrfSubCmdSelect.Refresh()
-	}
-
-	// Build command string based on mode
-	buildCommand := func() string {
-		switch scTypeSelect.Selected {
-		case "CXR":
-			return BuildCXRCommand(cmdSelectEntry.Text, subCmdSelect.Selected, argsEntry.Text)
-		case "CXRF":
-			return BuildCXRCommand(cxrfCmdSelectEntry.Text, cxrfSubCmdSelect.Selected, cxrfArgsEntry.Text)
+			statusLabel.Color = ColorSuccess
+			connectBtn.SetText("Disconnect")
+			portSelect.Disable()
+			scTypeSelect.Disable()
+			detectBtn.Disable()
+			advancedBtn.Disable()
+			powerCycleBtn.Enable()
+			internalModeBtn.Enable()
+			dumpBtn.Enable()
+		case err != nil:
+			statusLabel.Text = fmt.Sprintf("%s: %v", state, err)
+			statusLabel.Color = ColorError
+			connectBtn.SetText("Connect")
+			portSelect.Enable()
+			scTypeSelect.Enable()
+			detectBtn.Enable()
+			advancedBtn.Enable()
+			powerCycleBtn.Disable()
+			internalModeBtn.Disable()
+			dumpBtn.Disable()
 		default:
-			return commandEntry.Text
+			statusLabel.Color = ColorTextMuted
+			connectBtn.SetText("Connect")
+			portSelect.Enable()
+			scTypeSelect.Enable()
+			detectBtn.Enable()
+			advancedBtn.Enable()
+			powerCycleBtn.Disable()
+			internalModeBtn.Disable()
+			dumpBtn.Disable()
+		}
+		statusLabel.Refresh()
+		for _, p := range pickers {
+			p.updatePermission()
 		}
 	}
 
-	sendCmd := func() {
+	connect := func() error {
 		if portSelect.Selected == "" {
-			dialog.ShowError(fmt.Errorf("serial port not selected"), myWindow)
-			return
+			return ErrPortNotSelected
 		}
 		if scTypeSelect.Selected == "" {
-			dialog.ShowError(fmt.Errorf("mode not selected"), myWindow)
-			return
+			return ErrModeNotSelected
+		}
+		serialSpeed := GetSerialSpeed(scTypeSelect.Selected)
+		if detectedPort == portSelect.Selected && detected.SCType == scTypeSelect.Selected {
+			serialSpeed = detected.BaudRate
 		}
+		err := deps.Connect(portSelect.Selected, scTypeSelect.Selected, serialSpeed)
+		refreshStatus()
+		return err
+	}
 
-		cmdText := buildCommand()
-		if cmdText == "" {
-			dialog.ShowError(fmt.Errorf("command is empty"), myWindow)
+	// ensureConnected opens the session on first use so Send and
+	// Authenticate work without pressing Connect first.
+	ensureConnected := func() error {
+		if state, _ := deps.ConnectionState(); state == syscon.StateConnected {
+			return nil
+		}
+		return connect()
+	}
+
+	connectBtn.OnTapped = func() {
+		if state, _ := deps.ConnectionState(); state == syscon.StateConnected {
+			if err := deps.Disconnect(); err != nil {
+				dialog.ShowError(err, myWindow)
+			}
+			refreshStatus()
 			return
 		}
+		if err := connect(); err != nil {
+			dialog.ShowError(err, myWindow)
+		}
+	}
 
-		serialSpeed := GetSerialSpeed(scTypeSelect.Selected)
+	// Action buttons
+	sendBtn := widget.NewButton("Send Command", nil)
+	sendBtn.Importance = widget.HighImportance
 
-		result, err := deps.SendCommand(portSelect.Selected, scTypeSelect.Selected, cmdText, serialSpeed)
-		if err != nil {
-			dialog.ShowError(err, myWindow)
-			return
+	authBtn := widget.NewButton("Authenticate", nil)
+
+	// Progress shown while a command runs in the background
+	progress := widget.NewProgressBarInfinite()
+	progress.Stop()
+
+	cancelBtn := widget.NewButton("Cancel", nil)
+	cancelBtn.Importance = widget.DangerImportance
+
+	busyRow := container.NewBorder(nil, nil, nil, cancelBtn, progress)
+	busyRow.Hide()
+
+	busy := false
+	var cancelRun context.CancelFunc
+
+	cancelBtn.OnTapped = func() {
+		if cancelRun != nil {
+			cancelRun()
 		}
+	}
 
-		if result.Code == 0xFFFFFFFF {
-			errMsg := "unknown error"
-			if len(result.Data) > 0 {
-				errMsg = result.Data[0]
-			}
-			dialog.ShowError(fmt.Errorf("command failed: %s", errMsg), myWindow)
+	// runInBackground runs work off the UI goroutine so the window stays
+	// responsive, then hands its error to done back on the UI goroutine.
+	runInBackground := func(work func(ctx context.Context) error, done func(err error)) {
+		ctx, cancel := context.WithCancel(context.Background())
+		busy = true
+		cancelRun = cancel
+		sendBtn.Disable()
+		authBtn.Disable()
+		connectBtn.Disable()
+		detectBtn.Disable()
+		powerCycleBtn.Disable()
+		internalModeBtn.Disable()
+		dumpBtn.Disable()
+		busyRow.Show()
+		progress.Start()
+
+		go func() {
+			err := work(ctx)
+			fyne.Do(func() {
+				cancel()
+				busy = false
+				cancelRun = nil
+				progress.Stop()
+				busyRow.Hide()
+				sendBtn.Enable()
+				authBtn.Enable()
+				connectBtn.Enable()
+				done(err)
+				refreshStatus()
+			})
+		}()
+	}
+
+	// repairChecksums writes the values eepcsum expects in its bad regions
+	// and runs eepcsum again to verify them
+	repairChecksums := func(scType string, regions []syscon.ChecksumRegion) {
+		if busy {
 			return
 		}
 
-		output := FormatCommandOutput(scTypeSelect.Selected, result)
-		timestamp := time.Now().Format("15:04:05")
-		outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > %s\n%s\n", timestamp, cmdText, output))
+		var after []syscon.ChecksumRegion
+		runInBackground(func(ctx context.Context) error {
+			var err error
+			after, err = syscon.RepairChecksums(ctx, deps.SendCommand, regions)
+			return err
+		}, func(err error) {
+			timestamp := time.Now().Format("15:04:05")
+			sent := strings.Join(append(syscon.ChecksumFixCommands(regions), "eepcsum"), "\n> ")
+			if errors.Is(err, context.Canceled) {
+				outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > %s\nCancelled\n", timestamp, sent))
+				return
+			}
+			if after != nil {
+				outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > %s\n%s\n", timestamp, sent, FormatEepcsum(after)))
+			}
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("checksum repair failed: %w", err), myWindow)
+			}
+		})
 	}
 
-	// Enter key handlers
-	cmdSelectEntry.OnSubmitted = func(s string) {
-		cmd := deps.GetCommand(s)
-		if cmd == nil || !cmd.HasSubcommands() {
-			sendCmd()
+	// runCommand sends cmdText in the background and prints the answer
+	runCommand := func(scType, cmdText string) {
+		if busy {
+			return
 		}
+
+		var result syscon.CommandResult
+		runInBackground(func(ctx context.Context) error {
+			ctx = syscon.WithRetryNotify(ctx, func(ev syscon.RetryEvent) {
+				line := fmt.Sprintf("[%s] %s\n", time.Now().Format("15:04:05"), FormatRetry(ev))
+				fyne.Do(func() {
+					outputText.SetText(outputText.Text + line)
+				})
+			})
+			var err error
+			result, err = deps.SendCommand(ctx, cmdText)
+			return err
+		}, func(err error) {
+			timestamp := time.Now().Format("15:04:05")
+			if errors.Is(err, context.Canceled) {
+				outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > %s\nCancelled\n", timestamp, cmdText))
+				return
+			}
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("command failed: %w", err), myWindow)
+				return
+			}
+
+			output := FormatCommandOutput(scType, result)
+			if entries, ok := syscon.DecodeErrorLog(scType, cmdText, result); ok && len(entries) > 0 {
+				output = FormatErrorLog(entries)
+			}
+			regions, isEepcsum := syscon.DecodeEepcsum(scType, cmdText, result)
+			if isEepcsum && len(regions) > 0 {
+				output = FormatEepcsum(regions)
+			}
+			outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > %s\n%s\n", timestamp, cmdText, output))
+
+			// Offer to write the checksums eepcsum expects, unless that
+			// would be refused anyway
+			if len(syscon.BadChecksums(regions)) > 0 && !readOnly {
+				confirmChecksumRepair(myWindow, scType, regions, func() { repairChecksums(scType, regions) })
+			}
+		})
 	}
 
-	argsEntry.OnSubmitted = func(s string) { sendCmd() }
+	// Auth function
+	authCmd := func() {
+		if busy {
+			return
+		}
 
-	subCmdSelect.OnChanged = func(s string) {
-		if s != "" {
-			myWindow.Canvas().Focus(argsEntry)
+		if err := ensureConnected(); err != nil {
+			dialog.ShowError(err, myWindow)
+			return
 		}
+
+		runInBackground(deps.Authenticate, func(err error) {
+			timestamp := time.Now().Format("15:04:05")
+			if errors.Is(err, context.Canceled) {
+				outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > AUTH\nCancelled\n", timestamp))
+				return
+			}
+			if err != nil {
+				outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > AUTH\nFailed: %v\n", timestamp, err))
+				dialog.ShowError(err, myWindow)
+				return
+			}
+
+			outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > AUTH\nAuth successful\n", timestamp))
+		})
 	}
 
-	cxrfCmdSelectEntry.OnSubmitted = func(s string) {
-		cmd := deps.GetCXRFCommand(s)
-		if cmd == nil || !cmd.HasSubcommands() {
-			sendCmd()
+	sendCmd := func() {
+		if busy {
+			return
+		}
+
+		cmdText, ok := buildCommand()
+		if cmdText == "" {
+			dialog.ShowError(ErrCommandEmpty, myWindow)
+			return
+		}
+		if !ok {
+			return
+		}
+		if err := syscon.ValidateCommandLine(scTypeSelect.Selected, cmdText); err != nil {
+			dialog.ShowError(err, myWindow)
+			return
+		}
+		// The guide's SW "auth" step is the AUTH1/AUTH2 handshake, which
+		// the syscon does not accept as a single line
+		if scTypeSelect.Selected == "SW" && cmdText == "auth" {
+			authCmd()
+			return
 		}
-	}
 
-	cxrfArgsEntry.OnSubmitted = func(s string) { sendCmd() }
+		if err := ensureConnected(); err != nil {
+			dialog.ShowError(err, myWindow)
+			return
+		}
 
-	cxrfSubCmdSelect.OnChanged = func(s string) {
-		if s != "" {
-			myWindow.Canvas().Focus(cxrfArgsEntry)
+		scType := scTypeSelect.Selected
+		if readOnly {
+			if err := syscon.CheckReadOnly(scType, cmdText); err != nil {
+				showReadOnlyRefusal(myWindow, scType, cmdText, err)
+				return
+			}
+		}
+		send := func() {
+			confirmDestructive(myWindow, scType, cmdText, func() { runCommand(scType, cmdText) })
 		}
+		if deps.AuthState != nil {
+			if err := syscon.CheckPermission(scType, cmdText, deps.AuthState()); err != nil {
+				dialog.ShowConfirm("Command Not Permitted", err.Error()+"\n\nSend it anyway?", func(ok bool) {
+					if ok {
+						send()
+					}
+				}, myWindow)
+				return
+			}
+		}
+		send()
 	}
 
-	commandEntry.OnSubmitted = func(s string) { sendCmd() }
+	// Enter key handlers
+	for _, p := range pickers {
+		p.setSubmit(sendCmd, myWindow.Canvas().Focus)
+	}
 
-	// Auth function
-	authCmd := func() {
-		if portSelect.Selected == "" {
-			dialog.ShowError(fmt.Errorf("serial port not selected"), myWindow)
+	// Detect probes the port and pre-selects the mode and baud it finds
+	detectCmd := func() {
+		if busy {
 			return
 		}
-		if scTypeSelect.Selected == "" {
-			dialog.ShowError(fmt.Errorf("mode not selected"), myWindow)
+		if portSelect.Selected == "" {
+			dialog.ShowError(ErrPortNotSelected, myWindow)
 			return
 		}
 
-		serialSpeed := GetSerialSpeed(scTypeSelect.Selected)
-		timestamp := time.Now().Format("15:04:05")
+		port := portSelect.Selected
+		var found syscon.Detection
+		runInBackground(func(ctx context.Context) error {
+			var err error
+			found, err = deps.DetectDevice(ctx, port)
+			return err
+		}, func(err error) {
+			timestamp := time.Now().Format("15:04:05")
+			if errors.Is(err, context.Canceled) {
+				outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > DETECT\nCancelled\n", timestamp))
+				return
+			}
+			if err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
 
-		if err := deps.Authenticate(portSelect.Selected, scTypeSelect.Selected, serialSpeed); err != nil {
-			outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > AUTH\nFailed: %v\n", timestamp, err))
-			dialog.ShowError(err, myWindow)
+			outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > DETECT %s\n%s\n", timestamp, port, found))
+			detectLabel.Text = "Detected: " + found.String()
+			detectLabel.Color = detectionColor(found.Confidence)
+			detectLabel.Refresh()
+			if found.Confidence != syscon.ConfidenceNone {
+				detected, detectedPort = found, port
+				scTypeSelect.SetSelected(found.SCType)
+			}
+		})
+	}
+
+	// Power cycle into the chosen mode, grounding DIAG for CXRF
+	powerCycleCmd := func() {
+		if busy {
 			return
 		}
+		target := "CXRF"
+		if scTypeSelect.Selected == "CXRF" {
+			target = "CXR"
+		}
+		targetSelect := widget.NewSelect([]string{"CXR", "CXRF", "SW"}, nil)
+		targetSelect.SetSelected(target)
+		content := container.NewVBox(
+			widget.NewLabel("Switch the console off, set DIAG for the mode and switch it on again."),
+			targetSelect,
+		)
+		dialog.ShowCustomConfirm("Power Cycle", "Power Cycle", "Cancel", content, func(ok bool) {
+			if !ok || targetSelect.Selected == "" {
+				return
+			}
+			scType := targetSelect.Selected
+			runInBackground(func(ctx context.Context) error {
+				return deps.SwitchMode(ctx, scType, GetSerialSpeed(scType))
+			}, func(err error) {
+				timestamp := time.Now().Format("15:04:05")
+				if errors.Is(err, context.Canceled) {
+					outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > POWER CYCLE\nCancelled\n", timestamp))
+					return
+				}
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("power cycle failed: %w", err), myWindow)
+					return
+				}
+				outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > POWER CYCLE %s\nConsole restarted in %s mode\n", timestamp, scType, scType))
+				scTypeSelect.SetSelected(scType)
+			})
+		}, myWindow)
+	}
 
-		outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > AUTH\nAuth successful\n", timestamp))
+	// Internal mode wizard, one step per tap, each reported in the output
+	internalModeCmd := func() {
+		if busy {
+			return
+		}
+		chooseInternalModeWizard(myWindow, scTypeSelect.Selected, func(title string, steps []syscon.WizardStep) {
+			w := newInternalModeWizard(steps, syscon.WizardEnv{
+				Send:         deps.SendCommand,
+				Authenticate: deps.Authenticate,
+				SwitchMode: func(ctx context.Context, scType string) error {
+					if deps.SwitchMode == nil {
+						return syscon.ErrLineNotMapped
+					}
+					return deps.SwitchMode(ctx, scType, GetSerialSpeed(scType))
+				},
+			})
+			w.run = runInBackground
+			w.mode = func() string { return scTypeSelect.Selected }
+			w.confirm = func(scType, cmdText string, send func()) {
+				confirmDestructive(myWindow, scType, cmdText, send)
+			}
+			w.logStep = func(step syscon.WizardStep, text string, err error) {
+				timestamp := time.Now().Format("15:04:05")
+				switch {
+				case errors.Is(err, context.Canceled):
+					text = "Cancelled"
+				case err != nil:
+					text = strings.TrimSpace(fmt.Sprintf("%s\nFailed: %v", text, err))
+				case step.SwitchesTo != "":
+					scTypeSelect.SetSelected(step.SwitchesTo)
+				}
+				outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > WIZARD %s\n%s\n", timestamp, step.Title, text))
+			}
+			w.reconnect = func(scType string) error {
+				if err := deps.Disconnect(); err != nil {
+					return err
+				}
+				scTypeSelect.SetSelected(scType)
+				return connect()
+			}
+			d := dialog.NewCustom(title, "Close", w.content, myWindow)
+			d.Resize(fyne.NewSize(520, 0))
+			d.Show()
+		})
 	}
 
-	// Action buttons
-	sendBtn := widget.NewButton("Send Command", sendCmd)
-	sendBtn.Importance = widget.HighImportance
+	// EEPROM dump, resumable from the chunk that failed
+	dumpCmd := func() {
+		if busy {
+			return
+		}
+		scType := scTypeSelect.Selected
+		dumper, err := syscon.NewEEPROMDumper(scType)
+		if err != nil {
+			dialog.ShowError(err, myWindow)
+			return
+		}
+		v := newEEPROMDump(dumper)
+		v.run = runInBackground
+		v.send = deps.SendCommand
+		v.authenticate = func(ctx context.Context) error {
+			if deps.AuthState != nil && deps.AuthState() == syscon.AuthAuthenticated {
+				return nil
+			}
+			return deps.Authenticate(ctx)
+		}
+		v.newPath = func() (string, error) { return deps.NewDumpPath(scType) }
+		v.logResult = func(text string) {
+			timestamp := time.Now().Format("15:04:05")
+			outputText.SetText(outputText.Text + fmt.Sprintf("[%s] > DUMP EEPROM %s\n%s\n", timestamp, scType, text))
+		}
+		d := dialog.NewCustom("Dump EEPROM", "Close", v.content, myWindow)
+		d.Resize(fyne.NewSize(520, 0))
+		d.Show()
+	}
 
-	authBtn := widget.NewButton("Authenticate", authCmd)
+	sendBtn.OnTapped = sendCmd
+	authBtn.OnTapped = authCmd
+	detectBtn.OnTapped = detectCmd
+	powerCycleBtn.OnTapped = powerCycleCmd
+	internalModeBtn.OnTapped = internalModeCmd
+	dumpBtn.OnTapped = dumpCmd
 
 	helpBtn := widget.NewButton("Help", func() {
 		ShowHelpDialog(myApp, myWindow, func() {
@@ -348,22 +686,20 @@ func CreateMainWindow(myApp fyne.App, myWindow fyne.Window, deps WindowDeps) fyn
 	scTypeSelect.OnChanged = func(scType string) {
 		updateModeDesc(scType)
 		commandSection.RemoveAll()
-		switch scType {
-		case "CXR":
-			commandSection.Add(cxrSection)
-		case "CXRF":
-			commandSection.Add(cxrfSection)
-		case "SW":
-			commandSection.Add(legacySection)
+		if p, ok := pickers[scType]; ok {
+			commandSection.Add(p.content)
 		}
 		commandSection.Refresh()
 	}
 
+	refreshStatus()
+
 	// Main layout
 	leftColumn := container.NewVBox(
 		connectionCard,
 		commandCard,
 		actionButtons,
+		busyRow,
 	)
 
 	// Use border layout for main content
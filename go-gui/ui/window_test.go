@@ -1,11 +1,23 @@
 package ui
 
 import (
+	"context"
 	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"ps3syscon-gui/syscon"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
 )
 
 func testWindowDeps() WindowDeps {
@@ -20,26 +32,96 @@ func testWindowDeps() WindowDeps {
 		GetCXRFCommandNames: func() []string {
 			return []string{"version", "eepcsum", "errlog"}
 		},
-		GetCommand: func(name string) *Command {
+		GetSWCommandNames: func() []string {
+			return []string{"auth", "SETCMDLONG", "errlog"}
+		},
+		GetCommand: func(name string) *syscon.Command {
 			if name == "EEP" {
-				return &Command{Name: "EEP", Subcommands: []string{"GET", "SET"}}
+				return &syscon.Command{Name: "EEP", Subcommands: []string{"GET", "SET"}}
 			}
-			return &Command{Name: name}
+			return &syscon.Command{Name: name}
+		},
+		GetCXRFCommand: func(name string) *syscon.Command {
+			return &syscon.Command{Name: name, Description: "Test description"}
+		},
+		GetSWCommand: func(name string) *syscon.Command {
+			return &syscon.Command{Name: name, Mode: "SW"}
+		},
+		Connect: func(port, scType string, speed int) error {
+			return nil
 		},
-		GetCXRFCommand: func(name string) *Command {
-			return &Command{Name: name, Description: "Test description"}
+		Disconnect: func() error {
+			return nil
+		},
+		ConnectionState: func() (syscon.ConnectionState, error) {
+			return syscon.StateDisconnected, nil
+		},
+		AuthState: func() syscon.AuthState {
+			return syscon.AuthNone
 		},
-		SendCommand: func(port, scType, cmd string, speed int) (CommandResult, error) {
-			return CommandResult{Code: 0, Data: []string{"OK"}}, nil
+		SendCommand: func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+			return syscon.CommandResult{Code: 0, Data: []string{"OK"}}, nil
 		},
-		Authenticate: func(port, scType string, speed int) error {
+		Authenticate: func(ctx context.Context) error {
 			return nil
 		},
+		DetectDevice: func(ctx context.Context, port string) (syscon.Detection, error) {
+			return syscon.Detection{}, nil
+		},
+		LoadProfile: func(port string) syscon.ConnectionProfile {
+			return syscon.DefaultProfile(0)
+		},
+		SaveProfile: func(port string, p syscon.ConnectionProfile) error {
+			return nil
+		},
+		SwitchMode: func(ctx context.Context, scType string, speed int) error {
+			return nil
+		},
+		NewRecorder: func(kind string) (*syscon.Recorder, error) {
+			return syscon.NewRecorder(io.Discard)
+		},
+		SetRecorder:       func(rec *syscon.Recorder) {},
 		OpenSerialMonitor: func(myApp fyne.App, port, scType string) {},
 		ShowGuideWindow:   func(myApp fyne.App) {},
 	}
 }
 
+// parkRuns wraps deps so that the status refresh ending a background
+// command or authentication never returns. The run's goroutine stops
+// there instead of drawing into the next test's window. The returned wait
+// blocks until the next run has reached that point.
+func parkRuns(t *testing.T, deps *WindowDeps) (wait func()) {
+	t.Helper()
+	var ran atomic.Bool
+	parked := make(chan struct{}, 8)
+
+	send, auth, state := deps.SendCommand, deps.Authenticate, deps.ConnectionState
+	deps.SendCommand = func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+		ran.Store(true)
+		return send(ctx, cmd)
+	}
+	deps.Authenticate = func(ctx context.Context) error {
+		ran.Store(true)
+		return auth(ctx)
+	}
+	deps.ConnectionState = func() (syscon.ConnectionState, error) {
+		if ran.Load() {
+			parked <- struct{}{}
+			select {}
+		}
+		return state()
+	}
+
+	return func() {
+		t.Helper()
+		select {
+		case <-parked:
+		case <-time.After(time.Second):
+			t.Fatal("the background run did not finish")
+		}
+	}
+}
+
 func TestCreateMainWindow(t *testing.T) {
 	app := test.NewApp()
 	defer app.Quit()
@@ -97,6 +179,7 @@ func TestCreateMainWindowWithEmptyCommands(t *testing.T) {
 	deps := testWindowDeps()
 	deps.GetCommandNames = func() []string { return []string{} }
 	deps.GetCXRFCommandNames = func() []string { return []string{} }
+	deps.GetSWCommandNames = func() []string { return []string{} }
 
 	window := app.NewWindow("Test")
 	content := CreateMainWindow(app, window, deps)
@@ -113,6 +196,7 @@ func TestWindowDepsCallbacks(t *testing.T) {
 	getSerialPortsCalled := false
 	getCommandNamesCalled := false
 	getCXRFCommandNamesCalled := false
+	getSWCommandNamesCalled := false
 
 	deps := WindowDeps{
 		LogoResource: fyne.NewStaticResource("test_logo", []byte{}),
@@ -128,10 +212,20 @@ func TestWindowDepsCallbacks(t *testing.T) {
 			getCXRFCommandNamesCalled = true
 			return []string{"version"}
 		},
-		GetCommand:        func(name string) *Command { return nil },
-		GetCXRFCommand:    func(name string) *Command { return nil },
-		SendCommand:       func(port, scType, cmd string, speed int) (CommandResult, error) { return CommandResult{}, nil },
-		Authenticate:      func(port, scType string, speed int) error { return nil },
+		GetSWCommandNames: func() []string {
+			getSWCommandNamesCalled = true
+			return []string{"auth"}
+		},
+		GetCommand:      func(name string) *syscon.Command { return nil },
+		GetCXRFCommand:  func(name string) *syscon.Command { return nil },
+		GetSWCommand:    func(name string) *syscon.Command { return nil },
+		Connect:         func(port, scType string, speed int) error { return nil },
+		Disconnect:      func() error { return nil },
+		ConnectionState: func() (syscon.ConnectionState, error) { return syscon.StateDisconnected, nil },
+		SendCommand: func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+			return syscon.CommandResult{}, nil
+		},
+		Authenticate:      func(ctx context.Context) error { return nil },
 		OpenSerialMonitor: func(myApp fyne.App, port, scType string) {},
 		ShowGuideWindow:   func(myApp fyne.App) {},
 	}
@@ -148,27 +242,30 @@ func TestWindowDepsCallbacks(t *testing.T) {
 	if !getCXRFCommandNamesCalled {
 		t.Error("GetCXRFCommandNames was not called")
 	}
+	if !getSWCommandNamesCalled {
+		t.Error("GetSWCommandNames was not called")
+	}
 }
 
 func TestCommandHasSubcommands(t *testing.T) {
 	tests := []struct {
 		name     string
-		cmd      Command
+		cmd      syscon.Command
 		expected bool
 	}{
 		{
 			name:     "with subcommands",
-			cmd:      Command{Name: "EEP", Subcommands: []string{"GET", "SET"}},
+			cmd:      syscon.Command{Name: "EEP", Subcommands: []string{"GET", "SET"}},
 			expected: true,
 		},
 		{
 			name:     "empty subcommands",
-			cmd:      Command{Name: "VER", Subcommands: []string{}},
+			cmd:      syscon.Command{Name: "VER", Subcommands: []string{}},
 			expected: false,
 		},
 		{
 			name:     "nil subcommands",
-			cmd:      Command{Name: "AUTH"},
+			cmd:      syscon.Command{Name: "AUTH"},
 			expected: false,
 		},
 	}
@@ -184,7 +281,7 @@ func TestCommandHasSubcommands(t *testing.T) {
 }
 
 func TestCommandStruct(t *testing.T) {
-	cmd := Command{
+	cmd := syscon.Command{
 		Name:        "TEST",
 		Subcommands: []string{"SUB1", "SUB2"},
 		Description: "Test command",
@@ -208,8 +305,8 @@ func TestCreateMainWindowSendCommandError(t *testing.T) {
 	defer app.Quit()
 
 	deps := testWindowDeps()
-	deps.SendCommand = func(port, scType, cmd string, speed int) (CommandResult, error) {
-		return CommandResult{}, errors.New("send error")
+	deps.SendCommand = func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+		return syscon.CommandResult{}, errors.New("send error")
 	}
 
 	window := app.NewWindow("Test")
@@ -225,7 +322,7 @@ func TestCreateMainWindowAuthError(t *testing.T) {
 	defer app.Quit()
 
 	deps := testWindowDeps()
-	deps.Authenticate = func(port, scType string, speed int) error {
+	deps.Authenticate = func(ctx context.Context) error {
 		return errors.New("auth error")
 	}
 
@@ -236,3 +333,542 @@ func TestCreateMainWindowAuthError(t *testing.T) {
 		t.Fatal("CreateMainWindow returned nil")
 	}
 }
+
+func TestCreateMainWindowConnectionStates(t *testing.T) {
+	states := []struct {
+		state syscon.ConnectionState
+		err   error
+	}{
+		{syscon.StateDisconnected, nil},
+		{syscon.StateConnected, nil},
+		{syscon.StateFailed, errors.New("device unplugged")},
+	}
+
+	for _, tt := range states {
+		t.Run(tt.state.String(), func(t *testing.T) {
+			app := test.NewApp()
+			defer app.Quit()
+
+			deps := testWindowDeps()
+			deps.ConnectionState = func() (syscon.ConnectionState, error) { return tt.state, tt.err }
+
+			window := app.NewWindow("Test")
+			content := CreateMainWindow(app, window, deps)
+			if content == nil {
+				t.Fatal("CreateMainWindow returned nil")
+			}
+		})
+	}
+}
+
+// findObject walks the container tree and returns the first object match accepts.
+func findObject(obj fyne.CanvasObject, match func(fyne.CanvasObject) bool) fyne.CanvasObject {
+	if match(obj) {
+		return obj
+	}
+	if c, ok := obj.(*fyne.Container); ok {
+		for _, child := range c.Objects {
+			if found := findObject(child, match); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// findRendered is findObject that also looks inside widgets, for dialogs
+// whose content sits in the dialog's renderer.
+func findRendered(obj fyne.CanvasObject, match func(fyne.CanvasObject) bool) fyne.CanvasObject {
+	if match(obj) {
+		return obj
+	}
+	var children []fyne.CanvasObject
+	switch o := obj.(type) {
+	case *fyne.Container:
+		children = o.Objects
+	case fyne.Widget:
+		children = test.WidgetRenderer(o).Objects()
+	}
+	for _, child := range children {
+		if found := findRendered(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findButton returns the button labelled text.
+func findButton(obj fyne.CanvasObject, text string) *widget.Button {
+	found := findObject(obj, func(o fyne.CanvasObject) bool {
+		b, ok := o.(*widget.Button)
+		return ok && b.Text == text
+	})
+	if found == nil {
+		return nil
+	}
+	return found.(*widget.Button)
+}
+
+func TestCreateMainWindowSendRunsInBackground(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	deps := testWindowDeps()
+	deps.ConnectionState = func() (syscon.ConnectionState, error) { return syscon.StateConnected, nil }
+	deps.SendCommand = func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return syscon.CommandResult{}, ctx.Err()
+	}
+	wait := parkRuns(t, &deps)
+
+	window := app.NewWindow("Test")
+	content := CreateMainWindow(app, window, deps)
+	window.SetContent(content)
+
+	entry := findObject(content, func(o fyne.CanvasObject) bool {
+		_, ok := o.(*widget.SelectEntry)
+		return ok
+	}).(*widget.SelectEntry)
+	entry.SetText("VER")
+
+	sendBtn := findButton(content, "Send Command")
+	test.Tap(sendBtn)
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("SendCommand was not called")
+	}
+
+	// Tapping Send returned while the command is still running
+	if !sendBtn.Disabled() {
+		t.Error("Send button should be disabled while a command runs")
+	}
+
+	test.Tap(findButton(content, "Cancel"))
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Cancel did not cancel the command context")
+	}
+	wait()
+}
+
+func TestCreateMainWindowDetectSelectsMode(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	detected := make(chan struct{})
+	finished := make(chan struct{})
+	var once sync.Once
+
+	deps := testWindowDeps()
+	deps.DetectDevice = func(ctx context.Context, port string) (syscon.Detection, error) {
+		close(detected)
+		return syscon.Detection{SCType: "CXRF", BaudRate: 57600, Confidence: syscon.ConfidenceMedium}, nil
+	}
+	deps.ConnectionState = func() (syscon.ConnectionState, error) {
+		// The status refresh after Detect is the last step of the background
+		// run; park it there, as parkRuns does
+		select {
+		case <-detected:
+			once.Do(func() {
+				close(finished)
+				select {}
+			})
+		default:
+		}
+		return syscon.StateDisconnected, nil
+	}
+
+	window := app.NewWindow("Test")
+	content := CreateMainWindow(app, window, deps)
+	window.SetContent(content)
+
+	var selects []*widget.Select
+	findObject(content, func(o fyne.CanvasObject) bool {
+		if s, ok := o.(*widget.Select); ok {
+			selects = append(selects, s)
+		}
+		return false
+	})
+	portSelect, modeSelect := selects[0], selects[1]
+	portSelect.SetSelected("/dev/ttyUSB0")
+
+	test.Tap(findButton(content, "Detect"))
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("Detect did not finish")
+	}
+	if mode := modeSelect.Selected; mode != "CXRF" {
+		t.Errorf("mode = %q after Detect, want CXRF", mode)
+	}
+}
+
+func TestCreateMainWindowValidatesArguments(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	sent := make(chan string, 1)
+	deps := testWindowDeps()
+	deps.ConnectionState = func() (syscon.ConnectionState, error) { return syscon.StateConnected, nil }
+	deps.AuthState = func() syscon.AuthState { return syscon.AuthAuthenticated }
+	deps.GetCommand = func(name string) *syscon.Command {
+		return &syscon.Command{Name: name, Args: []syscon.Arg{{Name: "offset", Type: syscon.ArgHex, Width: 4}}}
+	}
+	deps.SendCommand = func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+		sent <- cmd
+		return syscon.CommandResult{}, nil
+	}
+	wait := parkRuns(t, &deps)
+
+	window := app.NewWindow("Test")
+	content := CreateMainWindow(app, window, deps)
+	window.SetContent(content)
+
+	findObject(content, func(o fyne.CanvasObject) bool {
+		_, ok := o.(*widget.SelectEntry)
+		return ok
+	}).(*widget.SelectEntry).SetText("R8")
+	offset := findObject(content, func(o fyne.CanvasObject) bool {
+		e, ok := o.(*widget.Entry)
+		return ok && e.PlaceHolder == "4 hex digits"
+	})
+	if offset == nil {
+		t.Fatal("no offset input for a command with an argument definition")
+	}
+
+	offset.(*widget.Entry).SetText("396")
+	test.Tap(findButton(content, "Send Command"))
+	select {
+	case cmd := <-sent:
+		t.Fatalf("sent %q with an invalid offset", cmd)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	offset.(*widget.Entry).SetText("3961")
+	test.Tap(findButton(content, "Send Command"))
+	select {
+	case cmd := <-sent:
+		if cmd != "R8 3961" {
+			t.Errorf("sent %q, want %q", cmd, "R8 3961")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SendCommand was not called")
+	}
+	wait()
+}
+
+func TestCreateMainWindowBlocksCommandsNotPermitted(t *testing.T) {
+	for _, state := range []syscon.AuthState{syscon.AuthNone, syscon.AuthAuthenticated} {
+		t.Run(state.String(), func(t *testing.T) {
+			app := test.NewApp()
+			defer app.Quit()
+
+			sent := make(chan string, 1)
+			deps := testWindowDeps()
+			deps.ConnectionState = func() (syscon.ConnectionState, error) { return syscon.StateConnected, nil }
+			deps.AuthState = func() syscon.AuthState { return state }
+			deps.SendCommand = func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+				sent <- cmd
+				return syscon.CommandResult{}, nil
+			}
+			wait := parkRuns(t, &deps)
+
+			window := app.NewWindow("Test")
+			content := CreateMainWindow(app, window, deps)
+			window.SetContent(content)
+
+			findObject(content, func(o fyne.CanvasObject) bool {
+				_, ok := o.(*widget.SelectEntry)
+				return ok
+			}).(*widget.SelectEntry).SetText("EEP GET 3961 01")
+			test.Tap(findButton(content, "Send Command"))
+
+			select {
+			case cmd := <-sent:
+				if state == syscon.AuthNone {
+					t.Errorf("sent %q before authentication", cmd)
+				}
+				wait()
+			case <-time.After(100 * time.Millisecond):
+				if state == syscon.AuthAuthenticated {
+					t.Error("EEP GET was not sent after authentication")
+				}
+			}
+		})
+	}
+}
+
+func TestCreateMainWindowSWCommands(t *testing.T) {
+	// auth runs the AUTH1/AUTH2 handshake instead of being sent as a line
+	for _, line := range []string{"errlog", "auth"} {
+		t.Run(line, func(t *testing.T) {
+			app := test.NewApp()
+			defer app.Quit()
+
+			sent := make(chan string, 1)
+			authed := make(chan struct{}, 1)
+			deps := testWindowDeps()
+			deps.ConnectionState = func() (syscon.ConnectionState, error) { return syscon.StateConnected, nil }
+			deps.SendCommand = func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+				sent <- cmd
+				return syscon.CommandResult{}, nil
+			}
+			deps.Authenticate = func(ctx context.Context) error {
+				authed <- struct{}{}
+				return nil
+			}
+			wait := parkRuns(t, &deps)
+
+			window := app.NewWindow("Test")
+			content := CreateMainWindow(app, window, deps)
+			window.SetContent(content)
+
+			var selects []*widget.Select
+			findObject(content, func(o fyne.CanvasObject) bool {
+				if s, ok := o.(*widget.Select); ok {
+					selects = append(selects, s)
+				}
+				return false
+			})
+			selects[1].SetSelected("SW")
+
+			cmdEntry := findObject(content, func(o fyne.CanvasObject) bool {
+				_, ok := o.(*widget.SelectEntry)
+				return ok
+			}).(*widget.SelectEntry)
+			if cmdEntry.PlaceHolder != "Select command..." {
+				t.Fatalf("SW mode shows %q, want the command picker", cmdEntry.PlaceHolder)
+			}
+
+			cmdEntry.SetText(line)
+			test.Tap(findButton(content, "Send Command"))
+			select {
+			case cmd := <-sent:
+				if cmd != line || line == "auth" {
+					t.Errorf("sent %q for %q", cmd, line)
+				}
+			case <-authed:
+				if line != "auth" {
+					t.Errorf("%q ran the handshake", line)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("nothing was sent")
+			}
+			wait()
+		})
+	}
+}
+
+func TestCreateMainWindowReadOnlyMode(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	sent := make(chan string, 1)
+	var readOnly bool
+	deps := testWindowDeps()
+	deps.ConnectionState = func() (syscon.ConnectionState, error) { return syscon.StateConnected, nil }
+	deps.SetReadOnly = func(on bool) { readOnly = on }
+	deps.SendCommand = func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+		sent <- cmd
+		return syscon.CommandResult{}, nil
+	}
+	wait := parkRuns(t, &deps)
+
+	window := app.NewWindow("Test")
+	content := CreateMainWindow(app, window, deps)
+	window.SetContent(content)
+
+	findObject(content, func(o fyne.CanvasObject) bool {
+		c, ok := o.(*widget.Check)
+		return ok && c.Text == "Read-only mode"
+	}).(*widget.Check).SetChecked(true)
+	if !readOnly {
+		t.Fatal("SetReadOnly(true) was not called")
+	}
+
+	cmdEntry := findObject(content, func(o fyne.CanvasObject) bool {
+		_, ok := o.(*widget.SelectEntry)
+		return ok
+	}).(*widget.SelectEntry)
+	cmdEntry.SetText("W8 3961 FF")
+	test.Tap(findButton(content, "Send Command"))
+	select {
+	case cmd := <-sent:
+		t.Fatalf("sent %q in read-only mode", cmd)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cmdEntry.SetText("VER")
+	test.Tap(findButton(content, "Send Command"))
+	select {
+	case cmd := <-sent:
+		if cmd != "VER" {
+			t.Errorf("sent %q, want VER", cmd)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("VER was not sent in read-only mode")
+	}
+	wait()
+}
+
+func TestCreateMainWindowRepairsChecksums(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	bad := "sum:0x0100\nAddr:0x000039fe should be 0x0038\n"
+	good := "Addr:0x000039fe should be 0x0038\n"
+	var mu sync.Mutex
+	var sent []string
+	deps := testWindowDeps()
+	deps.ConnectionState = func() (syscon.ConnectionState, error) { return syscon.StateConnected, nil }
+	deps.SendCommand = func(ctx context.Context, cmd string) (syscon.CommandResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, cmd)
+		if cmd != "eepcsum" {
+			return syscon.CommandResult{}, nil
+		}
+		if len(sent) == 1 {
+			return syscon.CommandResult{Data: []string{bad}}, nil
+		}
+		return syscon.CommandResult{Data: []string{good}}, nil
+	}
+	wait := parkRuns(t, &deps)
+
+	window := app.NewWindow("Test")
+	content := CreateMainWindow(app, window, deps)
+	window.SetContent(content)
+
+	var selects []*widget.Select
+	findObject(content, func(o fyne.CanvasObject) bool {
+		if s, ok := o.(*widget.Select); ok {
+			selects = append(selects, s)
+		}
+		return false
+	})
+	selects[1].SetSelected("CXRF")
+	findObject(content, func(o fyne.CanvasObject) bool {
+		_, ok := o.(*widget.SelectEntry)
+		return ok
+	}).(*widget.SelectEntry).SetText("eepcsum")
+	test.Tap(findButton(content, "Send Command"))
+	wait()
+
+	preview := window.Canvas().Overlays().Top()
+	if preview == nil {
+		t.Fatal("no repair preview after a bad eepcsum")
+	}
+	if findRendered(preview, func(o fyne.CanvasObject) bool {
+		l, ok := o.(*widget.Label)
+		return ok && strings.Contains(l.Text, "w 39FE 38 00")
+	}) == nil {
+		t.Error("the preview does not show w 39FE 38 00")
+	}
+	write := findRendered(preview, func(o fyne.CanvasObject) bool {
+		b, ok := o.(*widget.Button)
+		return ok && b.Text == "Write"
+	})
+	if write == nil {
+		t.Fatal("the preview has no Write button")
+	}
+	test.Tap(write.(*widget.Button))
+	wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"eepcsum", "w 39FE 38 00", "eepcsum"}
+	if !slices.Equal(sent, want) {
+		t.Errorf("sent %q, want %q", sent, want)
+	}
+}
+
+func TestCreateMainWindowInternalModeButton(t *testing.T) {
+	for _, state := range []syscon.ConnectionState{syscon.StateDisconnected, syscon.StateConnected} {
+		t.Run(state.String(), func(t *testing.T) {
+			app := test.NewApp()
+			defer app.Quit()
+
+			deps := testWindowDeps()
+			deps.ConnectionState = func() (syscon.ConnectionState, error) { return state, nil }
+
+			window := app.NewWindow("Test")
+			content := CreateMainWindow(app, window, deps)
+			window.SetContent(content)
+
+			btn := findButton(content, "Internal Mode")
+			if btn == nil {
+				t.Fatal("no Internal Mode button")
+			}
+			if btn.Disabled() != (state != syscon.StateConnected) {
+				t.Errorf("Internal Mode disabled = %v while %s", btn.Disabled(), state)
+			}
+			if state == syscon.StateConnected {
+				test.Tap(btn)
+				if window.Canvas().Overlays().Top() == nil {
+					t.Error("Internal Mode did not open the wizard chooser")
+				}
+			}
+		})
+	}
+}
+
+func TestCreateMainWindowDumpsEEPROM(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	binPath := filepath.Join(t.TempDir(), "eeprom.bin")
+	var authenticated atomic.Bool
+	deps := testWindowDeps()
+	deps.ConnectionState = func() (syscon.ConnectionState, error) { return syscon.StateConnected, nil }
+	deps.SendCommand = emulatorSend(t, "CXR")
+	deps.Authenticate = func(ctx context.Context) error {
+		authenticated.Store(true)
+		return nil
+	}
+	deps.NewDumpPath = func(scType string) (string, error) { return binPath, nil }
+	wait := parkRuns(t, &deps)
+
+	window := app.NewWindow("Test")
+	content := CreateMainWindow(app, window, deps)
+	window.SetContent(content)
+
+	btn := findButton(content, "Dump EEPROM")
+	if btn == nil || btn.Disabled() {
+		t.Fatal("no enabled Dump EEPROM button while connected")
+	}
+	test.Tap(btn)
+	overlay := window.Canvas().Overlays().Top()
+	if overlay == nil {
+		t.Fatal("Dump EEPROM did not open the dump dialog")
+	}
+	start := findRendered(overlay, func(o fyne.CanvasObject) bool {
+		b, ok := o.(*widget.Button)
+		return ok && b.Text == "Start"
+	})
+	if start == nil {
+		t.Fatal("the dump dialog has no Start button")
+	}
+	test.Tap(start.(*widget.Button))
+	wait()
+
+	if !authenticated.Load() {
+		t.Error("the dump did not authenticate first")
+	}
+	data, err := os.ReadFile(binPath)
+	if err != nil || len(data) != syscon.EEPROMSize {
+		t.Errorf("dump file: %d bytes, %v", len(data), err)
+	}
+	if _, err := os.Stat(strings.TrimSuffix(binPath, ".bin") + ".json"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,189 @@
+// Package ui provides the internal mode wizard.
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Wizard choices offered by chooseInternalModeWizard.
+const (
+	wizardEnable = "Enable internal mode (CXR to CXRF)"
+	wizardRevert = "Revert to normal boot (CXRF to CXR)"
+)
+
+// internalModeWizard runs the steps of enabling or reverting internal
+// mode one at a time, checking each answer before offering the next.
+type internalModeWizard struct {
+	steps   []syscon.WizardStep
+	current int
+	rec     syscon.WizardRecord
+	env     syscon.WizardEnv
+
+	run       func(work func(ctx context.Context) error, done func(err error)) // runs work in the background
+	mode      func() string                                                    // mode the window is in
+	logStep   func(step syscon.WizardStep, text string, err error)             // reports a finished step
+	reconnect func(scType string) error                                        // reopens the port after a manual power cycle
+	confirm   func(scType, cmdText string, send func())                        // confirms a destructive step command
+
+	content    fyne.CanvasObject
+	stepLabels []*widget.Label
+	result     *widget.Label
+	runBtn     *widget.Button
+	manualBtn  *widget.Button
+}
+
+// newInternalModeWizard builds the wizard for steps.
+func newInternalModeWizard(steps []syscon.WizardStep, env syscon.WizardEnv) *internalModeWizard {
+	w := &internalModeWizard{steps: steps, env: env}
+
+	list := container.NewVBox()
+	for i, step := range steps {
+		label := widget.NewLabel(fmt.Sprintf("%d. %s", i+1, step.Title))
+		w.stepLabels = append(w.stepLabels, label)
+		list.Add(label)
+	}
+
+	w.result = widget.NewLabel("")
+	w.result.Wrapping = fyne.TextWrapWord
+	w.runBtn = widget.NewButton("Run Step", w.runStep)
+	w.runBtn.Importance = widget.HighImportance
+	w.manualBtn = widget.NewButton("Done by Hand", w.manualDone)
+	w.manualBtn.Hide()
+
+	w.content = container.NewVBox(list, widget.NewSeparator(), w.result, container.NewHBox(w.runBtn, w.manualBtn))
+	w.update()
+	return w
+}
+
+// update marks the current step and describes what it will do.
+func (w *internalModeWizard) update() {
+	for i, label := range w.stepLabels {
+		label.TextStyle = fyne.TextStyle{Bold: i == w.current}
+		label.Refresh()
+	}
+	if w.current >= len(w.steps) {
+		w.runBtn.Disable()
+		return
+	}
+	step := w.steps[w.current]
+	w.runBtn.Enable()
+	w.result.SetText(fmt.Sprintf("Next, in %s mode: %s", step.Mode, step.Title))
+}
+
+// runStep runs the current step in the background, once its command has
+// been confirmed like any other destructive command.
+func (w *internalModeWizard) runStep() {
+	if w.current >= len(w.steps) {
+		return
+	}
+	step := w.steps[w.current]
+	if mode := w.mode(); mode != step.Mode {
+		w.result.SetText(fmt.Sprintf("This step runs in %s mode, but the window is in %s mode.", step.Mode, mode))
+		return
+	}
+	if step.Command == "" {
+		w.start(step)
+		return
+	}
+	w.confirm(step.Mode, step.Command, func() { w.start(step) })
+}
+
+// start runs step in the background and reports its answer.
+func (w *internalModeWizard) start(step syscon.WizardStep) {
+	w.runBtn.Disable()
+	w.manualBtn.Hide()
+	var text string
+	w.run(func(ctx context.Context) error {
+		var err error
+		text, err = step.Run(ctx, w.env, &w.rec)
+		return err
+	}, func(err error) {
+		w.logStep(step, text, err)
+		switch {
+		case err == nil:
+			w.finishStep(text)
+		case errors.Is(err, syscon.ErrLineNotMapped) && step.Manual != "":
+			w.result.SetText(fmt.Sprintf("DIAG and power are not mapped in Advanced. %s Then press Done by Hand.", step.Manual))
+			w.manualBtn.Show()
+			w.runBtn.SetText("Retry")
+			w.runBtn.Enable()
+		default:
+			msg := fmt.Sprintf("Step %d failed: %v", w.current+1, err)
+			if text != "" {
+				msg += "\nAnswer: " + text
+			}
+			w.result.SetText(msg)
+			w.runBtn.SetText("Retry")
+			w.runBtn.Enable()
+		}
+	})
+}
+
+// manualDone reopens the port in the new mode after the user has power
+// cycled the console by hand.
+func (w *internalModeWizard) manualDone() {
+	step := w.steps[w.current]
+	if err := w.reconnect(step.SwitchesTo); err != nil {
+		w.result.SetText(fmt.Sprintf("Reconnecting in %s mode failed: %v", step.SwitchesTo, err))
+		return
+	}
+	text := "Console restarted by hand in " + step.SwitchesTo + " mode"
+	w.logStep(step, text, nil)
+	w.finishStep(text)
+}
+
+// finishStep moves on after a step succeeded.
+func (w *internalModeWizard) finishStep(text string) {
+	w.stepLabels[w.current].SetText(w.stepLabels[w.current].Text + " - done")
+	w.current++
+	w.manualBtn.Hide()
+	w.runBtn.SetText("Run Step")
+	w.update()
+	if w.current < len(w.steps) {
+		w.result.SetText(text + "\n\n" + w.result.Text)
+		return
+	}
+	summary := text + "\n\nAll steps done."
+	if w.rec.HasOriginal {
+		summary += fmt.Sprintf(" The internal mode byte was %02X before the wizard changed it.", w.rec.Original)
+	}
+	w.result.SetText(summary)
+}
+
+// chooseInternalModeWizard asks whether to enable internal mode or revert
+// it, defaulting to revert when the window is already in CXRF mode, and
+// calls start with the chosen steps.
+func chooseInternalModeWizard(parent fyne.Window, scType string, start func(title string, steps []syscon.WizardStep)) {
+	choice := widget.NewRadioGroup([]string{wizardEnable, wizardRevert}, nil)
+	choice.Required = true
+	choice.SetSelected(wizardEnable)
+	if scType == "CXRF" {
+		choice.SetSelected(wizardRevert)
+	}
+	warning := widget.NewLabel(fmt.Sprintf(
+		"Both write EEPROM byte %04X. The console does not boot normally until the wizard has fixed the checksums; each answer is checked before the next step.",
+		syscon.InternalModeOffset))
+	warning.Wrapping = fyne.TextWrapWord
+
+	d := dialog.NewCustomConfirm("Internal Mode", "Start", "Cancel", container.NewVBox(choice, warning), func(ok bool) {
+		if !ok {
+			return
+		}
+		if choice.Selected == wizardRevert {
+			start("Revert Internal Mode", syscon.RevertInternalModeSteps())
+			return
+		}
+		start("Enable Internal Mode", syscon.EnableInternalModeSteps())
+	}, parent)
+	d.Resize(fyne.NewSize(480, 0))
+	d.Show()
+}
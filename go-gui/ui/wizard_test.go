@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ps3syscon-gui/syscon"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+// testWizard returns a wizard over steps that runs each step at once.
+func testWizard(steps []syscon.WizardStep, mode *string) (*internalModeWizard, *[]string) {
+	var log []string
+	w := newInternalModeWizard(steps, syscon.WizardEnv{})
+	w.run = func(work func(ctx context.Context) error, done func(err error)) {
+		done(work(context.Background()))
+	}
+	w.mode = func() string { return *mode }
+	w.logStep = func(step syscon.WizardStep, text string, err error) {
+		if err != nil {
+			text = "Failed: " + err.Error()
+		}
+		log = append(log, step.Title+": "+text)
+	}
+	w.reconnect = func(scType string) error {
+		*mode = scType
+		return nil
+	}
+	w.confirm = func(scType, cmdText string, send func()) { send() }
+	return w, &log
+}
+
+func TestInternalModeWizardSteps(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	answer := "00000000 00"
+	steps := []syscon.WizardStep{
+		{Title: "Read", Mode: "CXR", Run: func(ctx context.Context, env syscon.WizardEnv, rec *syscon.WizardRecord) (string, error) {
+			rec.Original, rec.HasOriginal = 0xFF, true
+			if answer != "00000000 FF" {
+				return answer, syscon.ErrUnexpectedAnswer
+			}
+			return answer, nil
+		}},
+		{Title: "Switch", Mode: "CXR", SwitchesTo: "CXRF", Manual: "Ground DIAG.", Run: func(ctx context.Context, env syscon.WizardEnv, rec *syscon.WizardRecord) (string, error) {
+			return "", syscon.ErrLineNotMapped
+		}},
+		{Title: "Fix", Mode: "CXRF", Run: func(ctx context.Context, env syscon.WizardEnv, rec *syscon.WizardRecord) (string, error) {
+			return "All checksums OK", nil
+		}},
+	}
+	mode := "CXRF"
+	w, log := testWizard(steps, &mode)
+
+	test.Tap(w.runBtn)
+	if len(*log) != 0 || !strings.Contains(w.result.Text, "runs in CXR mode") {
+		t.Fatalf("ran a CXR step in CXRF mode: %q", w.result.Text)
+	}
+
+	mode = "CXR"
+	test.Tap(w.runBtn)
+	if w.current != 0 || w.runBtn.Text != "Retry" || !strings.Contains(w.result.Text, "Answer: 00000000 00") {
+		t.Errorf("unexpected answer: step %d, %q, %q", w.current, w.runBtn.Text, w.result.Text)
+	}
+
+	answer = "00000000 FF"
+	test.Tap(w.runBtn)
+	if w.current != 1 || !strings.HasSuffix(w.stepLabels[0].Text, "- done") {
+		t.Fatalf("expected answer did not advance: step %d", w.current)
+	}
+
+	test.Tap(w.runBtn)
+	if w.manualBtn.Hidden || !strings.Contains(w.result.Text, "Ground DIAG.") {
+		t.Fatalf("unmapped lines offer no manual step: %q", w.result.Text)
+	}
+	test.Tap(w.manualBtn)
+	if mode != "CXRF" || w.current != 2 {
+		t.Fatalf("manual power cycle: mode %s, step %d", mode, w.current)
+	}
+
+	test.Tap(w.runBtn)
+	if !w.runBtn.Disabled() || !strings.Contains(w.result.Text, "byte was FF") {
+		t.Errorf("finished wizard: %q", w.result.Text)
+	}
+	if len(*log) != 5 {
+		t.Errorf("log = %q, want every attempt", *log)
+	}
+}
+
+func TestInternalModeWizardConfirmsWrite(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	window := app.NewWindow("Test")
+
+	ran := false
+	step := syscon.EnableInternalModeSteps()[2]
+	step.Run = func(ctx context.Context, env syscon.WizardEnv, rec *syscon.WizardRecord) (string, error) {
+		ran = true
+		return "00000000", nil
+	}
+	mode := "CXR"
+	w, _ := testWizard([]syscon.WizardStep{step}, &mode)
+	w.confirm = func(scType, cmdText string, send func()) {
+		confirmDestructive(window, scType, cmdText, send)
+	}
+
+	test.Tap(w.runBtn)
+	if ran {
+		t.Fatal("EEP SET ran without confirmation")
+	}
+	d := window.Canvas().Overlays().Top()
+	if d == nil {
+		t.Fatal("no confirmation shown")
+	}
+	bytes := findRendered(d, func(o fyne.CanvasObject) bool {
+		l, ok := o.(*widget.Label)
+		return ok && strings.Contains(l.Text, `"C:`)
+	}).(*widget.Label)
+	if !strings.Contains(bytes.Text, ":EEP SET 3961 01 00\\r\\n\"") {
+		t.Errorf("bytes = %q, want the framed EEP SET", bytes.Text)
+	}
+
+	entry := findRendered(d, func(o fyne.CanvasObject) bool {
+		_, ok := o.(*widget.Entry)
+		return ok
+	}).(*widget.Entry)
+	test.Type(entry, "EEP SET 3961 01 00")
+	send := findRendered(d, func(o fyne.CanvasObject) bool {
+		b, ok := o.(*widget.Button)
+		return ok && b.Text == "Send"
+	}).(*widget.Button)
+	test.Tap(send)
+	if !ran || w.current != 1 {
+		t.Errorf("confirmed step: ran %v, step %d", ran, w.current)
+	}
+}
+
+func TestChooseInternalModeWizard(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+
+	for scType, want := range map[string]string{"CXR": wizardEnable, "CXRF": wizardRevert} {
+		window := app.NewWindow("Test")
+		var title string
+		var steps []syscon.WizardStep
+		chooseInternalModeWizard(window, scType, func(t string, s []syscon.WizardStep) { title, steps = t, s })
+
+		d := window.Canvas().Overlays().Top()
+		radio := findRendered(d, func(o fyne.CanvasObject) bool {
+			_, ok := o.(*widget.RadioGroup)
+			return ok
+		}).(*widget.RadioGroup)
+		if radio.Selected != want {
+			t.Errorf("%s mode offers %q first, want %q", scType, radio.Selected, want)
+		}
+		start := findRendered(d, func(o fyne.CanvasObject) bool {
+			b, ok := o.(*widget.Button)
+			return ok && b.Text == "Start"
+		}).(*widget.Button)
+		test.Tap(start)
+		if len(steps) == 0 || steps[0].Mode != scType {
+			t.Errorf("%s mode started %q beginning in %+v", scType, title, steps)
+		}
+	}
+}